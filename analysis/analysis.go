@@ -0,0 +1,187 @@
+package analysis
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+type Module struct {
+	Path     string
+	Dir      string
+	Requires []string
+
+	packages []*Package
+}
+
+func (m *Module) Packages() []*Package {
+	return m.packages
+}
+
+func (m *Module) Package(path string) *Package {
+	for _, pkg := range m.packages {
+		if pkg.Path == path && !isExternalTest(pkg) {
+			return pkg
+		}
+	}
+	return nil
+}
+
+func isExternalTest(pkg *Package) bool {
+	return strings.HasSuffix(pkg.Name, "_test")
+}
+
+type Options struct {
+	Select      []string
+	SelectExact []string
+	Exclude     []string
+	Tests       bool
+	BuildTags   []string
+	GOOS        string
+	GOARCH      string
+	Concurrency int
+}
+
+type Option func(*Options)
+
+func WithSelect(paths ...string) Option {
+	return func(o *Options) {
+		o.Select = append(o.Select, paths...)
+	}
+}
+
+func WithSelectExact(paths ...string) Option {
+	return func(o *Options) {
+		o.SelectExact = append(o.SelectExact, paths...)
+	}
+}
+
+func WithExclude(paths ...string) Option {
+	return func(o *Options) {
+		o.Exclude = append(o.Exclude, paths...)
+	}
+}
+
+func WithTests(tests bool) Option {
+	return func(o *Options) {
+		o.Tests = tests
+	}
+}
+
+func WithBuildTags(tags ...string) Option {
+	return func(o *Options) {
+		o.BuildTags = append(o.BuildTags, tags...)
+	}
+}
+
+func WithPlatform(goos, goarch string) Option {
+	return func(o *Options) {
+		o.GOOS = goos
+		o.GOARCH = goarch
+	}
+}
+
+func WithConcurrency(n int) Option {
+	return func(o *Options) {
+		o.Concurrency = n
+	}
+}
+
+func (o *Options) config() *internal.Config {
+	return &internal.Config{
+		Select:       set(o.Select),
+		SelectExact:  set(o.SelectExact),
+		Exclude:      set(o.Exclude),
+		IncludeTests: o.Tests,
+		GOOS:         o.GOOS,
+		GOARCH:       o.GOARCH,
+		BuildTags:    o.BuildTags,
+	}
+}
+
+func set(items []string) map[string]struct{} {
+	res := make(map[string]struct{}, len(items))
+	for _, i := range items {
+		res[i] = struct{}{}
+	}
+	return res
+}
+
+func Load(ctx context.Context, dir string, opts ...Option) (*Module, error) {
+	options := &Options{Concurrency: runtime.NumCPU()}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.Concurrency < 1 {
+		options.Concurrency = 1
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	mod, err := internal.ReadGoMod(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	directories, err := internal.LoadPackages(dir, mod.Module, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(directories))
+	for p := range directories {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	config := options.config()
+	jobs := make(chan *internal.Directory)
+	errs := make(chan error, len(paths))
+
+	var wg sync.WaitGroup
+	for i := 0; i < options.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for directory := range jobs {
+				if err := internal.ParsePackage(directory, mod.Module, dir, config); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+
+	cancelled := false
+	for _, p := range paths {
+		if ctx.Err() != nil {
+			cancelled = true
+			break
+		}
+		jobs <- directories[p]
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+
+	if cancelled {
+		return nil, ctx.Err()
+	}
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+
+	return &Module{
+		Path:     mod.Module,
+		Dir:      dir,
+		Requires: mod.Requires,
+		packages: newPackages(directories),
+	}, nil
+}
@@ -0,0 +1,145 @@
+package analysis_test
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/analysis"
+)
+
+func assertEqual(t *testing.T, expected, actual any) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected:\n%#v\ngot:\n%#v", expected, actual)
+	}
+}
+
+func structNames(m *analysis.Module) []string {
+	var names []string
+	for _, pkg := range m.Packages() {
+		for _, f := range pkg.Files {
+			for _, s := range f.Structs {
+				names = append(names, pkg.Name+"."+s.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestLoad(t *testing.T) {
+	m, err := analysis.Load(context.Background(), "testdata/lib", analysis.WithConcurrency(2))
+	assertEqual(t, nil, err)
+
+	assertEqual(t, "example.com/lib", m.Path)
+	assertEqual(t, []string{
+		"lib.Client",
+		"lib.DebugClient",
+		"lib.LinuxClient",
+		"lib.ModernClient",
+		"lib.UnixClient",
+		"lib.WindowsClient",
+		"store.Store",
+	}, structNames(m))
+	assertEqual(t, "store", m.Package("example.com/lib/store").Name)
+	assertEqual(t, (*analysis.Package)(nil), m.Package("example.com/lib/missing"))
+}
+
+func TestLoadModel(t *testing.T) {
+	m, err := analysis.Load(context.Background(), "testdata/lib")
+	assertEqual(t, nil, err)
+
+	pkg := m.Package("example.com/lib")
+	assertEqual(t, "lib", pkg.Name)
+	assertEqual(t, m.Dir, pkg.Dir)
+
+	var client *analysis.Struct
+	var imports []string
+	for _, f := range pkg.Files {
+		for _, s := range f.Structs {
+			if s.Name == "Client" {
+				client = s
+			}
+		}
+		for _, i := range f.Imports {
+			imports = append(imports, i.Path)
+		}
+	}
+	assertEqual(t, []string{"example.com/lib/store"}, imports)
+	assertEqual(t, "Store *store.Store", client.Fields[0].Name+" "+client.Fields[0].Type)
+	assertEqual(t, "Get(string) string", client.Methods[0].Signature)
+	assertEqual(t, 1, client.Methods[0].Complexity.Cyclomatic)
+	assertEqual(t, 5, client.Position.Line)
+}
+
+func TestLoadWithTests(t *testing.T) {
+	m, err := analysis.Load(context.Background(), "testdata/lib", analysis.WithTests(true))
+	assertEqual(t, nil, err)
+
+	assertEqual(t, []string{
+		"lib.Client",
+		"lib.DebugClient",
+		"lib.LinuxClient",
+		"lib.ModernClient",
+		"lib.UnixClient",
+		"lib.WindowsClient",
+		"lib_test.fakeStore",
+		"store.Store",
+	}, structNames(m))
+	assertEqual(t, "lib", m.Package("example.com/lib").Name)
+}
+
+func TestLoadWithPlatform(t *testing.T) {
+	m, err := analysis.Load(context.Background(), "testdata/lib", analysis.WithPlatform("linux", "amd64"))
+	assertEqual(t, nil, err)
+
+	assertEqual(t, []string{
+		"lib.Client",
+		"lib.LinuxClient",
+		"lib.ModernClient",
+		"lib.UnixClient",
+		"store.Store",
+	}, structNames(m))
+
+	m, err = analysis.Load(
+		context.Background(), "testdata/lib",
+		analysis.WithPlatform("windows", "amd64"),
+		analysis.WithBuildTags("debug"),
+	)
+	assertEqual(t, nil, err)
+
+	assertEqual(t, []string{
+		"lib.Client",
+		"lib.DebugClient",
+		"lib.ModernClient",
+		"lib.WindowsClient",
+		"store.Store",
+	}, structNames(m))
+}
+
+func TestLoadInvalidBuildConstraint(t *testing.T) {
+	m, err := analysis.Load(context.Background(), "testdata/badtags", analysis.WithPlatform("linux", "amd64"))
+	if err == nil || !strings.HasSuffix(err.Error(), "testdata/badtags: bad.go: parsing //go:build line: unexpected end of expression") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertEqual(t, (*analysis.Module)(nil), m)
+}
+
+func TestLoadCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	m, err := analysis.Load(ctx, "testdata/lib")
+	assertEqual(t, context.Canceled, err)
+	assertEqual(t, (*analysis.Module)(nil), m)
+}
+
+func TestLoadMissingModule(t *testing.T) {
+	_, err := analysis.Load(context.Background(), "testdata/missing")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
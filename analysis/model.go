@@ -0,0 +1,194 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+type Position struct {
+	Filename string
+	Line     int
+	Column   int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+}
+
+type LineCounts struct {
+	Code    int
+	Comment int
+	Blank   int
+}
+
+func (l LineCounts) Total() int {
+	return l.Code + l.Comment + l.Blank
+}
+
+type Complexity struct {
+	Cyclomatic int
+	Cognitive  int
+	Lines      int
+	Params     int
+	Nesting    int
+}
+
+type Field struct {
+	Name     string
+	Type     string
+	Position Position
+}
+
+type Method struct {
+	Signature  string
+	Receiver   string
+	Position   Position
+	Complexity *Complexity
+}
+
+type Struct struct {
+	Name     string
+	Fields   []*Field
+	Methods  []*Method
+	Position Position
+}
+
+type Interface struct {
+	Name     string
+	Methods  []*Method
+	Embedded []string
+	Position Position
+}
+
+type NamedType struct {
+	Name     string
+	Type     string
+	Alias    bool
+	Position Position
+}
+
+type Function struct {
+	Name       string
+	Signature  string
+	Position   Position
+	Complexity *Complexity
+}
+
+type Import struct {
+	Name     string
+	Path     string
+	StdLib   bool
+	Position Position
+}
+
+type File struct {
+	Path             string
+	BuildConstraints []string
+	Structs          []*Struct
+	Imports          []*Import
+	Functions        []*Function
+	Methods          []*Method
+	Interfaces       []*Interface
+	Types            []*NamedType
+	Lines            LineCounts
+	Position         Position
+}
+
+type Package struct {
+	Name  string
+	Path  string
+	Dir   string
+	Doc   string
+	Files []*File
+}
+
+type converter struct {
+	methods map[*internal.Method]*Method
+}
+
+func newPackages(directories map[string]*internal.Directory) []*Package {
+	c := &converter{methods: map[*internal.Method]*Method{}}
+
+	var packages []*Package
+	for _, directory := range internal.DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range internal.PackagesMap(directory.Packages).SortedPackages() {
+			p := &Package{Name: pkg.Name, Path: pkg.ModulePath, Dir: directory.Path, Doc: pkg.Doc}
+			for _, f := range pkg.Files {
+				p.Files = append(p.Files, c.file(f))
+			}
+			packages = append(packages, p)
+		}
+	}
+	return packages
+}
+
+func position(p internal.Position) Position {
+	return Position{Filename: p.Filename, Line: p.Line, Column: p.Column}
+}
+
+func complexity(c *internal.Complexity) *Complexity {
+	if c == nil {
+		return nil
+	}
+	return &Complexity{Cyclomatic: c.Cyclomatic, Cognitive: c.Cognitive, Lines: c.Lines, Params: c.Params, Nesting: c.Nesting}
+}
+
+func (c *converter) method(m *internal.Method) *Method {
+	if res, ok := c.methods[m]; ok {
+		return res
+	}
+	res := &Method{Signature: m.Signature, Receiver: m.Receiver, Position: position(m.Position), Complexity: complexity(m.Complexity)}
+	c.methods[m] = res
+	return res
+}
+
+func (c *converter) methodList(methods []*internal.Method) []*Method {
+	var res []*Method
+	for _, m := range methods {
+		res = append(res, c.method(m))
+	}
+	return res
+}
+
+func (c *converter) file(f *internal.File) *File {
+	res := &File{
+		Path:             f.Path,
+		BuildConstraints: f.BuildConstraints,
+		Methods:          c.methodList(f.Methods),
+		Lines:            LineCounts{Code: f.Lines.Code, Comment: f.Lines.Comment, Blank: f.Lines.Blank},
+		Position:         position(f.Position),
+	}
+
+	for _, s := range f.Structs {
+		st := &Struct{Name: s.Name, Methods: c.methodList(s.Methods), Position: position(s.Position)}
+		for _, field := range s.Fields {
+			st.Fields = append(st.Fields, &Field{Name: field.Name, Type: field.Type, Position: position(field.Position)})
+		}
+		res.Structs = append(res.Structs, st)
+	}
+	for _, i := range f.Imports {
+		res.Imports = append(res.Imports, &Import{Name: i.Name, Path: i.Path, StdLib: i.StdLib, Position: position(i.Position)})
+	}
+	for _, fn := range f.Functions {
+		res.Functions = append(res.Functions, &Function{
+			Name:       fn.Name,
+			Signature:  fn.Signature,
+			Position:   position(fn.Position),
+			Complexity: complexity(fn.Complexity),
+		})
+	}
+	for _, i := range f.Interfaces {
+		res.Interfaces = append(res.Interfaces, &Interface{
+			Name:     i.Name,
+			Methods:  c.methodList(i.Methods),
+			Embedded: i.Embedded,
+			Position: position(i.Position),
+		})
+	}
+	for _, t := range f.Types {
+		res.Types = append(res.Types, &NamedType{Name: t.Name, Type: t.Type, Alias: t.Alias, Position: position(t.Position)})
+	}
+
+	return res
+}
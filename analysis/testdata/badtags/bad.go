@@ -0,0 +1,5 @@
+//go:build linux &&
+
+package badtags
+
+type Bad struct{}
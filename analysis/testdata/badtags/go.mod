@@ -0,0 +1,3 @@
+module example.com/badtags
+
+go 1.19
@@ -0,0 +1,5 @@
+//go:build debug
+
+package lib
+
+type DebugClient struct{}
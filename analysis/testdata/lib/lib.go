@@ -0,0 +1,11 @@
+package lib
+
+import "example.com/lib/store"
+
+type Client struct {
+	Store *store.Store
+}
+
+func (c *Client) Get(key string) string {
+	return c.Store.Get(key)
+}
@@ -0,0 +1,7 @@
+//go:build go1.18
+
+package lib
+
+type ModernClient struct {
+	Name string
+}
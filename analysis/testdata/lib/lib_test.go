@@ -0,0 +1,3 @@
+package lib_test
+
+type fakeStore struct{}
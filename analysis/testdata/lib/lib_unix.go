@@ -0,0 +1,7 @@
+//go:build unix && gc
+
+package lib
+
+type UnixClient struct {
+	Name string
+}
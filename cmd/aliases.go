@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func aliases() *Command {
+	var command *Command
+	command = &Command{
+		Name:        "aliases",
+		Description: "Display import aliases, dot imports and blank imports per imported package",
+		DefaultArg:  ".",
+		Flags: map[string]*Flag{
+			"tests":     {"t", false, "include test files"},
+			"positions": {"p", false, "prefix output lines with source positions"},
+		},
+		Run: func(out io.Writer, args []string, project *Project) error {
+			var target string
+			var module string
+			var err error
+			var directories map[string]*internal.Directory
+
+			includeTests := command.Flags["tests"].Value.(bool)
+			positions := command.Flags["positions"].Value.(bool)
+
+			target, err = filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+
+			module, err = getModule(target)
+			if err != nil {
+				return err
+			}
+
+			directories, err = loadDirectories(target, module, project)
+			if err != nil {
+				return err
+			}
+
+			config := &internal.Config{
+				IncludeTests: includeTests,
+			}
+
+			for _, directory := range directories {
+				internal.ParsePackage(directory, module, target, config)
+			}
+
+			fmt.Fprintf(out, "%s", internal.FormatImportAliases(directories, module, config, &internal.AliasesOptions{Positions: positions}))
+
+			return nil
+		},
+	}
+	return command
+}
@@ -0,0 +1,350 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+var packageFlags = map[string]struct{}{
+	"select":       {},
+	"exclude":      {},
+	"select-exact": {},
+}
+
+func completion() *Command {
+	return &Command{
+		Name:        "completion",
+		Description: "Generate a shell completion script",
+		ValidArgs:   []string{"bash", "zsh", "fish"},
+		Run: func(out io.Writer, args []string, project *Project) error {
+			commands := visibleCommands(root())
+
+			switch args[0] {
+			case "bash":
+				fmt.Fprintf(out, "%s", bashCompletion(commands))
+			case "zsh":
+				fmt.Fprintf(out, "%s", zshCompletion(commands))
+			case "fish":
+				fmt.Fprintf(out, "%s", fishCompletion(commands))
+			default:
+				return fmt.Errorf("unsupported shell: %s", args[0])
+			}
+
+			return nil
+		},
+	}
+}
+
+func complete() *Command {
+	return &Command{
+		Name:        "__complete",
+		Description: "List package paths for shell completion",
+		DefaultArg:  ".",
+		Hidden:      true,
+		Run: func(out io.Writer, args []string, project *Project) error {
+			target, err := filepath.Abs(completionPath(args))
+			if err != nil {
+				return err
+			}
+
+			project, err = loadProject(target)
+			if err != nil {
+				return err
+			}
+
+			module, err := getModule(target)
+			if err != nil {
+				return err
+			}
+
+			directories, err := loadDirectories(target, module, project)
+			if err != nil {
+				return err
+			}
+
+			unique := map[string]struct{}{}
+			for _, directory := range directories {
+				if err := internal.ParsePackage(directory, module, target, &internal.Config{}); err != nil {
+					return err
+				}
+				for _, pkg := range directory.Packages {
+					unique[pkg.ModulePath] = struct{}{}
+				}
+			}
+
+			for _, p := range sortedKeys(unique) {
+				fmt.Fprintf(out, "%s\n", p)
+			}
+
+			return nil
+		},
+	}
+}
+
+func completionPath(args []string) string {
+	c, ok := root().Subcommands[args[0]]
+	if !ok {
+		return args[0]
+	}
+
+	_, positional, _, _, err := parseFlags(c, args[1:])
+	if err != nil {
+		_, positional, _, _, err = parseFlags(c, append(args[1:], ""))
+	}
+	if err == nil && c.PathArg < len(positional) {
+		return positional[c.PathArg]
+	}
+	return "."
+}
+
+func visibleCommands(c *Command) []*Command {
+	var commands []*Command
+	for _, name := range sortedKeys(c.Subcommands) {
+		if !c.Subcommands[name].Hidden {
+			commands = append(commands, c.Subcommands[name])
+		}
+	}
+	return commands
+}
+
+func commandNames(commands []*Command) string {
+	names := make([]string, 0, len(commands))
+	for _, c := range commands {
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, " ")
+}
+
+func flagNames(c *Command) []string {
+	names := []string{"-h", "--help"}
+	for _, k := range sortedKeys(c.Flags) {
+		if c.Flags[k].Short != "" {
+			names = append(names, fmt.Sprintf("-%s", c.Flags[k].Short))
+		}
+		names = append(names, fmt.Sprintf("--%s", k))
+	}
+	return append(names, "--preset")
+}
+
+func packageFlagNames(commands []*Command) []string {
+	unique := map[string]struct{}{}
+	for _, c := range commands {
+		for k, f := range c.Flags {
+			if _, ok := packageFlags[k]; !ok {
+				continue
+			}
+			unique[fmt.Sprintf("--%s", k)] = struct{}{}
+			if f.Short != "" {
+				unique[fmt.Sprintf("-%s", f.Short)] = struct{}{}
+			}
+		}
+	}
+	return sortedKeys(unique)
+}
+
+func takesValue(f *Flag) bool {
+	_, ok := f.Value.(bool)
+	return !ok
+}
+
+func bashCompletion(commands []*Command) string {
+	var sb strings.Builder
+
+	sb.WriteString("# bash completion for godiss\n")
+	sb.WriteString("_godiss() {\n")
+	sb.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	sb.WriteString("    local prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	sb.WriteString("    local cmd=\"${COMP_WORDS[1]}\"\n\n")
+
+	sb.WriteString("    if [[ ${COMP_CWORD} -eq 1 ]]; then\n")
+	sb.WriteString(fmt.Sprintf("        COMPREPLY=($(compgen -W \"%s help\" -- \"${cur}\"))\n", commandNames(commands)))
+	sb.WriteString("        return\n")
+	sb.WriteString("    fi\n\n")
+
+	sb.WriteString("    case \"${prev}\" in\n")
+	sb.WriteString(fmt.Sprintf("        %s)\n", strings.Join(packageFlagNames(commands), "|")))
+	sb.WriteString("            COMPREPLY=($(compgen -W \"$(godiss __complete -- \"${COMP_WORDS[@]:1}\" 2>/dev/null)\" -- \"${cur}\"))\n")
+	sb.WriteString("            return\n")
+	sb.WriteString("            ;;\n")
+	sb.WriteString("    esac\n\n")
+
+	sb.WriteString("    case \"${cmd}\" in\n")
+	sb.WriteString("        help)\n")
+	sb.WriteString(fmt.Sprintf("            COMPREPLY=($(compgen -W \"%s\" -- \"${cur}\"))\n", commandNames(commands)))
+	sb.WriteString("            ;;\n")
+
+	for _, c := range commands {
+		sb.WriteString(fmt.Sprintf("        %s)\n", c.Name))
+		sb.WriteString("            if [[ ${cur} == -* ]]; then\n")
+		sb.WriteString(fmt.Sprintf("                COMPREPLY=($(compgen -W \"%s\" -- \"${cur}\"))\n", strings.Join(flagNames(c), " ")))
+		sb.WriteString("                return\n")
+		sb.WriteString("            fi\n")
+		switch {
+		case len(c.ValidArgs) > 0:
+			sb.WriteString(fmt.Sprintf("            COMPREPLY=($(compgen -W \"%s\" -- \"${cur}\"))\n", strings.Join(c.ValidArgs, " ")))
+		case c.DefaultArg == "":
+			sb.WriteString("            COMPREPLY=($(compgen -f -- \"${cur}\"))\n")
+		default:
+			sb.WriteString("            COMPREPLY=($(compgen -d -- \"${cur}\"))\n")
+		}
+		sb.WriteString("            ;;\n")
+	}
+
+	sb.WriteString("    esac\n")
+	sb.WriteString("}\n\n")
+	sb.WriteString("complete -o filenames -F _godiss godiss\n")
+
+	return sb.String()
+}
+
+func zshQuote(v string) string {
+	return strings.ReplaceAll(v, "'", `'\''`)
+}
+
+func zshDescription(v string) string {
+	return zshQuote(strings.NewReplacer("[", `\[`, "]", `\]`, ":", `\:`).Replace(v))
+}
+
+func zshCompletion(commands []*Command) string {
+	var sb strings.Builder
+
+	sb.WriteString("#compdef godiss\n\n")
+
+	sb.WriteString("_godiss_packages() {\n")
+	sb.WriteString("    local -a packages\n")
+	sb.WriteString("    packages=(${(f)\"$(godiss __complete -- \"${(@)words[2,-1]}\" 2>/dev/null)\"})\n")
+	sb.WriteString("    compadd -a packages\n")
+	sb.WriteString("}\n\n")
+
+	sb.WriteString("_godiss() {\n")
+	sb.WriteString("    local -a commands\n")
+	sb.WriteString("    commands=(\n")
+	for _, c := range commands {
+		sb.WriteString(fmt.Sprintf("        '%s:%s'\n", c.Name, zshDescription(c.Description)))
+	}
+	sb.WriteString("        'help:Display help for a command'\n")
+	sb.WriteString("    )\n\n")
+
+	sb.WriteString("    if (( CURRENT == 2 )); then\n")
+	sb.WriteString("        _describe 'command' commands\n")
+	sb.WriteString("        return\n")
+	sb.WriteString("    fi\n\n")
+
+	sb.WriteString("    case \"${words[2]}\" in\n")
+	sb.WriteString("        help)\n")
+	sb.WriteString(fmt.Sprintf("            _arguments '1:command:(%s)'\n", commandNames(commands)))
+	sb.WriteString("            ;;\n")
+
+	for _, c := range commands {
+		sb.WriteString(fmt.Sprintf("        %s)\n", c.Name))
+		sb.WriteString("            _arguments \\\n")
+		sb.WriteString("                '(-h --help)'{-h,--help}'[show help for this command]' \\\n")
+
+		for _, k := range sortedKeys(c.Flags) {
+			f := c.Flags[k]
+
+			names := fmt.Sprintf("--%s", k)
+			spec := fmt.Sprintf("'--%s", k)
+			if f.Short != "" {
+				names = fmt.Sprintf("-%s --%s", f.Short, k)
+				spec = fmt.Sprintf("'(%s)'{-%s,--%s}'", names, f.Short, k)
+			}
+
+			value := ""
+			if takesValue(f) {
+				value = fmt.Sprintf(":%s:", k)
+				if _, ok := packageFlags[k]; ok {
+					value = ":package:_godiss_packages"
+				}
+			}
+
+			sb.WriteString(fmt.Sprintf("                %s[%s]%s' \\\n", spec, zshDescription(f.Usage), value))
+		}
+
+		sb.WriteString("                '--preset[apply a named preset from the project configuration]:preset:' \\\n")
+
+		switch {
+		case len(c.ValidArgs) > 0:
+			sb.WriteString(fmt.Sprintf("                '1:argument:(%s)'\n", strings.Join(c.ValidArgs, " ")))
+		case c.DefaultArg == "":
+			sb.WriteString("                '1:file:_files'\n")
+		default:
+			sb.WriteString("                '1:directory:_files -/'\n")
+		}
+		sb.WriteString("            ;;\n")
+	}
+
+	sb.WriteString("    esac\n")
+	sb.WriteString("}\n\n")
+	sb.WriteString("_godiss \"$@\"\n")
+
+	return sb.String()
+}
+
+func fishQuote(v string) string {
+	return fmt.Sprintf("'%s'", strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(v))
+}
+
+func fishCompletion(commands []*Command) string {
+	var sb strings.Builder
+
+	sb.WriteString("# fish completion for godiss\n")
+	sb.WriteString("function __godiss_packages\n")
+	sb.WriteString("    set -l tokens (commandline -opc) (commandline -ct)\n")
+	sb.WriteString("    godiss __complete -- $tokens[2..-1] 2>/dev/null\n")
+	sb.WriteString("end\n\n")
+
+	sb.WriteString("complete -c godiss -f\n")
+	for _, c := range commands {
+		sb.WriteString(fmt.Sprintf(
+			"complete -c godiss -n __fish_use_subcommand -a %s -d %s\n",
+			c.Name, fishQuote(c.Description),
+		))
+	}
+	sb.WriteString("complete -c godiss -n __fish_use_subcommand -a help -d 'Display help for a command'\n")
+	sb.WriteString(fmt.Sprintf(
+		"complete -c godiss -n '__fish_seen_subcommand_from help' -a %s\n",
+		fishQuote(commandNames(commands)),
+	))
+
+	for _, c := range commands {
+		condition := fishQuote(fmt.Sprintf("__fish_seen_subcommand_from %s", c.Name))
+
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf("complete -c godiss -n %s -s h -l help -d 'show help for this command'\n", condition))
+
+		for _, k := range sortedKeys(c.Flags) {
+			f := c.Flags[k]
+
+			line := fmt.Sprintf("complete -c godiss -n %s", condition)
+			if f.Short != "" {
+				line = fmt.Sprintf("%s -s %s", line, f.Short)
+			}
+			line = fmt.Sprintf("%s -l %s", line, k)
+			if takesValue(f) {
+				line = fmt.Sprintf("%s -r", line)
+				if _, ok := packageFlags[k]; ok {
+					line = fmt.Sprintf("%s -a '(__godiss_packages)'", line)
+				}
+			}
+			sb.WriteString(fmt.Sprintf("%s -d %s\n", line, fishQuote(f.Usage)))
+		}
+
+		sb.WriteString(fmt.Sprintf("complete -c godiss -n %s -l preset -r -d 'apply a named preset from the project configuration'\n", condition))
+
+		switch {
+		case len(c.ValidArgs) > 0:
+			sb.WriteString(fmt.Sprintf("complete -c godiss -n %s -a %s\n", condition, fishQuote(strings.Join(c.ValidArgs, " "))))
+		case c.DefaultArg == "":
+			sb.WriteString(fmt.Sprintf("complete -c godiss -n %s -F\n", condition))
+		default:
+			sb.WriteString(fmt.Sprintf("complete -c godiss -n %s -a '(__fish_complete_directories)'\n", condition))
+		}
+	}
+
+	return sb.String()
+}
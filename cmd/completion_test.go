@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompletePackages(t *testing.T) {
+	stdout, stderr, err := execute("__complete", "testdata/project")
+	assertEqual(t, nil, err)
+	assertEqual(t, "example.com/project\nexample.com/project/api\n", stdout)
+	assertEqual(t, "", stderr)
+
+	stdout, stderr, err = execute("__complete", "--", "tests", "--summary", "-e", "x", "testdata/project", "-s", "")
+	assertEqual(t, nil, err)
+	assertEqual(t, "example.com/project\nexample.com/project/api\n", stdout)
+	assertEqual(t, "", stderr)
+
+	stdout, _, err = execute("__complete", "--", "tests", "testdata/project", "-s")
+	assertEqual(t, nil, err)
+	assertEqual(t, "example.com/project\nexample.com/project/api\n", stdout)
+}
+
+func TestCompletionHidesInternalCommands(t *testing.T) {
+	stdout, _, err := execute()
+	assertEqual(t, nil, err)
+	assertEqual(t, false, strings.Contains(stdout, "__complete"))
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		stdout, _, err := execute("completion", shell)
+		assertEqual(t, nil, err, shell)
+		assertEqual(t, false, strings.Contains(stdout, "__complete)"), shell)
+		assertEqual(t, false, strings.Contains(stdout, "-a __complete"), shell)
+	}
+}
+
+func TestBashCompletion(t *testing.T) {
+	stdout, _, err := execute("completion", "bash")
+	assertEqual(t, nil, err)
+
+	for _, expected := range []string{
+		"        --exclude|--select|--select-exact|-E|-e|-s)\n" +
+			"            COMPREPLY=($(compgen -W \"$(godiss __complete -- \"${COMP_WORDS[@]:1}\" 2>/dev/null)\" -- \"${cur}\"))\n",
+		"        structs)\n" +
+			"            if [[ ${cur} == -* ]]; then\n" +
+			"                COMPREPLY=($(compgen -W \"-h --help --dot-path -f --format -o --output --preset\" -- \"${cur}\"))\n" +
+			"                return\n" +
+			"            fi\n" +
+			"            COMPREPLY=($(compgen -f -- \"${cur}\"))\n",
+		"        completion)\n" +
+			"            if [[ ${cur} == -* ]]; then\n" +
+			"                COMPREPLY=($(compgen -W \"-h --help --preset\" -- \"${cur}\"))\n" +
+			"                return\n" +
+			"            fi\n" +
+			"            COMPREPLY=($(compgen -W \"bash zsh fish\" -- \"${cur}\"))\n",
+		"                COMPREPLY=($(compgen -W \"-h --help -r --roots -t --tests --preset\" -- \"${cur}\"))\n",
+		"complete -o filenames -F _godiss godiss\n",
+	} {
+		assertEqual(t, true, strings.Contains(stdout, expected), expected)
+	}
+}
+
+func TestZshCompletion(t *testing.T) {
+	stdout, _, err := execute("completion", "zsh")
+	assertEqual(t, nil, err)
+
+	for _, expected := range []string{
+		"#compdef godiss\n",
+		"        'coupling:Display package coupling metrics (Ca, Ce, I, A, D)'\n",
+		"                '(-s --select)'{-s,--select}'[select packages]:package:_godiss_packages' \\\n",
+		"                '(-a --arch)'{-a,--arch}'[target GOARCH used to compute sizes]:arch:' \\\n",
+		"                '--wasted[only display structs which can be made smaller by reordering fields]' \\\n",
+		"                '1:file:_files'\n",
+		"                '1:directory:_files -/'\n",
+	} {
+		assertEqual(t, true, strings.Contains(stdout, expected), expected)
+	}
+}
+
+func TestFishCompletion(t *testing.T) {
+	stdout, _, err := execute("completion", "fish")
+	assertEqual(t, nil, err)
+
+	for _, expected := range []string{
+		"complete -c godiss -n __fish_use_subcommand -a layout -d 'Display struct sizes, field offsets and padding'\n",
+		"complete -c godiss -n '__fish_seen_subcommand_from tests' -s s -l select -r -a '(__godiss_packages)' -d 'select packages'\n",
+		"complete -c godiss -n '__fish_seen_subcommand_from complexity' -s n -l top -r -d 'number of functions to display (0 for all)'\n",
+		"complete -c godiss -n '__fish_seen_subcommand_from structs' -F\n",
+		"complete -c godiss -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'\n",
+	} {
+		assertEqual(t, true, strings.Contains(stdout, expected), expected)
+	}
+}
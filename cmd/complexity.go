@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func complexity() *Command {
+	var command *Command
+	command = &Command{
+		Name:        "complexity",
+		Description: "Display function complexity and size metrics",
+		DefaultArg:  ".",
+		Flags: map[string]*Flag{
+			"top":       {"n", 20, "number of functions to display (0 for all)"},
+			"sort":      {"", "cognitive", fmt.Sprintf("sort by metric (%s)", strings.Join(internal.ComplexityColumns, ", "))},
+			"select":    {"s", []string{}, "select packages"},
+			"exclude":   {"e", []string{}, "exclude packages"},
+			"positions": {"p", false, "prefix output lines with source positions"},
+		},
+		Run: func(out io.Writer, args []string, project *Project) error {
+			var target string
+			var module string
+			var err error
+			var directories map[string]*internal.Directory
+
+			top := command.Flags["top"].Value.(int)
+			sortBy := command.Flags["sort"].Value.(string)
+			selected := command.Flags["select"].Value.([]string)
+			exclude := command.Flags["exclude"].Value.([]string)
+			positions := command.Flags["positions"].Value.(bool)
+
+			if !contains(internal.ComplexityColumns, strings.ToLower(sortBy)) {
+				return fmt.Errorf("unknown sort metric: %s", sortBy)
+			}
+
+			target, err = filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+
+			module, err = getModule(target)
+			if err != nil {
+				return err
+			}
+
+			directories, err = loadDirectories(target, module, project)
+			if err != nil {
+				return err
+			}
+
+			config := &internal.Config{
+				Select:  createSet(selected),
+				Exclude: createSet(exclude),
+			}
+
+			for _, directory := range directories {
+				internal.ParsePackage(directory, module, target, config)
+			}
+
+			fmt.Fprintf(out, "%s", internal.FormatComplexity(directories, module, config, &internal.ComplexityOptions{
+				Sort:      sortBy,
+				Top:       top,
+				Positions: positions,
+			}))
+
+			return nil
+		},
+	}
+	return command
+}
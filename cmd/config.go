@@ -0,0 +1,392 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slavsan/godiss/internal"
+	"gopkg.in/yaml.v3"
+)
+
+var projectFiles = []string{".godiss.yaml", ".godiss.yml", ".godiss.json"}
+
+type Project struct {
+	Path     string                    `json:"-"`
+	Ignore   []string                  `json:"ignore"`
+	Style    *internal.DiagramStyle    `json:"style"`
+	Presets  map[string]map[string]any `json:"presets"`
+	Commands map[string]map[string]any `json:"commands"`
+}
+
+func findProjectFile(start string) (string, error) {
+	info, err := os.Stat(start)
+	if err != nil {
+		return "", nil
+	}
+	if !info.IsDir() {
+		start = filepath.Dir(start)
+	}
+
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		for _, name := range projectFiles {
+			p := filepath.Join(dir, name)
+			if _, err := os.Stat(p); err == nil {
+				return p, nil
+			}
+		}
+
+		toml := filepath.Join(dir, ".godiss.toml")
+		if _, err := os.Stat(toml); err == nil {
+			return "", fmt.Errorf("%s: TOML is not supported, use one of %s", toml, strings.Join(projectFiles, ", "))
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return "", nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+func loadProject(start string) (*Project, error) {
+	p, err := findProjectFile(start)
+	if err != nil || p == "" {
+		return &Project{}, err
+	}
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := decodeProject(p, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", p, err.Error())
+	}
+	res.Path = p
+
+	return res, nil
+}
+
+func decodeProject(name string, data []byte) (*Project, error) {
+	var raw map[string]any
+	var err error
+	if strings.HasSuffix(name, ".json") {
+		err = json.Unmarshal(data, &raw)
+	} else {
+		err = yaml.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Project{}
+	err = json.Unmarshal(encoded, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (p *Project) Values(c *Command, preset string) (map[string]any, error) {
+	values := map[string]any{}
+
+	defaults := p.Commands[c.Name]
+	for k, v := range defaults {
+		if k == "preset" {
+			continue
+		}
+		if _, ok := c.Flags[k]; !ok {
+			return nil, fmt.Errorf("%s: unknown flag %s for command %s", p.Path, k, c.Name)
+		}
+		values[k] = v
+	}
+
+	if preset == "" {
+		if v, ok := defaults["preset"].(string); ok {
+			preset = v
+		}
+	}
+
+	if preset != "" {
+		options, ok := p.Presets[preset]
+		if !ok {
+			return nil, fmt.Errorf("unknown preset: %s", preset)
+		}
+		for k, v := range options {
+			if _, ok := c.Flags[k]; !ok {
+				return nil, fmt.Errorf("%s: unknown flag %s in preset %s for command %s", p.Path, k, preset, c.Name)
+			}
+			values[k] = v
+		}
+	}
+
+	return values, nil
+}
+
+func (p *Project) Apply(c *Command, preset string, explicit map[string]struct{}) error {
+	values, err := p.Values(c, preset)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range values {
+		if _, ok := explicit[k]; ok {
+			continue
+		}
+		value, err := convertFlagValue(c.Flags[k].Value, v)
+		if err != nil {
+			return fmt.Errorf("%s: flag %s of command %s: %s", p.Path, k, c.Name, err.Error())
+		}
+		c.Flags[k].Value = value
+	}
+
+	return nil
+}
+
+func (p *Project) Ignored(dir string) bool {
+	if p.Path == "" {
+		return false
+	}
+
+	rel, err := filepath.Rel(filepath.Dir(p.Path), dir)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	for _, pattern := range p.Ignore {
+		pattern = strings.Trim(pattern, "/")
+		if rel == pattern || strings.HasPrefix(rel, pattern+"/") {
+			return true
+		}
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func convertFlagValue(current, v any) (any, error) {
+	switch current.(type) {
+	case bool:
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case string:
+			return strconv.ParseBool(b)
+		}
+	case int:
+		switch n := v.(type) {
+		case float64:
+			if n != math.Trunc(n) || n > math.MaxInt || n < math.MinInt {
+				return nil, fmt.Errorf("%v is not an integer", n)
+			}
+			return int(n), nil
+		case string:
+			return strconv.Atoi(n)
+		}
+	case time.Duration:
+		switch d := v.(type) {
+		case string:
+			return time.ParseDuration(d)
+		case float64:
+			return nil, fmt.Errorf("durations need a unit, e.g. \"%vs\"", d)
+		}
+	case []string:
+		switch l := v.(type) {
+		case []any:
+			items := make([]string, 0, len(l))
+			for _, item := range l {
+				items = append(items, fmt.Sprint(item))
+			}
+			return items, nil
+		case string:
+			var items []string
+			for _, item := range strings.Split(l, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			return items, nil
+		}
+	case string:
+		switch s := v.(type) {
+		case []any:
+			items := make([]string, 0, len(s))
+			for _, item := range s {
+				items = append(items, fmt.Sprint(item))
+			}
+			return strings.Join(items, ","), nil
+		case float64:
+			return strconv.FormatFloat(s, 'f', -1, 64), nil
+		default:
+			return fmt.Sprint(s), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot use %v as %T", v, current)
+}
+
+func loadDirectories(target, module string, project *Project) (map[string]*internal.Directory, error) {
+	directories, err := internal.LoadPackages(target, module, target)
+	if err != nil {
+		return nil, err
+	}
+
+	for p := range directories {
+		if project.Ignored(p) {
+			delete(directories, p)
+		}
+	}
+
+	return directories, nil
+}
+
+func formatYAMLValue(v any) string {
+	switch value := v.(type) {
+	case string:
+		if value == "" || strings.ContainsAny(value, ":#[]{},\"'") || strings.TrimSpace(value) != value {
+			return strconv.Quote(value)
+		}
+		switch value {
+		case "true", "false", "yes", "no", "on", "off", "null", "~":
+			return strconv.Quote(value)
+		}
+		if _, err := strconv.Atoi(value); err == nil {
+			return strconv.Quote(value)
+		}
+		return value
+	case []any:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, formatYAMLValue(item))
+		}
+		return fmt.Sprintf("[%s]", strings.Join(items, ", "))
+	case []string:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, formatYAMLValue(item))
+		}
+		return fmt.Sprintf("[%s]", strings.Join(items, ", "))
+	case time.Duration:
+		return value.String()
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func config() *Command {
+	var command *Command
+	command = &Command{
+		Name:        "config",
+		Description: "Display the effective configuration",
+		DefaultArg:  ".",
+		Flags: map[string]*Flag{
+			"command": {"", "", "only display the options of the given command"},
+		},
+		Run: func(out io.Writer, args []string, project *Project) error {
+			var sb strings.Builder
+
+			only := command.Flags["command"].Value.(string)
+
+			if project.Path != "" {
+				sb.WriteString(fmt.Sprintf("# %s\n", project.Path))
+			} else {
+				sb.WriteString(fmt.Sprintf("# no %s found\n", strings.Join(projectFiles, ", ")))
+			}
+
+			if len(project.Ignore) > 0 {
+				sb.WriteString("ignore:\n")
+				for _, i := range project.Ignore {
+					sb.WriteString(fmt.Sprintf("  - %s\n", formatYAMLValue(i)))
+				}
+			}
+
+			if project.Style != nil {
+				encoded, err := json.Marshal(project.Style)
+				if err != nil {
+					return err
+				}
+				var style map[string]any
+				if err := json.Unmarshal(encoded, &style); err != nil {
+					return err
+				}
+				sb.WriteString("style:\n")
+				for _, k := range sortedKeys(style) {
+					if style[k] == "" {
+						continue
+					}
+					sb.WriteString(fmt.Sprintf("  %s: %s\n", k, formatYAMLValue(style[k])))
+				}
+			}
+
+			if len(project.Presets) > 0 {
+				sb.WriteString("presets:\n")
+				for _, name := range sortedKeys(project.Presets) {
+					sb.WriteString(fmt.Sprintf("  %s:\n", name))
+					options := project.Presets[name]
+					for _, k := range sortedKeys(options) {
+						sb.WriteString(fmt.Sprintf("    %s: %s\n", k, formatYAMLValue(options[k])))
+					}
+				}
+			}
+
+			commands := root().Subcommands
+			sb.WriteString("commands:\n")
+			for _, name := range sortedKeys(commands) {
+				c := commands[name]
+				if name == command.Name || len(c.Flags) == 0 || (only != "" && name != only) {
+					continue
+				}
+
+				if err := project.Apply(c, "", nil); err != nil {
+					return err
+				}
+
+				sb.WriteString(fmt.Sprintf("  %s:\n", name))
+				for _, k := range sortedKeys(c.Flags) {
+					sb.WriteString(fmt.Sprintf("    %s: %s\n", k, formatYAMLValue(c.Flags[k].Value)))
+				}
+			}
+
+			fmt.Fprintf(out, "%s", sb.String())
+
+			return nil
+		},
+	}
+	return command
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDecodeProject(t *testing.T) {
+	p, err := decodeProject(".godiss.yaml", []byte(`
+# comment
+ignore:
+  - examples
+  - "internal/testdata"   # fixtures
+style: {rankdir: TB, fontname: 'Helvetica, Arial'}
+presets:
+  base: &base
+    top: 10
+    select: [internal, cmd]
+  "api only":
+    <<: *base
+    select:
+      - api
+commands:
+  complexity: *base
+  docs:
+    output: >-
+      docs/
+      api
+`))
+	assertEqual(t, nil, err)
+	assertEqual(t, []string{"examples", "internal/testdata"}, p.Ignore)
+	assertEqual(t, "TB", p.Style.RankDir)
+	assertEqual(t, "Helvetica, Arial", p.Style.FontName)
+	assertEqual(t, map[string]map[string]any{
+		"base":     {"top": float64(10), "select": []any{"internal", "cmd"}},
+		"api only": {"top": float64(10), "select": []any{"api"}},
+	}, p.Presets)
+	assertEqual(t, map[string]map[string]any{
+		"complexity": {"top": float64(10), "select": []any{"internal", "cmd"}},
+		"docs":       {"output": "docs/ api"},
+	}, p.Commands)
+
+	p, err = decodeProject(".godiss.json", []byte(`{"ignore": ["examples"], "commands": {"stats": {"table": true}}}`))
+	assertEqual(t, nil, err)
+	assertEqual(t, []string{"examples"}, p.Ignore)
+	assertEqual(t, map[string]map[string]any{"stats": {"table": true}}, p.Commands)
+
+	_, err = decodeProject(".godiss.yaml", []byte("style:\n  rankdir: TB\n    font: x\n"))
+	assertEqual(t, true, err != nil)
+
+	_, err = decodeProject(".godiss.yaml", []byte("ignore: examples\n"))
+	assertEqual(t, true, err != nil)
+}
+
+func TestConvertFlagValue(t *testing.T) {
+	for _, tc := range []struct {
+		current  any
+		value    any
+		expected any
+	}{
+		{false, true, true},
+		{false, "true", true},
+		{0, float64(5), 5},
+		{0, "7", 7},
+		{"", []any{"a", "b"}, "a,b"},
+		{"", float64(3), "3"},
+		{time.Duration(0), "1m30s", 90 * time.Second},
+		{[]string{}, []any{"a", "b"}, []string{"a", "b"}},
+		{[]string{}, "a, b", []string{"a", "b"}},
+	} {
+		actual, err := convertFlagValue(tc.current, tc.value)
+		assertEqual(t, nil, err)
+		assertEqual(t, tc.expected, actual)
+	}
+
+	_, err := convertFlagValue(0, true)
+	assertEqual(t, "cannot use true as int", err.Error())
+
+	_, err = convertFlagValue(0, 2.7)
+	assertEqual(t, "2.7 is not an integer", err.Error())
+
+	_, err = convertFlagValue(time.Duration(0), float64(2))
+	assertEqual(t, `durations need a unit, e.g. "2s"`, err.Error())
+}
+
+func TestProjectApply(t *testing.T) {
+	c := &Command{
+		Name: "stats",
+		Flags: map[string]*Flag{
+			"top":       {"t", 0, ""},
+			"positions": {"p", false, ""},
+			"select":    {"s", []string{}, ""},
+		},
+	}
+	p := &Project{
+		Path: "/repo/.godiss.yaml",
+		Presets: map[string]map[string]any{
+			"small": {"top": float64(3)},
+		},
+		Commands: map[string]map[string]any{
+			"stats": {"top": float64(10), "positions": "true", "select": []any{"cmd"}, "preset": "small"},
+		},
+	}
+
+	err := p.Apply(c, "", map[string]struct{}{"positions": {}})
+	assertEqual(t, nil, err)
+	assertEqual(t, 3, c.Flags["top"].Value)
+	assertEqual(t, false, c.Flags["positions"].Value)
+	assertEqual(t, []string{"cmd"}, c.Flags["select"].Value)
+
+	err = p.Apply(c, "missing", nil)
+	assertEqual(t, "unknown preset: missing", err.Error())
+
+	p.Presets["small"]["unknown"] = true
+	err = p.Apply(c, "", nil)
+	assertEqual(t, "/repo/.godiss.yaml: unknown flag unknown in preset small for command stats", err.Error())
+	delete(p.Presets["small"], "unknown")
+
+	p.Commands["stats"]["depth"] = float64(1)
+	err = p.Apply(c, "", nil)
+	assertEqual(t, "/repo/.godiss.yaml: unknown flag depth for command stats", err.Error())
+
+	delete(p.Commands["stats"], "depth")
+	p.Commands["stats"]["top"] = true
+	p.Commands["stats"]["preset"] = ""
+	err = p.Apply(c, "", nil)
+	assertEqual(t, "/repo/.godiss.yaml: flag top of command stats: cannot use true as int", err.Error())
+}
+
+func TestFindProjectFile(t *testing.T) {
+	p, err := findProjectFile("testdata/project/api")
+	assertEqual(t, nil, err)
+	abs, _ := filepath.Abs("testdata/project/.godiss.yaml")
+	assertEqual(t, abs, p)
+
+	p, err = findProjectFile("testdata/project/missing")
+	assertEqual(t, nil, err)
+	assertEqual(t, "", p)
+
+	dir := t.TempDir()
+	assertEqual(t, nil, os.WriteFile(filepath.Join(dir, ".godiss.toml"), []byte("[style]\n"), 0o644))
+	_, err = findProjectFile(dir)
+	assertEqual(t, filepath.Join(dir, ".godiss.toml")+": TOML is not supported, use one of .godiss.yaml, .godiss.yml, .godiss.json", err.Error())
+}
+
+func TestProjectIgnored(t *testing.T) {
+	p := &Project{Path: "/repo/.godiss.yaml", Ignore: []string{"examples", "internal/*/fixtures/"}}
+	assertEqual(t, true, p.Ignored("/repo/examples"))
+	assertEqual(t, true, p.Ignored("/repo/examples/cars"))
+	assertEqual(t, true, p.Ignored("/repo/internal/x/fixtures"))
+	assertEqual(t, false, p.Ignored("/repo/internal/x"))
+	assertEqual(t, false, p.Ignored("/repo/examplesx"))
+	assertEqual(t, false, (&Project{}).Ignored("/repo/examples"))
+}
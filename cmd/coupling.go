@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func coupling() *Command {
+	var command *Command
+	command = &Command{
+		Name:        "coupling",
+		Description: "Display package coupling metrics (Ca, Ce, I, A, D)",
+		DefaultArg:  ".",
+		Flags: map[string]*Flag{
+			"sort":     {"", "d", fmt.Sprintf("sort by column (%s)", strings.Join(internal.CouplingColumns, ", "))},
+			"external": {"x", false, "count stdlib and third-party imports as efferent couplings"},
+			"svg":      {"g", false, "render instability vs abstractness as an SVG scatter plot"},
+		},
+		Run: func(out io.Writer, args []string, project *Project) error {
+			var target string
+			var module string
+			var err error
+			var directories map[string]*internal.Directory
+
+			sortBy := command.Flags["sort"].Value.(string)
+			external := command.Flags["external"].Value.(bool)
+			svg := command.Flags["svg"].Value.(bool)
+
+			if !contains(internal.CouplingColumns, strings.ToLower(sortBy)) {
+				return fmt.Errorf("unknown sort column: %s", sortBy)
+			}
+
+			target, err = filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+
+			module, err = getModule(target)
+			if err != nil {
+				return err
+			}
+
+			requires, err := getRequires(target)
+			if err != nil {
+				return err
+			}
+
+			directories, err = loadDirectories(target, module, project)
+			if err != nil {
+				return err
+			}
+
+			config := &internal.Config{
+				Requires: requires,
+			}
+
+			opts := &internal.CouplingOptions{Sort: sortBy, External: external}
+
+			for _, directory := range directories {
+				internal.ParsePackage(directory, module, target, config)
+			}
+
+			if svg {
+				fmt.Fprintf(out, "%s", internal.FormatCouplingSVG(directories, module, config, opts))
+				return nil
+			}
+
+			fmt.Fprintf(out, "%s", internal.FormatCoupling(directories, module, config, opts))
+
+			return nil
+		},
+	}
+	return command
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func dead() *Command {
+	var command *Command
+	command = &Command{
+		Name:        "dead",
+		Description: "Display packages not reachable from any entrypoint or root",
+		DefaultArg:  ".",
+		Flags: map[string]*Flag{
+			"roots": {"r", []string{}, "public API root packages (supports /...)"},
+			"tests": {"t", false, "treat tests as roots"},
+		},
+		Run: func(out io.Writer, args []string, project *Project) error {
+			var target string
+			var module string
+			var err error
+			var directories map[string]*internal.Directory
+
+			roots := command.Flags["roots"].Value.([]string)
+			tests := command.Flags["tests"].Value.(bool)
+
+			target, err = filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+
+			module, err = getModule(target)
+			if err != nil {
+				return err
+			}
+
+			directories, err = loadDirectories(target, module, project)
+			if err != nil {
+				return err
+			}
+
+			config := &internal.Config{
+				IncludeTests: true,
+			}
+
+			for _, directory := range directories {
+				internal.ParsePackage(directory, module, target, config)
+			}
+
+			fmt.Fprintf(out, "%s", internal.FormatDeadPackages(directories, module, config, &internal.DeadOptions{Roots: roots, Tests: tests}))
+
+			return nil
+		},
+	}
+	return command
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func loadRevision(target, rev string, project *Project) (map[string]*internal.Directory, error) {
+	dest, err := os.MkdirTemp("", "godiss-diff-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dest)
+
+	root, err := internal.ExtractRevision(target, rev, dest)
+	if err != nil {
+		return nil, err
+	}
+
+	module, err := getModule(root)
+	if err != nil {
+		return nil, fmt.Errorf("%s: no go.mod found", rev)
+	}
+
+	directories, err := internal.LoadPackages(root, module, root)
+	if err != nil {
+		return nil, err
+	}
+
+	for p := range directories {
+		rel, err := filepath.Rel(root, p)
+		if err == nil && project.Ignored(filepath.Join(target, rel)) {
+			delete(directories, p)
+		}
+	}
+
+	for _, directory := range directories {
+		if err := internal.ParsePackage(directory, module, root, &internal.Config{}); err != nil {
+			return nil, fmt.Errorf("%s: %w", rev, err)
+		}
+	}
+
+	return directories, nil
+}
+
+func diff() *Command {
+	var command *Command
+	command = &Command{
+		Name:        "diff",
+		Description: "Compare the structure of two git revisions",
+		Args:        "<rev1> <rev2> [path]",
+		PathArg:     2,
+		Flags: map[string]*Flag{
+			"format":   {"f", "text", "output format: text, dot or svg"},
+			"output":   {"o", "", "render the diagram to a .svg, .png or .pdf file using Graphviz"},
+			"dot-path": {"", "dot", "path to the Graphviz dot executable"},
+		},
+		Run: func(out io.Writer, args []string, project *Project) error {
+			format := command.Flags["format"].Value.(string)
+			output := command.Flags["output"].Value.(string)
+
+			if format != "text" && format != "dot" && format != "svg" {
+				return fmt.Errorf("unsupported format: %s", format)
+			}
+
+			if len(args) < 2 {
+				return fmt.Errorf("diff requires two revisions")
+			}
+
+			dir := "."
+			if len(args) > 2 {
+				dir = args[2]
+			}
+
+			target, err := filepath.Abs(dir)
+			if err != nil {
+				return err
+			}
+
+			before, err := loadRevision(target, args[0], project)
+			if err != nil {
+				return err
+			}
+
+			after, err := loadRevision(target, args[1], project)
+			if err != nil {
+				return err
+			}
+
+			diffs := internal.DiffDirectories(before, after)
+
+			if format == "text" && output == "" {
+				fmt.Fprint(out, internal.FormatDiff(diffs))
+				return nil
+			}
+
+			d := internal.DiffDiagram(diffs, project.Style)
+
+			return writeDiagram(out, command, func() string {
+				return internal.FormatDiagram(d)
+			}, func() string {
+				return internal.FormatSVG(d)
+			})
+		},
+	}
+	return command
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func docs() *Command {
+	var command *Command
+	command = &Command{
+		Name:        "docs",
+		Description: "Generate Markdown documentation for every package",
+		DefaultArg:  ".",
+		Flags: map[string]*Flag{
+			"output":     {"o", "docs", "directory to write the Markdown files to"},
+			"unexported": {"u", false, "include unexported types, fields and functions"},
+			"check":      {"", false, "fail if the files in the output directory are out of date instead of writing them"},
+		},
+		Run: func(out io.Writer, args []string, project *Project) error {
+			var target string
+			var module string
+			var err error
+			var directories map[string]*internal.Directory
+
+			output := command.Flags["output"].Value.(string)
+			unexported := command.Flags["unexported"].Value.(bool)
+			check := command.Flags["check"].Value.(bool)
+
+			target, err = filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+
+			module, err = getModule(target)
+			if err != nil {
+				return err
+			}
+
+			requires, err := getRequires(target)
+			if err != nil {
+				return err
+			}
+
+			directories, err = loadDirectories(target, module, project)
+			if err != nil {
+				return err
+			}
+
+			config := &internal.Config{
+				Requires: requires,
+			}
+
+			for _, directory := range directories {
+				internal.ParsePackage(directory, module, target, config)
+			}
+
+			pages := internal.GenerateDocs(directories, module, config, &internal.DocsOptions{Unexported: unexported})
+
+			extra, err := extraDocs(output, pages)
+			if err != nil {
+				return err
+			}
+
+			if check {
+				var stale []string
+				for _, p := range pages {
+					content, err := os.ReadFile(filepath.Join(output, filepath.FromSlash(p.Name)))
+					if err != nil || string(content) != p.Content {
+						stale = append(stale, p.Name)
+					}
+				}
+				stale = append(stale, extra...)
+				if len(stale) > 0 {
+					return fmt.Errorf("documentation in %s is out of date: %s", output, strings.Join(stale, ", "))
+				}
+				return nil
+			}
+
+			for _, p := range pages {
+				name := filepath.Join(output, filepath.FromSlash(p.Name))
+				err = os.MkdirAll(filepath.Dir(name), 0o755)
+				if err != nil {
+					return err
+				}
+				err = os.WriteFile(name, []byte(p.Content), 0o644)
+				if err != nil {
+					return err
+				}
+			}
+
+			for _, name := range extra {
+				err = os.Remove(filepath.Join(output, filepath.FromSlash(name)))
+				if err != nil {
+					return err
+				}
+			}
+
+			fmt.Fprintf(out, "wrote %d files to %s\n", len(pages), output)
+			if len(extra) > 0 {
+				fmt.Fprintf(out, "removed %d stale files: %s\n", len(extra), strings.Join(extra, ", "))
+			}
+
+			return nil
+		},
+	}
+	return command
+}
+
+func extraDocs(output string, pages []*internal.DocsPage) ([]string, error) {
+	names := map[string]struct{}{}
+	for _, p := range pages {
+		names[p.Name] = struct{}{}
+	}
+
+	var extra []string
+	err := filepath.WalkDir(output, func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && p == output {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(p) != ".md" {
+			return nil
+		}
+		rel, err := filepath.Rel(output, p)
+		if err != nil {
+			return err
+		}
+		if _, ok := names[filepath.ToSlash(rel)]; !ok {
+			extra = append(extra, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return extra, nil
+}
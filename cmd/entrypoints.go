@@ -2,23 +2,36 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
 
 	"github.com/slavsan/godiss/internal"
 )
 
 func entrypoints() *Command {
-	command := &Command{
+	var command *Command
+	command = &Command{
 		Name:        "entrypoints",
 		Description: "Display entrypoints",
 		Subcommands: map[string]*Command{},
 		DefaultArg:  ".",
-		Run: func(args []string) error {
+		Flags: map[string]*Flag{
+			"positions": {"p", false, "prefix output lines with source positions"},
+			"deps":      {"", false, "list transitive dependencies of each binary"},
+			"dot":       {"", false, "render dependencies of each binary as a DOT graph"},
+			"binary":    {"b", "", "only show the given binary"},
+		},
+		Run: func(out io.Writer, args []string, project *Project) error {
 			var target string
 			var module string
 			var err error
 			var directories map[string]*internal.Directory
 
+			positions := command.Flags["positions"].Value.(bool)
+			deps := command.Flags["deps"].Value.(bool)
+			dot := command.Flags["dot"].Value.(bool)
+			binary := command.Flags["binary"].Value.(string)
+
 			target, err = filepath.Abs(args[0])
 			if err != nil {
 				return err
@@ -29,16 +42,39 @@ func entrypoints() *Command {
 				return err
 			}
 
-			directories, err = internal.LoadPackages(target, module, target)
+			directories, err = loadDirectories(target, module, project)
+			if err != nil {
+				return err
+			}
+
+			requires, err := getRequires(target)
 			if err != nil {
 				return err
 			}
 
+			config := &internal.Config{
+				IncludeTests: true,
+				Requires:     requires,
+			}
+
+			opts := &internal.DependenciesOptions{
+				Binary:    binary,
+				Style:     project.Style,
+				Positions: positions,
+			}
+
 			for _, directory := range directories {
-				internal.ParsePackage(directory, module, target, &internal.Config{})
+				internal.ParsePackage(directory, module, target, config)
 			}
 
-			fmt.Printf("%s", internal.FormatEntrypoints(directories, module))
+			switch {
+			case dot:
+				fmt.Fprintf(out, "%s", internal.FormatDependenciesDot(directories, module, config, opts))
+			case deps:
+				fmt.Fprintf(out, "%s", internal.FormatDependencies(directories, module, config, opts))
+			default:
+				fmt.Fprintf(out, "%s", internal.FormatEntrypoints(directories, module, config, &internal.EntrypointsOptions{Positions: positions}))
+			}
 
 			return nil
 		},
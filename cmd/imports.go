@@ -2,17 +2,33 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
 
 	"github.com/slavsan/godiss/internal"
 )
 
 func imports() *Command {
-	return &Command{
+	var command *Command
+	command = &Command{
 		Name:        "imports",
 		Description: "Display imports",
 		DefaultArg:  ".",
-		Run: func(args []string) error {
+		Flags: map[string]*Flag{
+			"depth":    {"d", 0, "collapse module packages to the given path depth"},
+			"cluster":  {"c", false, "group nodes into clusters following the directory hierarchy"},
+			"weights":  {"w", false, "label edges with the number of importing packages and files"},
+			"stdlib":   {"l", false, "include stdlib packages"},
+			"format":   {"f", "dot", "output format: dot or svg"},
+			"output":   {"o", "", "render the diagram to a .svg, .png or .pdf file using Graphviz"},
+			"dot-path": {"", "dot", "path to the Graphviz dot executable"},
+		},
+		Run: func(out io.Writer, args []string, project *Project) error {
+			format := command.Flags["format"].Value.(string)
+			if format != "dot" && format != "svg" {
+				return fmt.Errorf("unsupported format: %s", format)
+			}
+
 			var target string
 			var module string
 			var err error
@@ -28,7 +44,12 @@ func imports() *Command {
 				return err
 			}
 
-			directories, err = internal.LoadPackages(target, module, target)
+			requires, err := getRequires(target)
+			if err != nil {
+				return err
+			}
+
+			directories, err = loadDirectories(target, module, project)
 			if err != nil {
 				return err
 			}
@@ -37,9 +58,24 @@ func imports() *Command {
 				internal.ParsePackage(directory, module, target, &internal.Config{})
 			}
 
-			fmt.Printf("%s\n", internal.FormatImports(directories))
+			config := &internal.Config{
+				Requires:      requires,
+				ExcludeStdLib: !command.Flags["stdlib"].Value.(bool),
+			}
+
+			opts := &internal.ImportsOptions{
+				Depth:   command.Flags["depth"].Value.(int),
+				Cluster: command.Flags["cluster"].Value.(bool),
+				Weights: command.Flags["weights"].Value.(bool),
+				Style:   project.Style,
+			}
 
-			return nil
+			return writeDiagram(out, command, func() string {
+				return internal.FormatImports(directories, module, config, opts)
+			}, func() string {
+				return internal.FormatImportsSVG(directories, module, config, opts)
+			})
 		},
 	}
+	return command
 }
@@ -2,8 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
-	"strings"
 
 	"github.com/slavsan/godiss/internal"
 )
@@ -15,17 +15,19 @@ func imports_table() *Command {
 		Description: "Display imports (in a table)",
 		DefaultArg:  ".",
 		Flags: map[string]*Flag{
-			"nostdlib": {"n", false, "exclude stdlib packages"},
-			"select":   {"s", "", "select packages"},
+			"nostdlib":  {"", false, "exclude stdlib packages"},
+			"select":    {"s", []string{}, "select packages"},
+			"positions": {"p", false, "prefix output lines with source positions"},
 		},
-		Run: func(args []string) error {
+		Run: func(out io.Writer, args []string, project *Project) error {
 			var target string
 			var module string
 			var err error
 			var directories map[string]*internal.Directory
 
 			excludeStdLib := command.Flags["nostdlib"].Value.(bool)
-			selected := command.Flags["select"].Value.(string)
+			selected := command.Flags["select"].Value.([]string)
+			positions := command.Flags["positions"].Value.(bool)
 
 			target, err = filepath.Abs(args[0])
 			if err != nil {
@@ -37,7 +39,7 @@ func imports_table() *Command {
 				return err
 			}
 
-			directories, err = internal.LoadPackages(target, module, target)
+			directories, err = loadDirectories(target, module, project)
 			if err != nil {
 				return err
 			}
@@ -51,7 +53,7 @@ func imports_table() *Command {
 				internal.ParsePackage(directory, module, target, config)
 			}
 
-			fmt.Printf("%s", internal.FormatImportsTable(directories, module, config))
+			fmt.Fprintf(out, "%s", internal.FormatImportsTable(directories, module, config, &internal.ImportsTableOptions{Positions: positions}))
 
 			return nil
 		},
@@ -59,8 +61,7 @@ func imports_table() *Command {
 	return command
 }
 
-func createSet(value string) map[string]struct{} {
-	items := strings.Split(value, ",")
+func createSet(items []string) map[string]struct{} {
 	set := make(map[string]struct{}, len(items))
 	for _, i := range items {
 		if i == "" {
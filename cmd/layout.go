@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func layout() *Command {
+	var command *Command
+	command = &Command{
+		Name:        "layout",
+		Description: "Display struct sizes, field offsets and padding",
+		DefaultArg:  ".",
+		Flags: map[string]*Flag{
+			"os":        {"", runtime.GOOS, "target GOOS used to select files"},
+			"arch":      {"a", runtime.GOARCH, "target GOARCH used to compute sizes"},
+			"wasted":    {"", false, "only display structs which can be made smaller by reordering fields"},
+			"select":    {"s", []string{}, "select packages"},
+			"exclude":   {"e", []string{}, "exclude packages"},
+			"positions": {"p", false, "prefix output lines with source positions"},
+			"tests":     {"t", false, "include test files"},
+		},
+		Run: func(out io.Writer, args []string, project *Project) error {
+			var target string
+			var module string
+			var err error
+			var directories map[string]*internal.Directory
+
+			target, err = filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+
+			module, err = getModule(target)
+			if err != nil {
+				return err
+			}
+
+			directories, err = loadDirectories(target, module, project)
+			if err != nil {
+				return err
+			}
+
+			tests := command.Flags["tests"].Value.(bool)
+
+			for _, directory := range directories {
+				internal.ParsePackage(directory, module, target, &internal.Config{IncludeTests: tests})
+			}
+
+			config := &internal.Config{
+				Select:       createSet(command.Flags["select"].Value.([]string)),
+				Exclude:      createSet(command.Flags["exclude"].Value.([]string)),
+				IncludeTests: tests,
+			}
+
+			layouts, err := internal.FormatLayouts(directories, module, config, &internal.LayoutOptions{
+				OS:        command.Flags["os"].Value.(string),
+				Arch:      command.Flags["arch"].Value.(string),
+				Wasted:    command.Flags["wasted"].Value.(bool),
+				Positions: command.Flags["positions"].Value.(bool),
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "%s", layouts)
+
+			return nil
+		},
+	}
+	return command
+}
@@ -2,17 +2,29 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
 
 	"github.com/slavsan/godiss/internal"
 )
 
 func packages() *Command {
-	return &Command{
+	var command *Command
+	command = &Command{
 		Name:        "packages",
 		Description: "Display packages in a project",
 		DefaultArg:  ".",
-		Run: func(args []string) error {
+		Flags: map[string]*Flag{
+			"format":   {"f", "dot", "output format: dot or svg"},
+			"output":   {"o", "", "render the diagram to a .svg, .png or .pdf file using Graphviz"},
+			"dot-path": {"", "dot", "path to the Graphviz dot executable"},
+		},
+		Run: func(out io.Writer, args []string, project *Project) error {
+			format := command.Flags["format"].Value.(string)
+			if format != "dot" && format != "svg" {
+				return fmt.Errorf("unsupported format: %s", format)
+			}
+
 			var target string
 			var module string
 			var err error
@@ -28,7 +40,7 @@ func packages() *Command {
 				return err
 			}
 
-			directories, err = internal.LoadPackages(target, module, target)
+			directories, err = loadDirectories(target, module, project)
 			if err != nil {
 				return err
 			}
@@ -37,9 +49,12 @@ func packages() *Command {
 				internal.ParsePackage(directory, module, target, &internal.Config{})
 			}
 
-			fmt.Println(internal.FormatPackages(directories))
-
-			return nil
+			return writeDiagram(out, command, func() string {
+				return internal.FormatPackages(directories, project.Style)
+			}, func() string {
+				return internal.FormatPackagesSVG(directories, project.Style)
+			})
 		},
 	}
+	return command
 }
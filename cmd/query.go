@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func query() *Command {
+	var command *Command
+	command = &Command{
+		Name:        "query",
+		Description: "Run a query over packages, files, structs, fields, methods, functions, interfaces and imports",
+		DefaultArg:  ".",
+		Flags: map[string]*Flag{
+			"query":  {"q", "", "query to run, e.g. 'structs where exported and fields > 15 select package, name'"},
+			"format": {"f", "table", "output format: table or json"},
+			"tests":  {"t", false, "include test packages"},
+		},
+		Run: func(out io.Writer, args []string, project *Project) error {
+			var target string
+			var module string
+			var err error
+			var directories map[string]*internal.Directory
+
+			src := command.Flags["query"].Value.(string)
+			format := command.Flags["format"].Value.(string)
+			includeTests := command.Flags["tests"].Value.(bool)
+
+			if src == "" {
+				return fmt.Errorf("missing query, pass one with --query")
+			}
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format: %s", format)
+			}
+
+			q, err := internal.ParseQuery(src)
+			if err != nil {
+				return fmt.Errorf("invalid query: %w", err)
+			}
+
+			target, err = filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+
+			module, err = getModule(target)
+			if err != nil {
+				return err
+			}
+
+			directories, err = loadDirectories(target, module, project)
+			if err != nil {
+				return err
+			}
+
+			config := &internal.Config{
+				IncludeTests: includeTests,
+			}
+
+			for _, directory := range directories {
+				internal.ParsePackage(directory, module, target, config)
+			}
+
+			res := internal.RunQuery(directories, q)
+
+			if format == "json" {
+				data, err := internal.FormatQueryJSON(res)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "%s", data)
+				return nil
+			}
+
+			fmt.Fprintf(out, "%s", internal.FormatQueryTable(res))
+
+			return nil
+		},
+	}
+	return command
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func report() *Command {
+	var command *Command
+	command = &Command{
+		Name:        "report",
+		Description: "Generate a self-contained HTML report",
+		DefaultArg:  ".",
+		Flags: map[string]*Flag{
+			"output": {"o", "report.html", "file to write the report to"},
+			"tests":  {"t", false, "include test packages"},
+		},
+		Run: func(out io.Writer, args []string, project *Project) error {
+			var target string
+			var module string
+			var err error
+			var directories map[string]*internal.Directory
+
+			output := command.Flags["output"].Value.(string)
+			includeTests := command.Flags["tests"].Value.(bool)
+
+			target, err = filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+
+			module, err = getModule(target)
+			if err != nil {
+				return err
+			}
+
+			requires, err := getRequires(target)
+			if err != nil {
+				return err
+			}
+
+			directories, err = loadDirectories(target, module, project)
+			if err != nil {
+				return err
+			}
+
+			config := &internal.Config{
+				Requires:     requires,
+				IncludeTests: includeTests,
+			}
+
+			for _, directory := range directories {
+				internal.ParsePackage(directory, module, target, config)
+			}
+
+			res, err := internal.FormatReport(directories, module, config)
+			if err != nil {
+				return err
+			}
+
+			err = os.WriteFile(output, []byte(res), 0o644)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "wrote report to %s\n", output)
+
+			return nil
+		},
+	}
+	return command
+}
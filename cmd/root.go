@@ -1,15 +1,18 @@
 package cmd
 
 import (
-	"bytes"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
-	"path"
-	"reflect"
+	"path/filepath"
 	"sort"
 	"strings"
+	"time"
+
+	"github.com/slavsan/godiss/internal"
 )
 
 type Command struct {
@@ -18,7 +21,11 @@ type Command struct {
 	Subcommands map[string]*Command
 	Flags       map[string]*Flag
 	DefaultArg  string
-	Run         func(args []string) error
+	Args        string
+	PathArg     int
+	ValidArgs   []string
+	Hidden      bool
+	Run         func(out io.Writer, args []string, project *Project) error
 }
 
 type Flag struct {
@@ -34,17 +41,46 @@ func (c *Command) Add(sub *Command) {
 	c.Subcommands[sub.Name] = sub
 }
 
+type listValue struct {
+	items *[]string
+	set   bool
+}
+
+func (l *listValue) String() string {
+	if l.items == nil {
+		return ""
+	}
+	return strings.Join(*l.items, ",")
+}
+
+func (l *listValue) Set(value string) error {
+	if !l.set {
+		*l.items = nil
+		l.set = true
+	}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l.items = append(*l.items, item)
+		}
+	}
+	return nil
+}
+
 type Executor struct {
-	Arg int
+	Args   []string
+	Stdout io.Writer
+	Stderr io.Writer
 }
 
-func NewExecutor() *Executor {
+func NewExecutor(args []string, stdout, stderr io.Writer) *Executor {
 	return &Executor{
-		Arg: 0,
+		Args:   args,
+		Stdout: stdout,
+		Stderr: stderr,
 	}
 }
 
-func (e *Executor) Usage(c *Command) {
+func (e *Executor) Usage(w io.Writer, c *Command) {
 	if c.Subcommands == nil {
 		return
 	}
@@ -53,6 +89,10 @@ func (e *Executor) Usage(c *Command) {
 	max := 0
 
 	for _, sub := range c.Subcommands {
+		if sub.Hidden {
+			continue
+		}
+
 		if len(sub.Name) > max {
 			max = len(sub.Name)
 		}
@@ -62,115 +102,291 @@ func (e *Executor) Usage(c *Command) {
 
 	sort.Strings(sorted)
 
-	fmt.Printf("Subcommands:\n")
+	fmt.Fprintf(w, "Subcommands:\n")
 	for _, sub := range sorted {
 		command := c.Subcommands[sub]
-		fmt.Printf("\t%-*s\t%s\n", max, command.Name, command.Description)
+		fmt.Fprintf(w, "\t%-*s\t%s\n", max, command.Name, command.Description)
 	}
 }
 
-func showHelp(c *Command) {
+func flagType(v any) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case int:
+		return "int"
+	case time.Duration:
+		return "duration"
+	case []string:
+		return "list"
+	default:
+		return ""
+	}
+}
+
+func flagDefault(v any) string {
+	switch d := v.(type) {
+	case bool:
+		if d {
+			return "true"
+		}
+	case string:
+		if d != "" {
+			return fmt.Sprintf("%q", d)
+		}
+	case int:
+		if d != 0 {
+			return fmt.Sprintf("%d", d)
+		}
+	case time.Duration:
+		if d != 0 {
+			return d.String()
+		}
+	case []string:
+		if len(d) > 0 {
+			return strings.Join(d, ",")
+		}
+	}
+	return ""
+}
+
+func showHelp(w io.Writer, c *Command) {
+	arg := ""
+	if c.Args != "" {
+		arg = fmt.Sprintf(" %s", c.Args)
+	} else if len(c.ValidArgs) > 0 {
+		arg = fmt.Sprintf(" <%s>", strings.Join(c.ValidArgs, "|"))
+	} else if c.DefaultArg != "" {
+		arg = fmt.Sprintf(" [path (default %q)]", c.DefaultArg)
+	} else if c.Subcommands == nil {
+		arg = " <path>"
+	}
+
+	fmt.Fprintf(w, "Usage: godiss %s [flags]%s\n", c.Name, arg)
+	if c.Description != "" {
+		fmt.Fprintf(w, "\n%s\n", c.Description)
+	}
+
+	type line struct {
+		name  string
+		usage string
+	}
+
+	lines := []line{{"-h, --help", "show help for this command"}}
+	for _, k := range sortedKeys(c.Flags) {
+		f := c.Flags[k]
+		name := fmt.Sprintf("-%s, --%s", f.Short, k)
+		if f.Short == "" {
+			name = fmt.Sprintf("    --%s", k)
+		}
+		if t := flagType(f.Value); t != "" {
+			name = fmt.Sprintf("%s %s", name, t)
+		}
+		usage := f.Usage
+		if d := flagDefault(f.Value); d != "" {
+			usage = fmt.Sprintf("%s (default %s)", usage, d)
+		}
+		lines = append(lines, line{name, usage})
+	}
+	lines = append(lines, line{"    --preset string", "apply a named preset from the project configuration"})
+
 	max := 0
-	for k := range c.Flags {
-		if len(k) > max {
-			max = len(k)
+	for _, l := range lines {
+		if len(l.name) > max {
+			max = len(l.name)
 		}
 	}
 
-	fmt.Printf("Usage:\n")
-	for k, f := range c.Flags {
-		fmt.Printf(
-			"\t-%s, --%s%s  %s\n",
-			f.Short, k, strings.Repeat(" ", max-len(k)), f.Usage,
-		)
+	fmt.Fprintf(w, "\nFlags:\n")
+	for _, l := range lines {
+		fmt.Fprintf(w, "\t%-*s  %s\n", max, l.name, l.usage)
 	}
 }
 
-func (e *Executor) Execute(c *Command) {
-	e.Arg++
+func (e *Executor) Execute(c *Command) error {
+	return e.execute(c, e.Args)
+}
+
+func (e *Executor) execute(c *Command, args []string) error {
 	if len(c.Subcommands) > 0 {
 		var arg string
-		if len(os.Args) > e.Arg {
-			arg = os.Args[e.Arg]
+		if len(args) > 0 {
+			arg = args[0]
 		}
 
-		if arg == "help" {
-			e.Arg++
-			if len(os.Args) > e.Arg {
-				arg = os.Args[e.Arg]
+		if arg == "help" || arg == "-h" || arg == "--help" {
+			if len(args) < 2 {
+				e.Usage(e.Stdout, c)
+				return nil
 			}
 
-			if command, ok := c.Subcommands[arg]; ok {
-				showHelp(command)
-				return
+			if command, ok := c.Subcommands[args[1]]; ok {
+				showHelp(e.Stdout, command)
+				return nil
 			}
 
-			fmt.Printf("unknown subcommand: %s\n", arg)
-			os.Exit(1)
+			return e.fail(fmt.Errorf("unknown subcommand: %s", args[1]))
 		}
 
 		if command, ok := c.Subcommands[arg]; ok {
-			e.Execute(command)
-			return
+			return e.execute(command, args[1:])
 		}
 
-		e.Usage(c)
-		return
+		if arg != "" {
+			e.Usage(e.Stderr, c)
+			return e.fail(fmt.Errorf("unknown subcommand: %s", arg))
+		}
+
+		e.Usage(e.Stdout, c)
+		return nil
+	}
+
+	values, positional, preset, explicit, err := parseFlags(c, args)
+	if errors.Is(err, flag.ErrHelp) {
+		showHelp(e.Stdout, c)
+		return nil
+	}
+	if err != nil {
+		fmt.Fprintf(e.Stderr, "%s\n\n", err.Error())
+		showHelp(e.Stderr, c)
+		return err
+	}
+
+	for k, v := range values {
+		c.Flags[k].Value = v
+	}
+
+	if len(positional) == 0 {
+		if c.DefaultArg == "" {
+			showHelp(e.Stderr, c)
+			return e.fail(fmt.Errorf("missing argument for %s", c.Name))
+		}
+		positional = append(positional, c.DefaultArg)
+	}
+
+	path := "."
+	if c.PathArg < len(positional) {
+		path = positional[c.PathArg]
+	}
+
+	project, err := loadProject(path)
+	if err != nil {
+		return e.fail(err)
+	}
+
+	err = project.Apply(c, preset, explicit)
+	if err != nil {
+		return e.fail(err)
 	}
 
-	flagSets := map[string]any{}
+	err = c.Run(e.Stdout, positional, project)
+	if err != nil {
+		return e.fail(err)
+	}
+
+	return nil
+}
+
+func (e *Executor) fail(err error) error {
+	fmt.Fprintf(e.Stderr, "%s\n", err.Error())
+	return err
+}
+
+func parseFlags(c *Command, args []string) (map[string]any, []string, string, map[string]struct{}, error) {
 	flagSet := flag.NewFlagSet(c.Name, flag.ContinueOnError)
+	flagSet.SetOutput(ioutil.Discard)
+	flagSet.Usage = func() {}
+
+	values := map[string]func() any{}
 
 	for k, f := range c.Flags {
+		names := []string{k}
+		if f.Short != "" {
+			names = append(names, f.Short)
+		}
+
 		switch v := f.Value.(type) {
 		case bool:
-			flagSets[k] = flagSet.Bool(k, v, f.Usage)
-			flagSets[k] = flagSet.Bool(f.Short, v, f.Usage)
+			p := new(bool)
+			*p = v
+			for _, name := range names {
+				flagSet.BoolVar(p, name, v, f.Usage)
+			}
+			values[k] = func() any { return *p }
 		case string:
-			flagSets[k] = flagSet.String(k, v, f.Usage)
-			flagSets[k] = flagSet.String(f.Short, v, f.Usage)
+			p := new(string)
+			*p = v
+			for _, name := range names {
+				flagSet.StringVar(p, name, v, f.Usage)
+			}
+			values[k] = func() any { return *p }
+		case int:
+			p := new(int)
+			*p = v
+			for _, name := range names {
+				flagSet.IntVar(p, name, v, f.Usage)
+			}
+			values[k] = func() any { return *p }
+		case time.Duration:
+			p := new(time.Duration)
+			*p = v
+			for _, name := range names {
+				flagSet.DurationVar(p, name, v, f.Usage)
+			}
+			values[k] = func() any { return *p }
+		case []string:
+			items := append([]string{}, v...)
+			l := &listValue{items: &items}
+			for _, name := range names {
+				flagSet.Var(l, name, f.Usage)
+			}
+			values[k] = func() any { return *l.items }
 		default:
-			panic(fmt.Sprintf("unhandled type: %#v - (%#v)", reflect.TypeOf(v), f))
+			panic(fmt.Sprintf("unhandled flag type for %s: %T", k, v))
 		}
 	}
 
-	flagSet.SetOutput(&bytes.Buffer{})
-
-	err := flagSet.Parse(os.Args[e.Arg:])
-	if err != nil {
-		fmt.Printf("%s\n\n", err.Error())
+	preset := flagSet.String("preset", "", "apply a named preset from the project configuration")
 
-		showHelp(c)
-		os.Exit(1)
-	}
+	var positional []string
+	rest := args
 
-	for k, v := range flagSets {
-		switch f := v.(type) {
-		case *string:
-			(*c).Flags[k].Value = *f
-		case *bool:
-			(*c).Flags[k].Value = *f
-		default:
-			panic(fmt.Sprintf("unhandled type: %#v\n", f))
+	for {
+		err := flagSet.Parse(rest)
+		if err != nil {
+			return nil, nil, "", nil, err
 		}
-	}
 
-	args := flagSet.Args()
+		remaining := flagSet.Args()
+		if len(remaining) == 0 {
+			break
+		}
 
-	if len(args) == 0 {
-		if c.DefaultArg == "" {
-			fmt.Printf("emtpy args\n")
-			e.Usage(c)
-			os.Exit(1)
+		consumed := len(rest) - len(remaining)
+		if consumed > 0 && rest[consumed-1] == "--" {
+			positional = append(positional, remaining...)
+			break
 		}
-		args = append(args, c.DefaultArg)
+
+		positional = append(positional, remaining[0])
+		rest = remaining[1:]
 	}
 
-	err = c.Run(args)
-	if err != nil {
-		fmt.Printf("%s\n", err.Error())
-		os.Exit(1)
+	explicit := map[string]struct{}{}
+	flagSet.Visit(func(f *flag.Flag) {
+		for k, v := range c.Flags {
+			if f.Name == k || f.Name == v.Short {
+				explicit[k] = struct{}{}
+			}
+		}
+	})
+
+	res := map[string]any{}
+	for k, value := range values {
+		res[k] = value()
 	}
+
+	return res, positional, *preset, explicit, nil
 }
 
 func root() *Command {
@@ -187,20 +403,74 @@ func root() *Command {
 	command.Add(types())
 	command.Add(entrypoints())
 	command.Add(stats())
+	command.Add(dead())
+	command.Add(coupling())
+	command.Add(complexity())
+	command.Add(tests())
+	command.Add(aliases())
+	command.Add(names())
+	command.Add(layout())
+	command.Add(query())
+	command.Add(docs())
+	command.Add(report())
+	command.Add(serve())
+	command.Add(diff())
+	command.Add(config())
+	command.Add(completion())
+	command.Add(complete())
 
 	return command
 }
 
-func Execute() {
-	NewExecutor().Execute(root())
+func Execute() error {
+	return NewExecutor(os.Args[1:], os.Stdout, os.Stderr).Execute(root())
 }
 
 func getModule(target string) (string, error) {
-	bytes, err := ioutil.ReadFile(path.Join(target, "go.mod"))
+	mod, err := internal.ReadGoMod(target)
 	if err != nil {
 		return "", err
 	}
 
-	lines := strings.Split(string(bytes), "\n")
-	return strings.ReplaceAll(lines[0], "module ", ""), nil
+	return mod.Module, nil
+}
+
+func getRequires(target string) ([]string, error) {
+	mod, err := internal.ReadGoMod(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return mod.Requires, nil
+}
+
+func writeDiagram(out io.Writer, command *Command, dot, svg func() string) error {
+	format := command.Flags["format"].Value.(string)
+	output := command.Flags["output"].Value.(string)
+
+	if output == "" {
+		if format == "svg" {
+			fmt.Fprint(out, svg())
+			return nil
+		}
+		fmt.Fprintln(out, dot())
+		return nil
+	}
+
+	if format == "svg" {
+		if strings.ToLower(filepath.Ext(output)) != ".svg" {
+			return fmt.Errorf("the built-in renderer only writes .svg files, got %s", output)
+		}
+		if err := os.WriteFile(output, []byte(svg()), 0o644); err != nil {
+			return err
+		}
+	} else {
+		if err := internal.RenderGraphviz(command.Flags["dot-path"].Value.(string), dot(), output); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(out, "wrote diagram to %s\n", output)
+
+	return nil
 }
@@ -0,0 +1,428 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func assertEqual(t *testing.T, expected, actual any, msg ...string) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, actual) {
+		message := ""
+		if len(msg) > 0 {
+			message = fmt.Sprintf("\t message: %s", strings.Join(msg, "\n"))
+		}
+		t.Errorf(
+			"equality assertion failed:\n\texpected: %#v (%s)\n\t  actual: %#v (%s)\n%s",
+			expected, reflect.TypeOf(expected),
+			actual, reflect.TypeOf(actual),
+			message,
+		)
+	}
+}
+
+func execute(args ...string) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	err := NewExecutor(args, &stdout, &stderr).Execute(root())
+	return stdout.String(), stderr.String(), err
+}
+
+func testCommand(run func(out io.Writer, args []string, project *Project) error) *Command {
+	return &Command{
+		Name:        "test",
+		Description: "Test command",
+		DefaultArg:  ".",
+		Flags: map[string]*Flag{
+			"verbose": {"v", false, "verbose output"},
+			"name":    {"n", "default", "a name"},
+			"count":   {"c", 3, "a count"},
+			"wait":    {"w", time.Second, "a duration"},
+			"tags":    {"t", []string{}, "a list"},
+		},
+		Run: run,
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	c := testCommand(nil)
+
+	values, args, preset, explicit, err := parseFlags(c, []string{
+		"--name", "long", "./a", "-v", "-c", "7", "--wait=2m", "-t", "x,y", "./b", "--tags", "z", "--", "-notaflag",
+	})
+	assertEqual(t, nil, err)
+	assertEqual(t, map[string]any{
+		"verbose": true,
+		"name":    "long",
+		"count":   7,
+		"wait":    2 * time.Minute,
+		"tags":    []string{"x", "y", "z"},
+	}, values)
+	assertEqual(t, []string{"./a", "./b", "-notaflag"}, args)
+	assertEqual(t, "", preset)
+	assertEqual(t, map[string]struct{}{"verbose": {}, "name": {}, "count": {}, "wait": {}, "tags": {}}, explicit)
+}
+
+func TestParseFlagsDefaults(t *testing.T) {
+	values, args, preset, explicit, err := parseFlags(testCommand(nil), []string{"-n", "short", "--preset", "core"})
+	assertEqual(t, nil, err)
+	assertEqual(t, map[string]any{
+		"verbose": false,
+		"name":    "short",
+		"count":   3,
+		"wait":    time.Second,
+		"tags":    []string{},
+	}, values)
+	assertEqual(t, []string(nil), args)
+	assertEqual(t, "core", preset)
+	assertEqual(t, map[string]struct{}{"name": {}}, explicit)
+}
+
+func TestExecuteRunsCommand(t *testing.T) {
+	var received []string
+	c := testCommand(func(out io.Writer, args []string, project *Project) error {
+		received = args
+		fmt.Fprintf(out, "count=%d\n", 0)
+		return nil
+	})
+
+	var stdout, stderr bytes.Buffer
+	err := NewExecutor([]string{"--count", "5"}, &stdout, &stderr).Execute(c)
+	assertEqual(t, nil, err)
+	assertEqual(t, []string{"."}, received)
+	assertEqual(t, 5, c.Flags["count"].Value)
+	assertEqual(t, "count=0\n", stdout.String())
+	assertEqual(t, "", stderr.String())
+}
+
+func TestExecuteReturnsErrors(t *testing.T) {
+	c := testCommand(func(out io.Writer, args []string, project *Project) error {
+		return fmt.Errorf("failed on %s", args[0])
+	})
+
+	var stdout, stderr bytes.Buffer
+	err := NewExecutor([]string{"target"}, &stdout, &stderr).Execute(c)
+	assertEqual(t, "failed on target", err.Error())
+	assertEqual(t, "", stdout.String())
+	assertEqual(t, "failed on target\n", stderr.String())
+}
+
+func TestShowHelp(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := NewExecutor([]string{"-h"}, &stdout, &stderr).Execute(testCommand(nil))
+	assertEqual(t, nil, err)
+	assertEqual(t, ""+
+		"Usage: godiss test [flags] [path (default \".\")]\n"+
+		"\n"+
+		"Test command\n"+
+		"\n"+
+		"Flags:\n"+
+		"\t-h, --help           show help for this command\n"+
+		"\t-c, --count int      a count (default 3)\n"+
+		"\t-n, --name string    a name (default \"default\")\n"+
+		"\t-t, --tags list      a list\n"+
+		"\t-v, --verbose        verbose output\n"+
+		"\t-w, --wait duration  a duration (default 1s)\n"+
+		"\t    --preset string  apply a named preset from the project configuration\n",
+		stdout.String())
+	assertEqual(t, "", stderr.String())
+}
+
+func TestHelpForEveryCommand(t *testing.T) {
+	for name := range root().Subcommands {
+		stdout, stderr, err := execute(name, "--help")
+		assertEqual(t, nil, err, name)
+		assertEqual(t, true, strings.HasPrefix(stdout, fmt.Sprintf("Usage: godiss %s ", name)), name)
+		assertEqual(t, "", stderr, name)
+	}
+}
+
+func TestFlagShortsAreConsistent(t *testing.T) {
+	shorts := map[string]string{}
+	for name, command := range root().Subcommands {
+		for long, flag := range command.Flags {
+			if flag.Short == "" {
+				continue
+			}
+			if other, ok := shorts[flag.Short]; ok && other != long {
+				t.Errorf("-%s is --%s in %s but --%s elsewhere", flag.Short, long, name, other)
+			}
+			shorts[flag.Short] = long
+		}
+	}
+}
+
+func TestExecuteUnknownFlag(t *testing.T) {
+	stdout, stderr, err := execute("complexity", "--bogus")
+	assertEqual(t, "flag provided but not defined: -bogus", err.Error())
+	assertEqual(t, "", stdout)
+	assertEqual(t, true, strings.HasPrefix(stderr, "flag provided but not defined: -bogus\n\nUsage: godiss complexity"))
+}
+
+func TestExecuteUnknownSubcommand(t *testing.T) {
+	stdout, stderr, err := execute("bogus")
+	assertEqual(t, "unknown subcommand: bogus", err.Error())
+	assertEqual(t, "", stdout)
+	assertEqual(t, true, strings.HasSuffix(stderr, "unknown subcommand: bogus\n"))
+}
+
+func TestExecuteProjectDefaults(t *testing.T) {
+	stdout, _, err := execute("tests", "testdata/project")
+	assertEqual(t, nil, err)
+	assertEqual(t, true, strings.Contains(stdout, "packages without tests:"))
+	assertEqual(t, false, strings.Contains(stdout, "    test "))
+
+	stdout, _, err = execute("complexity", "testdata/project", "--sort", "lines")
+	assertEqual(t, nil, err)
+	assertEqual(t, 2, strings.Count(stdout, "\n"))
+
+	stdout, _, err = execute("complexity", "--preset", "api", "testdata/project", "-n", "5")
+	assertEqual(t, nil, err)
+	assertEqual(t, 2, strings.Count(stdout, "\n"))
+
+	_, stderr, err := execute("complexity", "--preset", "missing", "testdata/project")
+	assertEqual(t, "unknown preset: missing", err.Error())
+	assertEqual(t, "unknown preset: missing\n", stderr)
+}
+
+func TestConfigCommand(t *testing.T) {
+	path, err := filepath.Abs("testdata/project/.godiss.yaml")
+	assertEqual(t, nil, err)
+
+	stdout, _, err := execute("config", "--command", "complexity", "testdata/project")
+	assertEqual(t, nil, err)
+	assertEqual(t, ""+
+		"# "+path+"\n"+
+		"style:\n"+
+		"  rankdir: TB\n"+
+		"presets:\n"+
+		"  api:\n"+
+		"    select: [api]\n"+
+		"commands:\n"+
+		"  complexity:\n"+
+		"    exclude: []\n"+
+		"    positions: false\n"+
+		"    select: []\n"+
+		"    sort: cognitive\n"+
+		"    top: 1\n",
+		stdout)
+}
+
+func TestQueryCommand(t *testing.T) {
+	stdout, _, err := execute("query", "testdata/project", "-q", `functions where name = "Upper" select package, name, cyclomatic`)
+	assertEqual(t, nil, err)
+	assertEqual(t, ""+
+		"package                 | name  | cyclomatic\n"+
+		"example.com/project/api | Upper |          2\n",
+		stdout)
+
+	stdout, _, err = execute("query", "testdata/project", "-f", "json", "-q", "packages count")
+	assertEqual(t, nil, err)
+	assertEqual(t, "[\n  {\n    \"count\": 2\n  }\n]\n", stdout)
+
+	_, stderr, err := execute("query", "testdata/project", "-q", "widgets")
+	assertEqual(t, true, err != nil)
+	assertEqual(t, true, strings.HasPrefix(stderr, "invalid query: unknown entity \"widgets\""))
+}
+
+func TestStatsComplexity(t *testing.T) {
+	stdout, _, err := execute("stats", "testdata/project", "--complexity")
+	assertEqual(t, nil, err)
+	assertEqual(t, true, strings.Contains(stdout, " | packages count\n"))
+	assertEqual(t, true, strings.Contains(stdout, "\npackage                 | funcs |"))
+	assertEqual(t, true, strings.Contains(stdout, "\nexample.com/project/api |     1 |"))
+
+	_, stderr, err := execute("stats", "testdata/project", "--complexity", "--table")
+	assertEqual(t, true, err != nil)
+	assertEqual(t, "--table and --complexity cannot be used together\n", stderr)
+}
+
+func TestTemplateFlag(t *testing.T) {
+	stdout, _, err := execute("types", "testdata/project", "--template", "testdata/templates/packages.tmpl")
+	assertEqual(t, nil, err)
+	assertEqual(t, "example.com/project main.go\nexample.com/project/api api.go\n", stdout)
+
+	_, _, err = execute("stats", "testdata/project", "--template", "testdata/templates/missing.tmpl")
+	assertEqual(t, true, err != nil)
+}
+
+func TestDocsCommand(t *testing.T) {
+	output := t.TempDir()
+
+	_, stderr, err := execute("docs", "testdata/project", "-o", output, "--check")
+	assertEqual(t, true, err != nil)
+	assertEqual(t, fmt.Sprintf("documentation in %s is out of date: README.md, _root.md, api.md\n", output), stderr)
+
+	stdout, _, err := execute("docs", "testdata/project", "-o", output)
+	assertEqual(t, nil, err)
+	assertEqual(t, fmt.Sprintf("wrote 3 files to %s\n", output), stdout)
+
+	index, err := os.ReadFile(filepath.Join(output, "README.md"))
+	assertEqual(t, nil, err)
+	assertEqual(t, true, strings.Contains(string(index), "- [example.com/project](_root.md): `main` main in `main.go`, builds `project`\n"))
+
+	_, _, err = execute("docs", "testdata/project", "-o", output, "--check")
+	assertEqual(t, nil, err)
+
+	err = os.WriteFile(filepath.Join(output, "project.md"), []byte("# example.com/project\n"), 0o644)
+	assertEqual(t, nil, err)
+
+	_, stderr, err = execute("docs", "testdata/project", "-o", output, "--check")
+	assertEqual(t, true, err != nil)
+	assertEqual(t, fmt.Sprintf("documentation in %s is out of date: project.md\n", output), stderr)
+
+	stdout, _, err = execute("docs", "testdata/project", "-o", output)
+	assertEqual(t, nil, err)
+	assertEqual(t, fmt.Sprintf("wrote 3 files to %s\nremoved 1 stale files: project.md\n", output), stdout)
+
+	_, err = os.Stat(filepath.Join(output, "project.md"))
+	assertEqual(t, true, os.IsNotExist(err))
+}
+
+func TestReportCommand(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "report.html")
+
+	stdout, _, err := execute("report", "testdata/project", "-o", output)
+	assertEqual(t, nil, err)
+	assertEqual(t, fmt.Sprintf("wrote report to %s\n", output), stdout)
+
+	content, err := os.ReadFile(output)
+	assertEqual(t, nil, err)
+	assertEqual(t, true, strings.Contains(string(content), `"path":"example.com/project/api"`))
+}
+
+func TestDotStyle(t *testing.T) {
+	for _, args := range [][]string{
+		{"packages", "testdata/project"},
+		{"structs", "testdata/project/api/api.go"},
+	} {
+		stdout, _, err := execute(args...)
+		assertEqual(t, nil, err)
+		assertEqual(t, true, strings.Contains(stdout, "    rankdir=\"TB\"\n"), args[0])
+	}
+}
+
+func TestSVGFormat(t *testing.T) {
+	for _, args := range [][]string{
+		{"packages", "testdata/project", "-f", "svg"},
+		{"imports", "testdata/project", "--format", "svg"},
+		{"structs", "testdata/project/api/api.go", "-f", "svg"},
+	} {
+		stdout, _, err := execute(args...)
+		assertEqual(t, nil, err)
+		assertEqual(t, true, strings.HasPrefix(stdout, "<?xml"), args[0])
+		assertEqual(t, true, strings.HasSuffix(stdout, "</svg>\n"), args[0])
+	}
+
+	_, stderr, err := execute("packages", "testdata/project", "-f", "png")
+	assertEqual(t, true, err != nil)
+	assertEqual(t, "unsupported format: png\n", stderr)
+}
+
+func TestDiagramOutput(t *testing.T) {
+	dir := t.TempDir()
+	dot := filepath.Join(dir, "dot")
+	err := os.WriteFile(dot, []byte("#!/bin/sh\ncat > \"$3\"\n"), 0o755)
+	assertEqual(t, nil, err)
+
+	output := filepath.Join(dir, "imports.pdf")
+	stdout, _, err := execute("imports", "testdata/project", "-o", output, "--dot-path", dot)
+	assertEqual(t, nil, err)
+	assertEqual(t, fmt.Sprintf("wrote diagram to %s\n", output), stdout)
+
+	content, err := os.ReadFile(output)
+	assertEqual(t, nil, err)
+	assertEqual(t, true, strings.HasPrefix(string(content), "digraph {\n"))
+
+	output = filepath.Join(dir, "packages.svg")
+	_, _, err = execute("packages", "testdata/project", "-f", "svg", "-o", output, "--dot-path", filepath.Join(dir, "missing"))
+	assertEqual(t, nil, err)
+	content, err = os.ReadFile(output)
+	assertEqual(t, nil, err)
+	assertEqual(t, true, strings.HasPrefix(string(content), "<?xml"))
+
+	_, stderr, err := execute("packages", "testdata/project", "-o", output, "--dot-path", filepath.Join(dir, "missing"))
+	assertEqual(t, true, err != nil)
+	assertEqual(t, true, strings.Contains(stderr, "not found, install Graphviz"))
+
+	_, stderr, err = execute("structs", "testdata/project/api/api.go", "-f", "svg", "-o", filepath.Join(dir, "api.png"))
+	assertEqual(t, true, err != nil)
+	assertEqual(t, true, strings.HasPrefix(stderr, "the built-in renderer only writes .svg files"))
+}
+
+func TestDiffCommand(t *testing.T) {
+	repo := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		assertEqual(t, nil, err, string(out))
+	}
+	write := func(name, content string) {
+		path := filepath.Join(repo, name)
+		assertEqual(t, nil, os.MkdirAll(filepath.Dir(path), 0o755))
+		assertEqual(t, nil, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	run("init", "-q")
+	write("go.mod", "module example.com/repo\n\ngo 1.19\n")
+	write("store/store.go", "package store\n\ntype DB struct {\n\tname string\n}\n")
+	run("add", "-A")
+	run("commit", "-q", "-m", "first")
+	write("store/store.go", "package store\n\ntype DB struct {\n\tname string\n\tsize int\n}\n")
+	write("README.md", "not go\n")
+	run("add", "-A")
+	run("commit", "-q", "-m", "second")
+
+	stdout, _, err := execute("diff", "HEAD~1", "HEAD", repo)
+	assertEqual(t, nil, err)
+	assertEqual(t, ""+
+		internal.Yellow+"~ package example.com/repo/store"+internal.NoColor+"\n"+
+		"    "+internal.Yellow+"~ struct DB"+internal.NoColor+"\n"+
+		"        "+internal.Green+"+ field size int"+internal.NoColor+"\n"+
+		"\n"+
+		"0 added, 0 removed, 1 changed packages\n",
+		stdout)
+
+	stdout, _, err = execute("diff", "HEAD~1", "HEAD", repo, "-f", "svg")
+	assertEqual(t, nil, err)
+	assertEqual(t, true, strings.Contains(stdout, "<title>example.com/repo/store.DB</title>"))
+
+	_, stderr, err := execute("diff", "HEAD", "missing", repo)
+	assertEqual(t, true, err != nil)
+	assertEqual(t, "unknown revision: missing\n", stderr)
+
+	_, stderr, err = execute("diff", "HEAD")
+	assertEqual(t, true, err != nil)
+	assertEqual(t, "diff requires two revisions\n", stderr)
+
+	stdout, _, err = execute("diff", "--help")
+	assertEqual(t, nil, err)
+	assertEqual(t, true, strings.HasPrefix(stdout, "Usage: godiss diff [flags] <rev1> <rev2> [path]\n"))
+
+	write("store/broken.go", "package store\n\nfunc broken( {\n")
+	run("add", "store/broken.go")
+	run("commit", "-q", "-m", "third")
+
+	_, stderr, err = execute("diff", "HEAD~1", "HEAD", repo)
+	assertEqual(t, true, err != nil)
+	assertEqual(t, true, strings.HasPrefix(stderr, "HEAD: "), stderr)
+
+	write(".godiss.yaml", "presets:\n  diagram:\n    format: svg\ncommands:\n  diff:\n    format: dot\n")
+
+	stdout, _, err = execute("diff", "HEAD~2", "HEAD~1", repo)
+	assertEqual(t, nil, err)
+	assertEqual(t, true, strings.HasPrefix(stdout, "digraph {"))
+
+	stdout, _, err = execute("diff", "HEAD~2", "HEAD~1", repo, "--preset", "diagram")
+	assertEqual(t, nil, err)
+	assertEqual(t, true, strings.HasPrefix(stdout, "<?xml"))
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func serve() *Command {
+	var command *Command
+	command = &Command{
+		Name:        "serve",
+		Description: "Serve interactive diagrams and the model as JSON, reloading on changes",
+		DefaultArg:  ".",
+		Flags: map[string]*Flag{
+			"addr":     {"", "127.0.0.1:8080", "address to listen on"},
+			"interval": {"i", time.Second, "how often to check the sources for changes"},
+			"tests":    {"t", false, "include test packages"},
+		},
+		Run: func(out io.Writer, args []string, project *Project) error {
+			var target string
+			var module string
+			var err error
+
+			addr := command.Flags["addr"].Value.(string)
+			interval := command.Flags["interval"].Value.(time.Duration)
+			includeTests := command.Flags["tests"].Value.(bool)
+
+			if interval <= 0 {
+				return fmt.Errorf("invalid interval: %s", interval)
+			}
+
+			target, err = filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+
+			module, err = getModule(target)
+			if err != nil {
+				return err
+			}
+
+			requires, err := getRequires(target)
+			if err != nil {
+				return err
+			}
+
+			config := &internal.Config{
+				Requires:     requires,
+				IncludeTests: includeTests,
+			}
+
+			server, err := internal.NewServer(target, module, config, func() (map[string]*internal.Directory, error) {
+				return loadDirectories(target, module, project)
+			})
+			if err != nil {
+				return err
+			}
+
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+
+			if host, _, err := net.SplitHostPort(listener.Addr().String()); err == nil {
+				if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+					fmt.Fprintf(out, "warning: %s is reachable from other machines\n", listener.Addr())
+				}
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			go server.Watch(ctx, interval, func(err error) {
+				fmt.Fprintf(out, "reload failed: %s\n", err)
+			})
+
+			srv := &http.Server{
+				Handler:     server.Handler(),
+				BaseContext: func(net.Listener) context.Context { return ctx },
+			}
+
+			go func() {
+				<-ctx.Done()
+				shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				srv.Shutdown(shutdown)
+			}()
+
+			fmt.Fprintf(out, "serving %s on http://%s\n", module, listener.Addr())
+
+			err = srv.Serve(listener)
+			if errors.Is(err, http.ErrServerClosed) {
+				return nil
+			}
+			return err
+		},
+	}
+	return command
+}
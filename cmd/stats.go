@@ -2,23 +2,45 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
+	"strings"
 
 	"github.com/slavsan/godiss/internal"
 )
 
 func stats() *Command {
-	command := &Command{
+	var command *Command
+	command = &Command{
 		Name:        "stats",
 		Description: "Display stats",
 		Subcommands: map[string]*Command{},
 		DefaultArg:  ".",
-		Run: func(args []string) error {
+		Flags: map[string]*Flag{
+			"complexity": {"", false, "also display complexity aggregated per package"},
+			"table":      {"", false, "display line counts in a per-package table"},
+			"sort":       {"", "code", fmt.Sprintf("sort the table by column (%s)", strings.Join(internal.LinesColumns, ", "))},
+			"template":   {"", "", "render the output with a text/template file instead"},
+		},
+		Run: func(out io.Writer, args []string, project *Project) error {
 			var target string
 			var module string
 			var err error
 			var directories map[string]*internal.Directory
 
+			complexity := command.Flags["complexity"].Value.(bool)
+			table := command.Flags["table"].Value.(bool)
+			sortBy := command.Flags["sort"].Value.(string)
+			tmpl := command.Flags["template"].Value.(string)
+
+			if table && complexity {
+				return fmt.Errorf("--table and --complexity cannot be used together")
+			}
+
+			if !contains(internal.LinesColumns, strings.ToLower(sortBy)) {
+				return fmt.Errorf("unknown sort column: %s", sortBy)
+			}
+
 			target, err = filepath.Abs(args[0])
 			if err != nil {
 				return err
@@ -29,7 +51,7 @@ func stats() *Command {
 				return err
 			}
 
-			directories, err = internal.LoadPackages(target, module, target)
+			directories, err = loadDirectories(target, module, project)
 			if err != nil {
 				return err
 			}
@@ -42,7 +64,29 @@ func stats() *Command {
 				internal.ParsePackage(directory, module, target, config)
 			}
 
-			fmt.Printf("%s", internal.FormatStats(directories, module))
+			if tmpl != "" {
+				config.Requires, err = getRequires(target)
+				if err != nil {
+					return err
+				}
+				res, err := internal.FormatTemplate(directories, module, config, tmpl)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "%s", res)
+				return nil
+			}
+
+			if table {
+				fmt.Fprintf(out, "%s", internal.FormatPackageLines(directories, module, config, &internal.LinesOptions{Sort: sortBy}))
+				return nil
+			}
+
+			fmt.Fprintf(out, "%s", internal.FormatStats(directories, module))
+
+			if complexity {
+				fmt.Fprintf(out, "\n%s", internal.FormatPackageComplexity(directories, module))
+			}
 
 			return nil
 		},
@@ -2,16 +2,28 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
 
 	"github.com/slavsan/godiss/internal"
 )
 
 func structs() *Command {
-	return &Command{
+	var command *Command
+	command = &Command{
 		Name:        "structs",
 		Description: "Display structs defined in a file",
-		Run: func(args []string) error {
+		Flags: map[string]*Flag{
+			"format":   {"f", "dot", "output format: dot or svg"},
+			"output":   {"o", "", "render the diagram to a .svg, .png or .pdf file using Graphviz"},
+			"dot-path": {"", "dot", "path to the Graphviz dot executable"},
+		},
+		Run: func(out io.Writer, args []string, project *Project) error {
+			format := command.Flags["format"].Value.(string)
+			if format != "dot" && format != "svg" {
+				return fmt.Errorf("unsupported format: %s", format)
+			}
+
 			var target string
 			var err error
 			var structs []*internal.Struct
@@ -26,9 +38,12 @@ func structs() *Command {
 				return err
 			}
 
-			fmt.Println(internal.Format(structs))
-
-			return nil
+			return writeDiagram(out, command, func() string {
+				return internal.Format(structs, project.Style)
+			}, func() string {
+				return internal.FormatStructsSVG(structs, project.Style)
+			})
 		},
 	}
+	return command
 }
@@ -0,0 +1,10 @@
+package api
+
+import "strings"
+
+func Upper(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s)
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/project/api"
+)
+
+func main() {
+	fmt.Println(api.Upper("project"))
+}
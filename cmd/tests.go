@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func tests() *Command {
+	var command *Command
+	command = &Command{
+		Name:        "tests",
+		Description: "Display tests, benchmarks, fuzz targets and examples per package",
+		DefaultArg:  ".",
+		Flags: map[string]*Flag{
+			"summary":   {"", false, "only display counts per package"},
+			"select":    {"s", []string{}, "select packages"},
+			"exclude":   {"e", []string{}, "exclude packages"},
+			"positions": {"p", false, "prefix output lines with source positions"},
+		},
+		Run: func(out io.Writer, args []string, project *Project) error {
+			var target string
+			var module string
+			var err error
+			var directories map[string]*internal.Directory
+
+			summary := command.Flags["summary"].Value.(bool)
+			selected := command.Flags["select"].Value.([]string)
+			exclude := command.Flags["exclude"].Value.([]string)
+			positions := command.Flags["positions"].Value.(bool)
+
+			target, err = filepath.Abs(args[0])
+			if err != nil {
+				return err
+			}
+
+			module, err = getModule(target)
+			if err != nil {
+				return err
+			}
+
+			directories, err = loadDirectories(target, module, project)
+			if err != nil {
+				return err
+			}
+
+			config := &internal.Config{
+				IncludeTests: true,
+				Select:       createSet(selected),
+				Exclude:      createSet(exclude),
+			}
+
+			for _, directory := range directories {
+				internal.ParsePackage(directory, module, target, config)
+			}
+
+			fmt.Fprintf(out, "%s", internal.FormatTests(directories, module, config, &internal.TestsOptions{Summary: summary, Positions: positions}))
+
+			return nil
+		},
+	}
+	return command
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"path/filepath"
 
 	"github.com/slavsan/godiss/internal"
@@ -14,19 +15,25 @@ func types() *Command {
 		Description: "Display defined types",
 		DefaultArg:  ".",
 		Flags: map[string]*Flag{
-			"exclude":      {"e", "", "exclude packages"},
-			"select-exact": {"E", "", "select exact packages"},
-			"select":       {"s", "", "select packages"},
+			"exclude":      {"e", []string{}, "exclude packages"},
+			"select-exact": {"E", []string{}, "select exact packages"},
+			"select":       {"s", []string{}, "select packages"},
+			"embedded":     {"m", false, "show promoted fields and methods of embedded types"},
+			"positions":    {"p", false, "prefix output lines with source positions"},
+			"template":     {"", "", "render the output with a text/template file instead"},
 		},
-		Run: func(args []string) error {
+		Run: func(out io.Writer, args []string, project *Project) error {
 			var target string
 			var module string
 			var err error
 			var directories map[string]*internal.Directory
 
-			exclude := command.Flags["exclude"].Value.(string)
-			selectExact := command.Flags["select-exact"].Value.(string)
-			selected := command.Flags["select"].Value.(string)
+			exclude := command.Flags["exclude"].Value.([]string)
+			selectExact := command.Flags["select-exact"].Value.([]string)
+			selected := command.Flags["select"].Value.([]string)
+			embedded := command.Flags["embedded"].Value.(bool)
+			positions := command.Flags["positions"].Value.(bool)
+			tmpl := command.Flags["template"].Value.(string)
 
 			target, err = filepath.Abs(args[0])
 			if err != nil {
@@ -38,7 +45,7 @@ func types() *Command {
 				return err
 			}
 
-			directories, err = internal.LoadPackages(target, module, target)
+			directories, err = loadDirectories(target, module, project)
 			if err != nil {
 				return err
 			}
@@ -53,7 +60,23 @@ func types() *Command {
 				internal.ParsePackage(p, module, target, config)
 			}
 
-			fmt.Printf("%s", internal.FormatTypes(directories, module))
+			if tmpl != "" {
+				config.Requires, err = getRequires(target)
+				if err != nil {
+					return err
+				}
+				res, err := internal.FormatTemplate(directories, module, config, tmpl)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "%s", res)
+				return nil
+			}
+
+			fmt.Fprintf(out, "%s", internal.FormatTypes(directories, module, config, &internal.TypesOptions{
+				Embedded:  embedded,
+				Positions: positions,
+			}))
 
 			return nil
 		},
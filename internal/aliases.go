@@ -0,0 +1,194 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+type AliasUse struct {
+	Alias    string
+	Importer string
+	Position Position
+}
+
+func (u *AliasUse) IsDot() bool {
+	return u.Alias == "."
+}
+
+func (u *AliasUse) IsBlank() bool {
+	return u.Alias == "_"
+}
+
+type ImportAliases struct {
+	Path        string
+	PackageName string
+	Uses        []*AliasUse
+}
+
+func (a *ImportAliases) Names() []string {
+	return a.names(a.Uses)
+}
+
+func (a *ImportAliases) names(uses []*AliasUse) []string {
+	names := map[string]struct{}{}
+	for _, u := range uses {
+		if u.IsDot() || u.IsBlank() {
+			continue
+		}
+		if u.Alias == "" {
+			names[a.PackageName] = struct{}{}
+			continue
+		}
+		names[u.Alias] = struct{}{}
+	}
+	return sortedKeys(names)
+}
+
+func (a *ImportAliases) Inconsistent() bool {
+	return len(a.Names()) > 1
+}
+
+func (a *ImportAliases) InconsistentImporters() []string {
+	byImporter := map[string][]*AliasUse{}
+	for _, u := range a.Uses {
+		byImporter[u.Importer] = append(byImporter[u.Importer], u)
+	}
+
+	importers := map[string]struct{}{}
+	for importer, uses := range byImporter {
+		if len(a.names(uses)) > 1 {
+			importers[importer] = struct{}{}
+		}
+	}
+	return sortedKeys(importers)
+}
+
+func (a *ImportAliases) IsUnnecessary(u *AliasUse) bool {
+	return u.Alias == a.PackageName
+}
+
+func FindImportAliases(directories map[string]*Directory) []*ImportAliases {
+	names := map[string]string{}
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			if strings.HasSuffix(pkg.Name, "_test") {
+				continue
+			}
+			if _, ok := names[pkg.ModulePath]; ok && pkg.Name != guessPackageName(pkg.ModulePath) {
+				continue
+			}
+			names[pkg.ModulePath] = pkg.Name
+		}
+	}
+
+	byPath := map[string]*ImportAliases{}
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			sort.Sort(ByFilePath(pkg.Files))
+
+			for _, f := range pkg.Files {
+				for _, i := range f.Imports {
+					a, ok := byPath[i.Path]
+					if !ok {
+						name, ok := names[i.Path]
+						if !ok {
+							name = guessPackageName(i.Path)
+						}
+						a = &ImportAliases{Path: i.Path, PackageName: name}
+						byPath[i.Path] = a
+					}
+					a.Uses = append(a.Uses, &AliasUse{
+						Alias:    i.Name,
+						Importer: pkg.ModulePath,
+						Position: i.Position,
+					})
+				}
+			}
+		}
+	}
+
+	res := make([]*ImportAliases, 0, len(byPath))
+	for _, a := range byPath {
+		res = append(res, a)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Path < res[j].Path
+	})
+
+	return res
+}
+
+func guessPackageName(p string) string {
+	name := path.Base(p)
+	if majorVersionSuffix.MatchString(name) && path.Dir(p) != "." {
+		name = path.Base(path.Dir(p))
+	}
+	if strings.HasPrefix(p, "gopkg.in/") {
+		if idx := strings.Index(name, ".v"); idx > 0 {
+			name = name[:idx]
+		}
+	}
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimSuffix(name, "-go")
+	name = strings.TrimSuffix(name, ".go")
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+type AliasesOptions struct {
+	Positions bool
+}
+
+func FormatImportAliases(directories map[string]*Directory, module string, config *Config, opts *AliasesOptions) string {
+	var sb strings.Builder
+
+	for _, a := range FindImportAliases(directories) {
+		header := fmt.Sprintf("%s%s%s (package %s)", Yellow, a.Path, NoColor, a.PackageName)
+		if a.Inconsistent() {
+			header = fmt.Sprintf("%s %sinconsistent: %s%s", header, Red, strings.Join(a.Names(), ", "), NoColor)
+		}
+		sb.WriteString(fmt.Sprintf("%s\n", header))
+
+		inconsistent := map[string]struct{}{}
+		for _, importer := range a.InconsistentImporters() {
+			inconsistent[importer] = struct{}{}
+		}
+
+		max := 0
+		for _, u := range a.Uses {
+			if len(formatAlias(u)) > max {
+				max = len(formatAlias(u))
+			}
+		}
+
+		for _, u := range a.Uses {
+			line := fmt.Sprintf(
+				"%s    %-*s %s",
+				formatPosition(opts.Positions, u.Position), max, formatAlias(u), u.Importer,
+			)
+			switch {
+			case u.IsDot():
+				line = fmt.Sprintf("%s %s(dot import)%s", line, Purple, NoColor)
+			case u.IsBlank():
+				line = fmt.Sprintf("%s %s(side effect)%s", line, Cyan, NoColor)
+			case a.IsUnnecessary(u):
+				line = fmt.Sprintf("%s %s(unnecessary alias)%s", line, Red, NoColor)
+			}
+			if _, ok := inconsistent[u.Importer]; ok && !u.IsDot() && !u.IsBlank() {
+				line = fmt.Sprintf("%s %s(inconsistent within package)%s", line, Red, NoColor)
+			}
+			sb.WriteString(fmt.Sprintf("%s\n", line))
+		}
+	}
+
+	return sb.String()
+}
+
+func formatAlias(u *AliasUse) string {
+	if u.Alias == "" {
+		return "-"
+	}
+	return u.Alias
+}
@@ -0,0 +1,65 @@
+package internal_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func TestFindImportAliases(t *testing.T) {
+	directories := loadFixture(t, "testdata/aliases", "example.com/aliases", &internal.Config{})
+	res := internal.FindImportAliases(directories)
+
+	var paths []string
+	for _, a := range res {
+		paths = append(paths, a.Path)
+	}
+	assertEqual(t, []string{"embed", "example.com/aliases/model", "example.com/aliases/multi", "example.com/aliases/store", "strings"}, paths)
+
+	model := res[1]
+	assertEqual(t, "model", model.PackageName)
+	assertEqual(t, true, model.Inconsistent())
+	assertEqual(t, []string{"domain", "m", "model"}, model.Names())
+	assertEqual(t, true, model.IsUnnecessary(model.Uses[0]))
+	assertEqual(t, false, model.IsUnnecessary(model.Uses[1]))
+	assertEqual(t, []string{"example.com/aliases/web"}, model.InconsistentImporters())
+
+	multi := res[2]
+	assertEqual(t, "multi", multi.PackageName)
+	assertEqual(t, false, multi.IsUnnecessary(multi.Uses[0]))
+
+	store := res[3]
+	assertEqual(t, false, store.Inconsistent())
+	assertEqual(t, []string{"store"}, store.Names())
+	assertEqual(t, []string{}, store.InconsistentImporters())
+
+	assertEqual(t, true, res[0].Uses[0].IsBlank())
+	assertEqual(t, true, res[4].Uses[0].IsDot())
+}
+
+func TestFormatImportAliases(t *testing.T) {
+	expected := `__YELLOW__embed__NOCOLOR__ (package embed)
+    _ example.com/aliases/store __CYAN__(side effect)__NOCOLOR__
+__YELLOW__example.com/aliases/model__NOCOLOR__ (package model) __RED__inconsistent: domain, m, model__NOCOLOR__
+    model  example.com/aliases/api __RED__(unnecessary alias)__NOCOLOR__
+    m      example.com/aliases/store
+    -      example.com/aliases/web __RED__(inconsistent within package)__NOCOLOR__
+    domain example.com/aliases/web __RED__(inconsistent within package)__NOCOLOR__
+__YELLOW__example.com/aliases/multi__NOCOLOR__ (package multi)
+    - example.com/aliases/api
+__YELLOW__example.com/aliases/store__NOCOLOR__ (package store)
+    - example.com/aliases/api
+    - example.com/aliases/web
+__YELLOW__strings__NOCOLOR__ (package strings)
+    . example.com/aliases/web __PURPLE__(dot import)__NOCOLOR__
+`
+	expected = strings.ReplaceAll(expected, "__YELLOW__", internal.Yellow)
+	expected = strings.ReplaceAll(expected, "__RED__", internal.Red)
+	expected = strings.ReplaceAll(expected, "__CYAN__", internal.Cyan)
+	expected = strings.ReplaceAll(expected, "__PURPLE__", internal.Purple)
+	expected = strings.ReplaceAll(expected, "__NOCOLOR__", internal.NoColor)
+
+	directories := loadFixture(t, "testdata/aliases", "example.com/aliases", &internal.Config{})
+	assertEqual(t, expected, internal.FormatImportAliases(directories, "example.com/aliases", &internal.Config{}, &internal.AliasesOptions{}))
+}
@@ -0,0 +1,36 @@
+package internal
+
+import (
+	"fmt"
+	"go/build"
+	"path/filepath"
+	"runtime"
+)
+
+func matchBuildTags(f *File, config *Config) (bool, error) {
+	if config.GOOS == "" && config.GOARCH == "" && len(config.BuildTags) == 0 {
+		return true, nil
+	}
+
+	ctx := build.Context{
+		GOOS:        config.GOOS,
+		GOARCH:      config.GOARCH,
+		Compiler:    runtime.Compiler,
+		CgoEnabled:  true,
+		BuildTags:   config.BuildTags,
+		ReleaseTags: build.Default.ReleaseTags,
+	}
+	if ctx.GOOS == "" {
+		ctx.GOOS = runtime.GOOS
+	}
+	if ctx.GOARCH == "" {
+		ctx.GOARCH = runtime.GOARCH
+	}
+
+	dir := filepath.Dir(f.Path)
+	ok, err := ctx.MatchFile(dir, filepath.Base(f.Path))
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", dir, err)
+	}
+	return ok, nil
+}
@@ -0,0 +1,352 @@
+package internal
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+type Complexity struct {
+	Cyclomatic int
+	Cognitive  int
+	Lines      int
+	Params     int
+	Nesting    int
+}
+
+var ComplexityColumns = []string{"cyclomatic", "cognitive", "lines", "params", "nesting"}
+
+func (c *Complexity) Value(column string) int {
+	switch column {
+	case "cyclomatic":
+		return c.Cyclomatic
+	case "lines":
+		return c.Lines
+	case "params":
+		return c.Params
+	case "nesting":
+		return c.Nesting
+	default:
+		return c.Cognitive
+	}
+}
+
+func measureComplexity(fset *token.FileSet, fn *ast.FuncDecl) *Complexity {
+	if fn.Body == nil {
+		return nil
+	}
+
+	c := &complexityWalker{cyclomatic: 1}
+	c.walk(fn.Body, 0)
+
+	params := 0
+	for _, p := range fn.Type.Params.List {
+		if len(p.Names) == 0 {
+			params++
+			continue
+		}
+		params += len(p.Names)
+	}
+
+	return &Complexity{
+		Cyclomatic: c.cyclomatic,
+		Cognitive:  c.cognitive,
+		Lines:      fset.Position(fn.End()).Line - fset.Position(fn.Pos()).Line + 1,
+		Params:     params,
+		Nesting:    c.maxNesting,
+	}
+}
+
+type complexityWalker struct {
+	cyclomatic int
+	cognitive  int
+	maxNesting int
+}
+
+func (c *complexityWalker) nested(node ast.Node, nesting int) {
+	if node == nil {
+		return
+	}
+	if nesting+1 > c.maxNesting {
+		c.maxNesting = nesting + 1
+	}
+	c.walk(node, nesting+1)
+}
+
+func (c *complexityWalker) walk(node ast.Node, nesting int) {
+	if node == nil {
+		return
+	}
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch v := n.(type) {
+		case *ast.IfStmt:
+			c.ifStmt(v, nesting, false)
+			return false
+		case *ast.ForStmt:
+			c.cyclomatic++
+			c.cognitive += 1 + nesting
+			c.walk(v.Init, nesting)
+			c.walk(v.Cond, nesting)
+			c.walk(v.Post, nesting)
+			c.nested(v.Body, nesting)
+			return false
+		case *ast.RangeStmt:
+			c.cyclomatic++
+			c.cognitive += 1 + nesting
+			c.walk(v.X, nesting)
+			c.nested(v.Body, nesting)
+			return false
+		case *ast.SwitchStmt:
+			c.cognitive += 1 + nesting
+			c.walk(v.Init, nesting)
+			c.walk(v.Tag, nesting)
+			c.nested(v.Body, nesting)
+			return false
+		case *ast.TypeSwitchStmt:
+			c.cognitive += 1 + nesting
+			c.walk(v.Init, nesting)
+			c.walk(v.Assign, nesting)
+			c.nested(v.Body, nesting)
+			return false
+		case *ast.SelectStmt:
+			c.cognitive += 1 + nesting
+			c.nested(v.Body, nesting)
+			return false
+		case *ast.CaseClause:
+			if v.List != nil {
+				c.cyclomatic++
+			}
+		case *ast.CommClause:
+			if v.Comm != nil {
+				c.cyclomatic++
+			}
+		case *ast.FuncLit:
+			c.nested(v.Body, nesting)
+			return false
+		case *ast.BranchStmt:
+			if v.Tok == token.GOTO || v.Label != nil {
+				c.cognitive++
+			}
+		case *ast.BinaryExpr:
+			if v.Op == token.LAND || v.Op == token.LOR {
+				c.logical(v, nesting)
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (c *complexityWalker) ifStmt(v *ast.IfStmt, nesting int, elseIf bool) {
+	c.cyclomatic++
+	if elseIf {
+		c.cognitive++
+	} else {
+		c.cognitive += 1 + nesting
+	}
+
+	c.walk(v.Init, nesting)
+	c.walk(v.Cond, nesting)
+	c.nested(v.Body, nesting)
+
+	switch e := v.Else.(type) {
+	case *ast.IfStmt:
+		c.ifStmt(e, nesting, true)
+	case *ast.BlockStmt:
+		c.cognitive++
+		c.nested(e, nesting)
+	}
+}
+
+func (c *complexityWalker) logical(v *ast.BinaryExpr, nesting int) {
+	var ops []token.Token
+	var operands []ast.Expr
+
+	var flatten func(e ast.Expr)
+	flatten = func(e ast.Expr) {
+		if b, ok := e.(*ast.BinaryExpr); ok && (b.Op == token.LAND || b.Op == token.LOR) {
+			flatten(b.X)
+			ops = append(ops, b.Op)
+			flatten(b.Y)
+			return
+		}
+		operands = append(operands, e)
+	}
+	flatten(v)
+
+	c.cyclomatic += len(ops)
+	for i, op := range ops {
+		if i == 0 || ops[i-1] != op {
+			c.cognitive++
+		}
+	}
+
+	for _, operand := range operands {
+		c.walk(operand, nesting)
+	}
+}
+
+type FunctionComplexity struct {
+	Package    string
+	Name       string
+	Position   Position
+	Complexity *Complexity
+}
+
+func FindComplexity(directories map[string]*Directory) []*FunctionComplexity {
+	var res []*FunctionComplexity
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			for _, f := range pkg.Files {
+				for _, fn := range f.Functions {
+					if fn.Complexity == nil {
+						continue
+					}
+					res = append(res, &FunctionComplexity{
+						Package:    pkg.ModulePath,
+						Name:       fn.Name,
+						Position:   fn.Position,
+						Complexity: fn.Complexity,
+					})
+				}
+				for _, m := range f.Methods {
+					if m.Complexity == nil {
+						continue
+					}
+					res = append(res, &FunctionComplexity{
+						Package:    pkg.ModulePath,
+						Name:       fmt.Sprintf("%s.%s", m.Receiver, methodName(m)),
+						Position:   m.Position,
+						Complexity: m.Complexity,
+					})
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+type ComplexityOptions struct {
+	Sort      string
+	Top       int
+	Positions bool
+}
+
+func FormatComplexity(directories map[string]*Directory, module string, config *Config, opts *ComplexityOptions) string {
+	var sb strings.Builder
+
+	functions := FindComplexity(directories)
+
+	column := strings.ToLower(opts.Sort)
+	sort.SliceStable(functions, func(i, j int) bool {
+		a, b := functions[i].Complexity.Value(column), functions[j].Complexity.Value(column)
+		if a == b {
+			return functions[i].Position.Before(functions[j].Position)
+		}
+		return a > b
+	})
+
+	if opts.Top > 0 && len(functions) > opts.Top {
+		functions = functions[:opts.Top]
+	}
+
+	sb.WriteString(fmt.Sprintf(
+		"%5s %5s %5s %6s %7s  %s\n",
+		"cyclo", "cogn", "lines", "params", "nesting", "function",
+	))
+
+	for _, fc := range functions {
+		c := fc.Complexity
+		sb.WriteString(fmt.Sprintf(
+			"%s%5d %5d %5d %6d %7d  %s%s%s.%s\n",
+			formatPosition(opts.Positions, fc.Position),
+			c.Cyclomatic, c.Cognitive, c.Lines, c.Params, c.Nesting,
+			Yellow, fc.Package, NoColor, fc.Name,
+		))
+	}
+
+	return sb.String()
+}
+
+type PackageComplexity struct {
+	Path          string
+	Functions     int
+	Lines         int
+	AvgCyclomatic float64
+	MaxCyclomatic int
+	AvgCognitive  float64
+	MaxCognitive  int
+	MaxNesting    int
+}
+
+func FindPackageComplexity(directories map[string]*Directory) []*PackageComplexity {
+	byPath := map[string]*PackageComplexity{}
+	var paths []string
+
+	for _, fc := range FindComplexity(directories) {
+		p, ok := byPath[fc.Package]
+		if !ok {
+			p = &PackageComplexity{Path: fc.Package}
+			byPath[fc.Package] = p
+			paths = append(paths, fc.Package)
+		}
+
+		c := fc.Complexity
+		p.Functions++
+		p.Lines += c.Lines
+		p.AvgCyclomatic += float64(c.Cyclomatic)
+		p.AvgCognitive += float64(c.Cognitive)
+		if c.Cyclomatic > p.MaxCyclomatic {
+			p.MaxCyclomatic = c.Cyclomatic
+		}
+		if c.Cognitive > p.MaxCognitive {
+			p.MaxCognitive = c.Cognitive
+		}
+		if c.Nesting > p.MaxNesting {
+			p.MaxNesting = c.Nesting
+		}
+	}
+
+	res := make([]*PackageComplexity, 0, len(paths))
+	for _, path := range paths {
+		p := byPath[path]
+		p.AvgCyclomatic /= float64(p.Functions)
+		p.AvgCognitive /= float64(p.Functions)
+		res = append(res, p)
+	}
+
+	return res
+}
+
+func FormatPackageComplexity(directories map[string]*Directory, module string) string {
+	var sb strings.Builder
+
+	packages := FindPackageComplexity(directories)
+
+	max := len("package")
+	for _, p := range packages {
+		if len(p.Path) > max {
+			max = len(p.Path)
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf(
+		"%-*s | %5s | %6s | %9s | %9s | %8s | %8s | %7s\n",
+		max, "package", "funcs", "lines", "avg cyclo", "max cyclo", "avg cogn", "max cogn", "nesting",
+	))
+
+	for _, p := range packages {
+		sb.WriteString(fmt.Sprintf(
+			"%-*s | %5d | %6d | %9.2f | %9d | %8.2f | %8d | %7d\n",
+			max, p.Path, p.Functions, p.Lines, p.AvgCyclomatic, p.MaxCyclomatic,
+			p.AvgCognitive, p.MaxCognitive, p.MaxNesting,
+		))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,62 @@
+package internal_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func TestFindComplexity(t *testing.T) {
+	directories := loadFixture(t, "testdata/complexity", "example.com/complexity", &internal.Config{})
+
+	var actual []string
+	for _, fc := range internal.FindComplexity(directories) {
+		c := fc.Complexity
+		actual = append(actual, fmt.Sprintf(
+			"%s cyclo=%d cogn=%d lines=%d params=%d nesting=%d",
+			fc.Name, c.Cyclomatic, c.Cognitive, c.Lines, c.Params, c.Nesting,
+		))
+	}
+
+	assertEqual(t, []string{
+		"Simple cyclo=1 cogn=0 lines=3 params=0 nesting=0",
+		"Branches cyclo=6 cogn=5 lines=9 params=3 nesting=1",
+		"Nested cyclo=6 cogn=9 lines=18 params=1 nesting=3",
+		"Worker.Run cyclo=4 cogn=5 lines=14 params=1 nesting=2",
+		"Counter.Next cyclo=3 cogn=4 lines=12 params=0 nesting=2",
+	}, actual)
+}
+
+func TestFormatComplexity(t *testing.T) {
+	directories := loadFixture(t, "testdata/complexity", "example.com/complexity", &internal.Config{})
+
+	expected := "" +
+		"cyclo  cogn lines params nesting  function\n" +
+		"testdata/complexity/complexity.go:7:6:     6     5     9      3       1  __YELLOW__example.com/complexity__NOCOLOR__.Branches\n" +
+		"testdata/complexity/complexity.go:17:6:     6     9    18      1       3  __YELLOW__example.com/complexity__NOCOLOR__.Nested\n"
+
+	expected = strings.ReplaceAll(expected, "__YELLOW__", internal.Yellow)
+	expected = strings.ReplaceAll(expected, "__NOCOLOR__", internal.NoColor)
+
+	assertEqual(t, expected, internal.FormatComplexity(directories, "example.com/complexity", &internal.Config{}, &internal.ComplexityOptions{Sort: "cyclomatic", Top: 2, Positions: true}))
+}
+
+func TestFindPackageComplexity(t *testing.T) {
+	directories := loadFixture(t, "testdata/complexity", "example.com/complexity", &internal.Config{})
+
+	packages := internal.FindPackageComplexity(directories)
+
+	assertEqual(t, 1, len(packages))
+	assertEqual(t, &internal.PackageComplexity{
+		Path:          "example.com/complexity",
+		Functions:     5,
+		Lines:         56,
+		AvgCyclomatic: 4,
+		MaxCyclomatic: 6,
+		AvgCognitive:  4.6,
+		MaxCognitive:  9,
+		MaxNesting:    3,
+	}, packages[0])
+}
@@ -0,0 +1,240 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strings"
+)
+
+type Coupling struct {
+	Path         string
+	Afferent     int
+	Efferent     int
+	Abstract     int
+	Types        int
+	Instability  float64
+	Abstractness float64
+	Distance     float64
+}
+
+func (c *Coupling) Isolated() bool {
+	return c.Afferent+c.Efferent == 0
+}
+
+var CouplingColumns = []string{"package", "ca", "ce", "i", "a", "d", "types"}
+
+type CouplingOptions struct {
+	Sort     string
+	External bool
+}
+
+func FindCoupling(directories map[string]*Directory, module string, config *Config, opts *CouplingOptions) []*Coupling {
+	g := NewImportGraph(directories, module, config.Requires)
+
+	metrics := map[string]*Coupling{}
+	efferent := map[string]map[string]struct{}{}
+	afferent := map[string]map[string]struct{}{}
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			if strings.HasSuffix(pkg.Name, "_test") {
+				continue
+			}
+
+			m, ok := metrics[pkg.ModulePath]
+			if !ok {
+				m = &Coupling{Path: pkg.ModulePath}
+				metrics[pkg.ModulePath] = m
+				efferent[pkg.ModulePath] = map[string]struct{}{}
+			}
+
+			for _, f := range pkg.Files {
+				if isTestFile(f.Path) {
+					continue
+				}
+				m.Abstract += len(f.Interfaces)
+				m.Types += len(f.Interfaces) + len(f.Structs) + len(f.Types)
+			}
+
+			for _, i := range packageImports(pkg, false) {
+				if i == pkg.ModulePath {
+					continue
+				}
+				kind := g.Kind(i)
+				if kind != ModuleImport && !opts.External {
+					continue
+				}
+				if kind == ExternalImport {
+					i = g.ExternalModule(i)
+				}
+				efferent[pkg.ModulePath][i] = struct{}{}
+				if kind == ModuleImport {
+					if _, ok := afferent[i]; !ok {
+						afferent[i] = map[string]struct{}{}
+					}
+					afferent[i][pkg.ModulePath] = struct{}{}
+				}
+			}
+		}
+	}
+
+	res := make([]*Coupling, 0, len(metrics))
+
+	for p, m := range metrics {
+		m.Afferent = len(afferent[p])
+		m.Efferent = len(efferent[p])
+		if m.Types > 0 {
+			m.Abstractness = float64(m.Abstract) / float64(m.Types)
+		}
+		if !m.Isolated() {
+			m.Instability = float64(m.Efferent) / float64(m.Afferent+m.Efferent)
+			m.Distance = math.Abs(m.Abstractness + m.Instability - 1)
+		}
+		res = append(res, m)
+	}
+
+	sortCoupling(res, opts.Sort)
+
+	return res
+}
+
+func sortCoupling(metrics []*Coupling, column string) {
+	column = strings.ToLower(column)
+
+	value := func(m *Coupling) float64 {
+		switch column {
+		case "ca":
+			return float64(m.Afferent)
+		case "ce":
+			return float64(m.Efferent)
+		case "i":
+			if m.Isolated() {
+				return -1
+			}
+			return m.Instability
+		case "a":
+			return m.Abstractness
+		case "types":
+			return float64(m.Types)
+		default:
+			if m.Isolated() {
+				return -1
+			}
+			return m.Distance
+		}
+	}
+
+	sort.SliceStable(metrics, func(i, j int) bool {
+		if column == "package" {
+			return metrics[i].Path < metrics[j].Path
+		}
+		if value(metrics[i]) == value(metrics[j]) {
+			return metrics[i].Path < metrics[j].Path
+		}
+		return value(metrics[i]) > value(metrics[j])
+	})
+}
+
+func FormatCoupling(directories map[string]*Directory, module string, config *Config, opts *CouplingOptions) string {
+	var sb strings.Builder
+
+	metrics := FindCoupling(directories, module, config, opts)
+
+	max := len("package")
+	for _, m := range metrics {
+		if len(m.Path) > max {
+			max = len(m.Path)
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf(
+		"%-*s | %4s | %4s | %5s | %5s | %5s | %5s\n",
+		max, "package", "Ca", "Ce", "I", "A", "D", "types",
+	))
+	sb.WriteString(fmt.Sprintf(
+		"%s-+-%s-+-%s-+-%s-+-%s-+-%s-+-%s\n",
+		strings.Repeat("-", max), strings.Repeat("-", 4), strings.Repeat("-", 4),
+		strings.Repeat("-", 5), strings.Repeat("-", 5), strings.Repeat("-", 5), strings.Repeat("-", 5),
+	))
+
+	for _, m := range metrics {
+		color := NoColor
+		if m.Distance >= 0.7 {
+			color = Red
+		} else if m.Distance >= 0.4 {
+			color = Yellow
+		}
+
+		instability, distance := fmt.Sprintf("%.2f", m.Instability), fmt.Sprintf("%.2f", m.Distance)
+		if m.Isolated() {
+			instability, distance = "n/a", "n/a"
+		}
+
+		sb.WriteString(fmt.Sprintf(
+			"%-*s | %4d | %4d | %5s | %5.2f | %s%5s%s | %5d\n",
+			max, m.Path, m.Afferent, m.Efferent, instability, m.Abstractness,
+			color, distance, NoColor, m.Types,
+		))
+	}
+
+	return sb.String()
+}
+
+func FormatCouplingSVG(directories map[string]*Directory, module string, config *Config, opts *CouplingOptions) string {
+	var sb strings.Builder
+
+	const (
+		size   = 600
+		margin = 60
+		plot   = size - 2*margin
+	)
+
+	x := func(v float64) float64 { return margin + v*plot }
+	y := func(v float64) float64 { return margin + (1-v)*plot }
+
+	sb.WriteString(fmt.Sprintf(
+		"<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" font-family=\"Helvetica,Arial,sans-serif\" font-size=\"11\">\n",
+		size, size, size, size,
+	))
+	sb.WriteString(fmt.Sprintf("    <rect width=\"%d\" height=\"%d\" fill=\"#ffffff\"/>\n", size, size))
+	sb.WriteString(fmt.Sprintf(
+		"    <rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"none\" stroke=\"#888888\"/>\n",
+		margin, margin, plot, plot,
+	))
+	sb.WriteString(fmt.Sprintf(
+		"    <line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"#4caf50\" stroke-dasharray=\"6,4\"/>\n",
+		x(0), y(1), x(1), y(0),
+	))
+	sb.WriteString(fmt.Sprintf(
+		"    <text x=\"%d\" y=\"%d\" text-anchor=\"middle\">Instability (I)</text>\n",
+		size/2, size-margin/3,
+	))
+	sb.WriteString(fmt.Sprintf(
+		"    <text x=\"%d\" y=\"%d\" text-anchor=\"middle\" transform=\"rotate(-90 %d %d)\">Abstractness (A)</text>\n",
+		margin/3, size/2, margin/3, size/2,
+	))
+	sb.WriteString(fmt.Sprintf("    <text x=\"%.1f\" y=\"%.1f\" fill=\"#c62828\">zone of pain</text>\n", x(0)+4, y(0)-6))
+	sb.WriteString(fmt.Sprintf("    <text x=\"%.1f\" y=\"%.1f\" fill=\"#c62828\" text-anchor=\"end\">zone of uselessness</text>\n", x(1)-4, y(1)+14))
+
+	for _, m := range FindCoupling(directories, module, config, opts) {
+		if m.Isolated() {
+			continue
+		}
+		red := int(math.Round(m.Distance * 255))
+		sb.WriteString(fmt.Sprintf(
+			"    <circle cx=\"%.1f\" cy=\"%.1f\" r=\"5\" fill=\"#%02x%02x40\"><title>%s I=%.2f A=%.2f D=%.2f</title></circle>\n",
+			x(m.Instability), y(m.Abstractness), red, 255-red,
+			xmlEscape(m.Path), m.Instability, m.Abstractness, m.Distance,
+		))
+		sb.WriteString(fmt.Sprintf(
+			"    <text x=\"%.1f\" y=\"%.1f\">%s</text>\n",
+			x(m.Instability)+7, y(m.Abstractness)-7, xmlEscape(path.Base(m.Path)),
+		))
+	}
+
+	sb.WriteString("</svg>\n")
+
+	return sb.String()
+}
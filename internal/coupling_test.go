@@ -0,0 +1,85 @@
+package internal_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func TestFindCoupling(t *testing.T) {
+	directories := loadFixture(t, "testdata/entrypoints", "example.com/entrypoints", &internal.Config{IncludeTests: true})
+
+	var actual []string
+	for _, m := range internal.FindCoupling(directories, "example.com/entrypoints", &internal.Config{}, &internal.CouplingOptions{}) {
+		actual = append(actual, fmt.Sprintf(
+			"%s ca=%d ce=%d i=%.2f a=%.2f d=%.2f",
+			m.Path, m.Afferent, m.Efferent, m.Instability, m.Abstractness, m.Distance,
+		))
+	}
+
+	assertEqual(t, []string{
+		"example.com/entrypoints/lib ca=1 ce=1 i=0.50 a=0.00 d=0.50",
+		"example.com/entrypoints ca=0 ce=1 i=1.00 a=0.00 d=0.00",
+		"example.com/entrypoints/cmd/tool ca=0 ce=1 i=1.00 a=0.00 d=0.00",
+		"example.com/entrypoints/shared ca=2 ce=0 i=0.00 a=1.00 d=0.00",
+		"example.com/entrypoints/app/v2 ca=0 ce=0 i=0.00 a=0.00 d=0.00",
+		"example.com/entrypoints/daemon ca=0 ce=0 i=0.00 a=0.00 d=0.00",
+	}, actual)
+}
+
+func TestFormatCouplingIsolated(t *testing.T) {
+	expected := "" +
+		"package                          |   Ca |   Ce |     I |     A |     D | types\n" +
+		"---------------------------------+------+------+-------+-------+-------+------\n" +
+		"example.com/entrypoints/lib      |    1 |    1 |  0.50 |  0.00 | __YELLOW__ 0.50__NOCOLOR__ |     0\n" +
+		"example.com/entrypoints          |    0 |    1 |  1.00 |  0.00 | __NOCOLOR__ 0.00__NOCOLOR__ |     0\n" +
+		"example.com/entrypoints/cmd/tool |    0 |    1 |  1.00 |  0.00 | __NOCOLOR__ 0.00__NOCOLOR__ |     0\n" +
+		"example.com/entrypoints/shared   |    2 |    0 |  0.00 |  1.00 | __NOCOLOR__ 0.00__NOCOLOR__ |     1\n" +
+		"example.com/entrypoints/app/v2   |    0 |    0 |   n/a |  0.00 | __NOCOLOR__  n/a__NOCOLOR__ |     0\n" +
+		"example.com/entrypoints/daemon   |    0 |    0 |   n/a |  0.00 | __NOCOLOR__  n/a__NOCOLOR__ |     0\n"
+
+	expected = strings.ReplaceAll(expected, "__YELLOW__", internal.Yellow)
+	expected = strings.ReplaceAll(expected, "__NOCOLOR__", internal.NoColor)
+
+	directories := loadFixture(t, "testdata/entrypoints", "example.com/entrypoints", &internal.Config{IncludeTests: true})
+
+	assertEqual(t, expected, internal.FormatCoupling(directories, "example.com/entrypoints", &internal.Config{}, &internal.CouplingOptions{}))
+}
+
+func TestFindCouplingExternalSortedByEfferent(t *testing.T) {
+	directories := loadFixture(t, "testdata/entrypoints", "example.com/entrypoints", &internal.Config{IncludeTests: true})
+
+	config := &internal.Config{
+		Requires: []string{"github.com/acme/widgets"},
+	}
+
+	var actual []string
+	for _, m := range internal.FindCoupling(directories, "example.com/entrypoints", config, &internal.CouplingOptions{Sort: "ce", External: true}) {
+		actual = append(actual, fmt.Sprintf("%s ce=%d", m.Path, m.Efferent))
+	}
+
+	assertEqual(t, []string{
+		"example.com/entrypoints/lib ce=3",
+		"example.com/entrypoints/cmd/tool ce=2",
+		"example.com/entrypoints ce=1",
+		"example.com/entrypoints/shared ce=1",
+		"example.com/entrypoints/app/v2 ce=0",
+		"example.com/entrypoints/daemon ce=0",
+	}, actual)
+}
+
+func TestFormatCouplingSVG(t *testing.T) {
+	directories := loadFixture(t, "testdata/entrypoints", "example.com/entrypoints", &internal.Config{IncludeTests: true})
+
+	actual := internal.FormatCouplingSVG(directories, "example.com/entrypoints", &internal.Config{}, &internal.CouplingOptions{})
+
+	if !strings.HasPrefix(actual, "<svg ") || !strings.HasSuffix(actual, "</svg>\n") {
+		t.Errorf("expected an svg document, got:\n%s", actual)
+	}
+	assertEqual(t, 4, strings.Count(actual, "<circle "))
+	if !strings.Contains(actual, "<title>example.com/entrypoints/shared I=0.00 A=1.00 D=0.00</title>") {
+		t.Errorf("missing shared package point:\n%s", actual)
+	}
+}
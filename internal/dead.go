@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+type DeadPackage struct {
+	Path      string
+	Files     int
+	Importers []string
+}
+
+type DeadOptions struct {
+	Roots []string
+	Tests bool
+}
+
+func FindDeadPackages(directories map[string]*Directory, module string, config *Config, opts *DeadOptions) []*DeadPackage {
+	g := NewImportGraph(directories, module, config.Requires)
+
+	var roots []string
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			if pkg.Name == "main" {
+				roots = append(roots, packageImports(pkg, false)...)
+				continue
+			}
+
+			if !opts.Tests {
+				continue
+			}
+
+			if strings.HasSuffix(pkg.Name, "_test") {
+				roots = append(roots, packageImports(pkg, true)...)
+				continue
+			}
+
+			for _, f := range pkg.Files {
+				if isTestFile(f.Path) {
+					roots = append(roots, pkg.ModulePath)
+					for _, i := range f.Imports {
+						roots = append(roots, i.Path)
+					}
+				}
+			}
+		}
+	}
+
+	for _, p := range g.Packages() {
+		if isRoot(p, module, opts.Roots) {
+			roots = append(roots, p)
+		}
+	}
+
+	var moduleRoots []string
+	for _, r := range roots {
+		if g.Contains(r) {
+			moduleRoots = append(moduleRoots, r)
+		}
+	}
+
+	alive := g.Closure(moduleRoots)
+
+	importers := map[string][]string{}
+	for _, p := range g.Packages() {
+		for _, i := range g.Imports(p) {
+			importers[i] = append(importers[i], p)
+		}
+	}
+
+	var dead []*DeadPackage
+	for _, p := range g.Packages() {
+		if _, ok := alive[p]; ok {
+			continue
+		}
+
+		files := 0
+		for _, pkg := range g.packages[p] {
+			if isImportable(pkg) {
+				files += len(pkg.Files)
+			}
+		}
+
+		dead = append(dead, &DeadPackage{
+			Path:      p,
+			Files:     files,
+			Importers: importers[p],
+		})
+	}
+
+	return dead
+}
+
+func isRoot(p, module string, roots []string) bool {
+	for _, r := range roots {
+		recursive := strings.HasSuffix(r, "/...")
+		r = strings.TrimSuffix(strings.TrimSuffix(r, "/..."), "/")
+		r = strings.TrimPrefix(r, "./")
+
+		if module != "" && r != module && !strings.HasPrefix(r, module+"/") {
+			if r == "" || r == "." {
+				r = module
+			} else {
+				r = fmt.Sprintf("%s/%s", module, r)
+			}
+		}
+
+		if p == r || (recursive && strings.HasPrefix(p, r+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+func FormatDeadPackages(directories map[string]*Directory, module string, config *Config, opts *DeadOptions) string {
+	var sb strings.Builder
+
+	for _, d := range FindDeadPackages(directories, module, config, opts) {
+		files := "files"
+		if d.Files == 1 {
+			files = "file"
+		}
+
+		sb.WriteString(fmt.Sprintf("%s%s%s (%d %s)", Red, d.Path, NoColor, d.Files, files))
+		if len(d.Importers) > 0 {
+			sb.WriteString(fmt.Sprintf(" imported only by: %s", strings.Join(d.Importers, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,52 @@
+package internal_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func findDeadPackages(t *testing.T, config *internal.Config, opts *internal.DeadOptions) []string {
+	t.Helper()
+	directories := loadFixture(t, "testdata/dead", "example.com/dead", &internal.Config{IncludeTests: true})
+
+	var res []string
+	for _, d := range internal.FindDeadPackages(directories, "example.com/dead", config, opts) {
+		res = append(res, fmt.Sprintf("%s %d %v", d.Path, d.Files, d.Importers))
+	}
+	return res
+}
+
+func TestFindDeadPackages(t *testing.T) {
+	assertEqual(t, []string{
+		"example.com/dead/api 1 []",
+		"example.com/dead/orphan 1 []",
+		"example.com/dead/orphan/child 1 [example.com/dead/orphan]",
+		"example.com/dead/tested 2 []",
+		"example.com/dead/testhelper 1 []",
+	}, findDeadPackages(t, &internal.Config{}, &internal.DeadOptions{}))
+}
+
+func TestFindDeadPackagesWithRoots(t *testing.T) {
+	assertEqual(t, []string{
+		"example.com/dead/orphan 1 []",
+		"example.com/dead/orphan/child 1 [example.com/dead/orphan]",
+	}, findDeadPackages(t, &internal.Config{}, &internal.DeadOptions{Roots: []string{"api"}, Tests: true}))
+
+	assertEqual(t, []string{
+		"example.com/dead/api 1 []",
+	}, findDeadPackages(t, &internal.Config{}, &internal.DeadOptions{Roots: []string{"example.com/dead/orphan"}, Tests: true}))
+
+	assertEqual(t, []string{
+		"example.com/dead/api 1 []",
+	}, findDeadPackages(t, &internal.Config{}, &internal.DeadOptions{Roots: []string{"./orphan/..."}, Tests: true}))
+}
+
+func TestFindDeadPackagesWithTests(t *testing.T) {
+	assertEqual(t, []string{
+		"example.com/dead/api 1 []",
+		"example.com/dead/orphan 1 []",
+		"example.com/dead/orphan/child 1 [example.com/dead/orphan]",
+	}, findDeadPackages(t, &internal.Config{}, &internal.DeadOptions{Tests: true}))
+}
@@ -0,0 +1,154 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Dependencies struct {
+	Entrypoint *Entrypoint
+	Packages   []string
+	Modules    []string
+	StdLib     []string
+	Edges      map[string][]string
+}
+
+type DependenciesOptions struct {
+	Binary    string
+	Style     *DiagramStyle
+	Positions bool
+}
+
+func FindDependencies(directories map[string]*Directory, module string, config *Config, opts *DependenciesOptions) []*Dependencies {
+	var res []*Dependencies
+
+	g := NewImportGraph(directories, module, config.Requires)
+	seen := map[*Package]struct{}{}
+
+	for _, e := range FindEntrypoints(directories) {
+		if !e.IsBinary() {
+			continue
+		}
+		if _, ok := seen[e.Package]; ok {
+			continue
+		}
+		seen[e.Package] = struct{}{}
+
+		if opts.Binary != "" && e.Binary != opts.Binary {
+			continue
+		}
+
+		root := e.Package.ModulePath
+		direct := packageImports(e.Package, false)
+
+		var roots []string
+		for _, i := range direct {
+			if g.Contains(i) {
+				roots = append(roots, i)
+			}
+		}
+
+		closure := g.Closure(roots)
+
+		deps := &Dependencies{
+			Entrypoint: e,
+			Edges:      map[string][]string{root: {}},
+		}
+
+		modules := map[string]struct{}{}
+		stdlib := map[string]struct{}{}
+
+		addEdges := func(from string, imports []string) {
+			for _, i := range imports {
+				switch g.Kind(i) {
+				case StdLibImport:
+					stdlib[i] = struct{}{}
+				case ExternalImport:
+					m := g.ExternalModule(i)
+					modules[m] = struct{}{}
+					deps.Edges[from] = appendUnique(deps.Edges[from], m)
+				case ModuleImport:
+					deps.Edges[from] = appendUnique(deps.Edges[from], i)
+				}
+			}
+		}
+
+		addEdges(root, direct)
+		for _, p := range sortedKeys(closure) {
+			deps.Packages = append(deps.Packages, p)
+			addEdges(p, g.Imports(p))
+		}
+
+		deps.Modules = sortedKeys(modules)
+		deps.StdLib = sortedKeys(stdlib)
+
+		res = append(res, deps)
+	}
+
+	return res
+}
+
+func appendUnique(items []string, item string) []string {
+	for _, i := range items {
+		if i == item {
+			return items
+		}
+	}
+	return append(items, item)
+}
+
+func FormatDependencies(directories map[string]*Directory, module string, config *Config, opts *DependenciesOptions) string {
+	var sb strings.Builder
+
+	for _, deps := range FindDependencies(directories, module, config, opts) {
+		e := deps.Entrypoint
+
+		sb.WriteString(fmt.Sprintf(
+			"%s%s%s%s %s (%d module packages, %d third-party modules, %d stdlib packages)\n",
+			formatPosition(opts.Positions, e.Function.Position),
+			Blue, e.Binary, NoColor,
+			e.Package.ModulePath,
+			len(deps.Packages), len(deps.Modules), len(deps.StdLib),
+		))
+
+		for _, p := range deps.Packages {
+			sb.WriteString(fmt.Sprintf("    %s%s%s\n", Green, p, NoColor))
+		}
+		for _, m := range deps.Modules {
+			sb.WriteString(fmt.Sprintf("    %s\n", m))
+		}
+	}
+
+	return sb.String()
+}
+
+func FormatDependenciesDot(directories map[string]*Directory, module string, config *Config, opts *DependenciesOptions) string {
+	var sb strings.Builder
+	sb.WriteString("digraph {\n")
+	writeDotHeader(&sb, opts.Style)
+
+	for _, deps := range FindDependencies(directories, module, config, opts) {
+		binary := deps.Entrypoint.Binary
+		root := deps.Entrypoint.Package.ModulePath
+
+		sb.WriteString(fmt.Sprintf("\n    subgraph cluster_%s {\n", normalizePackageName(root)))
+		sb.WriteString(fmt.Sprintf("        label = \"%s\"\n", binary))
+
+		nodes := append([]string{root}, deps.Packages...)
+		nodes = append(nodes, deps.Modules...)
+		for _, n := range nodes {
+			sb.WriteString(fmt.Sprintf("        \"%s|%s\" [label=\"%s\"]\n", binary, n, n))
+		}
+
+		for _, from := range nodes {
+			for _, to := range deps.Edges[from] {
+				sb.WriteString(fmt.Sprintf("        \"%s|%s\" -> \"%s|%s\"\n", binary, from, binary, to))
+			}
+		}
+
+		sb.WriteString("    }\n")
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
@@ -0,0 +1,159 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Diagram struct {
+	Nodes    []*DiagramNode
+	Edges    []*DiagramEdge
+	Clusters []*DiagramCluster
+	Style    *DiagramStyle
+}
+
+type DiagramNode struct {
+	ID        string
+	Label     string
+	Sections  [][]string
+	RowColors [][]string
+	Cluster   string
+	Color     string
+}
+
+type DiagramEdge struct {
+	From  string
+	To    string
+	Label string
+	Width int
+	Color string
+}
+
+type DiagramCluster struct {
+	ID     string
+	Label  string
+	Parent string
+	Color  string
+}
+
+func dotQuote(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `"`, `\"`)
+}
+
+func FormatDiagram(d *Diagram) string {
+	var sb strings.Builder
+	sb.WriteString("digraph {\n")
+	writeDotHeader(&sb, d.Style)
+	sb.WriteString("\n")
+
+	known := map[string]struct{}{}
+	for _, c := range d.Clusters {
+		known[c.ID] = struct{}{}
+	}
+
+	children := map[string][]*DiagramCluster{}
+	for _, c := range d.Clusters {
+		parent := c.Parent
+		if _, ok := known[parent]; !ok {
+			parent = ""
+		}
+		children[parent] = append(children[parent], c)
+	}
+
+	nodes := map[string][]*DiagramNode{}
+	for _, n := range d.Nodes {
+		cluster := n.Cluster
+		if _, ok := known[cluster]; !ok {
+			cluster = ""
+		}
+		nodes[cluster] = append(nodes[cluster], n)
+	}
+
+	writeDiagramCluster(&sb, nil, children, nodes, 1)
+
+	if len(d.Edges) > 0 {
+		sb.WriteString("\n")
+	}
+	for _, e := range d.Edges {
+		var attrs []string
+		if e.Color != "" {
+			attrs = append(attrs, fmt.Sprintf("color=\"%s\"", dotQuote(e.Color)))
+		}
+		if e.Width > 0 {
+			attrs = append(attrs, fmt.Sprintf("penwidth=%d", e.Width))
+		}
+		if e.Label != "" {
+			attrs = append(attrs, fmt.Sprintf("label=\"%s\"", dotQuote(e.Label)))
+		}
+		sb.WriteString(fmt.Sprintf("    \"%s\" -> \"%s\"", dotQuote(e.From), dotQuote(e.To)))
+		if len(attrs) > 0 {
+			sb.WriteString(fmt.Sprintf(" [%s]", strings.Join(attrs, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func writeDiagramCluster(sb *strings.Builder, c *DiagramCluster, children map[string][]*DiagramCluster, nodes map[string][]*DiagramNode, indent int) {
+	prefix := strings.Repeat(tab, indent)
+
+	id := ""
+	if c != nil {
+		id = c.ID
+		sb.WriteString(fmt.Sprintf("%ssubgraph cluster_%s {\n", prefix, normalizePackageName(c.ID)))
+		sb.WriteString(fmt.Sprintf("%s%slabel = \"%s\"\n", prefix, tab, dotQuote(c.Label)))
+		if c.Color != "" {
+			sb.WriteString(fmt.Sprintf("%s%scolor = \"%s\"\n", prefix, tab, dotQuote(c.Color)))
+		}
+		prefix += tab
+	}
+
+	for _, n := range nodes[id] {
+		writeDiagramNode(sb, n, prefix)
+	}
+
+	for _, child := range children[id] {
+		writeDiagramCluster(sb, child, children, nodes, len(prefix)/len(tab))
+	}
+
+	if c != nil {
+		sb.WriteString(fmt.Sprintf("%s}\n", strings.Repeat(tab, indent)))
+	}
+}
+
+func writeDiagramNode(sb *strings.Builder, n *DiagramNode, prefix string) {
+	color := ""
+	if n.Color != "" {
+		color = fmt.Sprintf(", color=\"%s\"", dotQuote(n.Color))
+	}
+
+	if n.Sections == nil {
+		sb.WriteString(fmt.Sprintf("%s\"%s\" [label=\"%s\"%s]\n", prefix, dotQuote(n.ID), dotQuote(n.Label), color))
+		return
+	}
+
+	table := ""
+	if n.Color != "" {
+		table = fmt.Sprintf(" color=\"%s\"", xmlEscape(n.Color))
+	}
+
+	var rows strings.Builder
+	for s, section := range n.Sections {
+		rows.WriteString("<tr><td align=\"left\">")
+		for i, row := range section {
+			text := xmlEscape(row)
+			if s < len(n.RowColors) && i < len(n.RowColors[s]) && n.RowColors[s][i] != "" {
+				text = fmt.Sprintf("<font color=\"%s\">%s</font>", xmlEscape(n.RowColors[s][i]), text)
+			}
+			rows.WriteString(fmt.Sprintf("%s<br align=\"left\"/>", text))
+		}
+		rows.WriteString("</td></tr>")
+	}
+
+	sb.WriteString(fmt.Sprintf(
+		"%s\"%s\" [shape=plain%s, label=<<table border=\"0\" cellborder=\"1\" cellspacing=\"0\" cellpadding=\"3\"%s><tr><td><b>%s</b></td></tr>%s</table>>]\n",
+		prefix, dotQuote(n.ID), color, table, xmlEscape(n.Label), rows.String(),
+	))
+}
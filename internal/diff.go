@@ -0,0 +1,300 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+type DiffKind string
+
+const (
+	DiffAdded   DiffKind = "added"
+	DiffRemoved DiffKind = "removed"
+	DiffChanged DiffKind = "changed"
+)
+
+var diffMarkers = map[DiffKind]string{
+	DiffAdded:   "+",
+	DiffRemoved: "-",
+	DiffChanged: "~",
+}
+
+var diffColors = map[DiffKind]string{
+	DiffAdded:   Green,
+	DiffRemoved: Red,
+	DiffChanged: Yellow,
+}
+
+var diffDiagramColors = map[DiffKind]string{
+	DiffAdded:   "#2da44e",
+	DiffRemoved: "#cf222e",
+	DiffChanged: "#bf8700",
+}
+
+type DiffItem struct {
+	Kind DiffKind
+	Name string
+	Old  string
+	New  string
+}
+
+type StructDiff struct {
+	Name    string
+	Kind    DiffKind
+	Fields  []*DiffItem
+	Methods []*DiffItem
+}
+
+type PackageDiff struct {
+	Path    string
+	Kind    DiffKind
+	Imports []*DiffItem
+	Structs []*StructDiff
+}
+
+type diffPackage struct {
+	imports map[string]string
+	structs map[string]*diffStruct
+}
+
+type diffStruct struct {
+	fields  map[string]string
+	methods map[string]string
+}
+
+func diffSnapshot(directories map[string]*Directory) map[string]*diffPackage {
+	res := map[string]*diffPackage{}
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			if strings.HasSuffix(pkg.Name, "_test") {
+				continue
+			}
+
+			p := &diffPackage{imports: map[string]string{}, structs: map[string]*diffStruct{}}
+
+			for _, f := range pkg.Files {
+				if isTestFile(f.Path) {
+					continue
+				}
+				for _, i := range f.Imports {
+					p.imports[i.Path] = ""
+				}
+				for _, s := range f.Structs {
+					ds := &diffStruct{fields: map[string]string{}, methods: map[string]string{}}
+					blank := 0
+					for _, field := range s.Fields {
+						name := fieldName(field)
+						if name == "_" {
+							blank++
+							name = fmt.Sprintf("_#%d", blank)
+						}
+						ds.fields[name] = strings.TrimSpace(fmt.Sprintf("%s %s", field.Name, field.Type))
+					}
+					for _, m := range s.Methods {
+						ds.methods[methodName(m)] = strings.TrimSpace(m.Signature)
+					}
+					p.structs[s.Name] = ds
+				}
+			}
+
+			res[pkg.ModulePath] = p
+		}
+	}
+
+	return res
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := map[string]struct{}{}
+	for k := range a {
+		keys[k] = struct{}{}
+	}
+	for k := range b {
+		keys[k] = struct{}{}
+	}
+	return sortedKeys(keys)
+}
+
+func diffItems(before, after map[string]string) []*DiffItem {
+	var items []*DiffItem
+	for _, name := range unionKeys(before, after) {
+		was, inBefore := before[name]
+		now, inAfter := after[name]
+		switch {
+		case !inBefore:
+			items = append(items, &DiffItem{Kind: DiffAdded, Name: name, New: now})
+		case !inAfter:
+			items = append(items, &DiffItem{Kind: DiffRemoved, Name: name, Old: was})
+		case was != now:
+			items = append(items, &DiffItem{Kind: DiffChanged, Name: name, Old: was, New: now})
+		}
+	}
+	return items
+}
+
+func diffStructs(before, after map[string]*diffStruct) []*StructDiff {
+	empty := &diffStruct{}
+
+	var res []*StructDiff
+	for _, name := range unionKeys(before, after) {
+		was, inBefore := before[name]
+		now, inAfter := after[name]
+
+		kind := DiffChanged
+		switch {
+		case !inBefore:
+			kind, was = DiffAdded, empty
+		case !inAfter:
+			kind, now = DiffRemoved, empty
+		}
+
+		s := &StructDiff{
+			Name:    name,
+			Kind:    kind,
+			Fields:  diffItems(was.fields, now.fields),
+			Methods: diffItems(was.methods, now.methods),
+		}
+		if kind == DiffChanged && len(s.Fields) == 0 && len(s.Methods) == 0 {
+			continue
+		}
+		res = append(res, s)
+	}
+	return res
+}
+
+func DiffDirectories(before, after map[string]*Directory) []*PackageDiff {
+	was := diffSnapshot(before)
+	now := diffSnapshot(after)
+	empty := &diffPackage{}
+
+	var res []*PackageDiff
+	for _, p := range unionKeys(was, now) {
+		b, inBefore := was[p]
+		a, inAfter := now[p]
+
+		kind := DiffChanged
+		switch {
+		case !inBefore:
+			kind, b = DiffAdded, empty
+		case !inAfter:
+			kind, a = DiffRemoved, empty
+		}
+
+		d := &PackageDiff{
+			Path:    p,
+			Kind:    kind,
+			Imports: diffItems(b.imports, a.imports),
+			Structs: diffStructs(b.structs, a.structs),
+		}
+		if kind == DiffChanged && len(d.Imports) == 0 && len(d.Structs) == 0 {
+			continue
+		}
+		res = append(res, d)
+	}
+
+	return res
+}
+
+func formatDiffLine(kind DiffKind, indent int, text string) string {
+	return fmt.Sprintf("%s%s%s %s%s\n", strings.Repeat(tab, indent), diffColors[kind], diffMarkers[kind], text, NoColor)
+}
+
+func (i *DiffItem) String() string {
+	switch i.Kind {
+	case DiffAdded:
+		return i.New
+	case DiffRemoved:
+		return i.Old
+	}
+	return fmt.Sprintf("%s -> %s", i.Old, i.New)
+}
+
+func FormatDiff(diffs []*PackageDiff) string {
+	if len(diffs) == 0 {
+		return "no structural changes\n"
+	}
+
+	var sb strings.Builder
+	counts := map[DiffKind]int{}
+
+	for _, p := range diffs {
+		counts[p.Kind]++
+		sb.WriteString(formatDiffLine(p.Kind, 0, fmt.Sprintf("package %s", p.Path)))
+		for _, i := range p.Imports {
+			sb.WriteString(formatDiffLine(i.Kind, 1, fmt.Sprintf("import %s", i.Name)))
+		}
+		for _, s := range p.Structs {
+			sb.WriteString(formatDiffLine(s.Kind, 1, fmt.Sprintf("struct %s", s.Name)))
+			for _, f := range s.Fields {
+				sb.WriteString(formatDiffLine(f.Kind, 2, fmt.Sprintf("field %s", f)))
+			}
+			for _, m := range s.Methods {
+				sb.WriteString(formatDiffLine(m.Kind, 2, fmt.Sprintf("method %s", m)))
+			}
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf(
+		"\n%d added, %d removed, %d changed packages\n",
+		counts[DiffAdded], counts[DiffRemoved], counts[DiffChanged],
+	))
+
+	return sb.String()
+}
+
+func diffRows(items []*DiffItem) ([]string, []string) {
+	rows := []string{}
+	colors := []string{}
+	for _, item := range items {
+		rows = append(rows, fmt.Sprintf("%s %s", diffMarkers[item.Kind], item))
+		colors = append(colors, diffDiagramColors[item.Kind])
+	}
+	return rows, colors
+}
+
+func DiffDiagram(diffs []*PackageDiff, style *DiagramStyle) *Diagram {
+	d := &Diagram{Style: style}
+
+	nodes := map[string]struct{}{}
+	for _, p := range diffs {
+		nodes[p.Path] = struct{}{}
+	}
+
+	var targets []string
+	for _, p := range diffs {
+		color := diffDiagramColors[p.Kind]
+		d.Clusters = append(d.Clusters, &DiagramCluster{ID: p.Path, Label: p.Path, Color: color})
+		d.Nodes = append(d.Nodes, &DiagramNode{ID: p.Path, Label: path.Base(p.Path), Cluster: p.Path, Color: color})
+
+		for _, s := range p.Structs {
+			fields, fieldColors := diffRows(s.Fields)
+			methods, methodColors := diffRows(s.Methods)
+			d.Nodes = append(d.Nodes, &DiagramNode{
+				ID:        fmt.Sprintf("%s.%s", p.Path, s.Name),
+				Label:     s.Name,
+				Sections:  [][]string{fields, methods},
+				RowColors: [][]string{fieldColors, methodColors},
+				Cluster:   p.Path,
+				Color:     diffDiagramColors[s.Kind],
+			})
+		}
+
+		for _, i := range p.Imports {
+			if _, ok := nodes[i.Name]; !ok {
+				nodes[i.Name] = struct{}{}
+				targets = append(targets, i.Name)
+			}
+			d.Edges = append(d.Edges, &DiagramEdge{From: p.Path, To: i.Name, Color: diffDiagramColors[i.Kind]})
+		}
+	}
+
+	sort.Strings(targets)
+	for _, t := range targets {
+		d.Nodes = append(d.Nodes, &DiagramNode{ID: t, Label: t})
+	}
+
+	return d
+}
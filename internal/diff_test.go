@@ -0,0 +1,71 @@
+package internal_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func TestFormatDiff(t *testing.T) {
+	before := loadFixture(t, "testdata/diff/before", "example.com/diff", &internal.Config{})
+	after := loadFixture(t, "testdata/diff/after", "example.com/diff", &internal.Config{})
+	diffs := internal.DiffDirectories(before, after)
+
+	added := func(s string) string { return internal.Green + "+ " + s + internal.NoColor + "\n" }
+	removed := func(s string) string { return internal.Red + "- " + s + internal.NoColor + "\n" }
+	changed := func(s string) string { return internal.Yellow + "~ " + s + internal.NoColor + "\n" }
+
+	assertEqual(t, ""+
+		added("package example.com/diff/api")+
+		"    "+added("import example.com/diff/store")+
+		"    "+added("struct Server")+
+		"        "+added("field db *store.DB")+
+		removed("package example.com/diff/old")+
+		"    "+removed("struct Thing")+
+		"        "+removed("field Name string")+
+		changed("package example.com/diff/store")+
+		"    "+removed("import fmt")+
+		"    "+added("import strings")+
+		"    "+added("struct Cache")+
+		"        "+added("field items map[string]string")+
+		"    "+changed("struct DB")+
+		"        "+added("field cache map[string]string")+
+		"        "+changed("field size int -> size int64")+
+		"        "+removed("method Close() error")+
+		"        "+changed("method Get(string) string -> Get(string, string) string")+
+		"    "+changed("struct Header")+
+		"        "+changed("field _ [2]byte -> _ [6]byte")+
+		"        "+added("field _ [8]byte")+
+		"    "+removed("struct Legacy")+
+		"        "+removed("field ID int")+
+		"\n"+
+		"1 added, 1 removed, 1 changed packages\n",
+		internal.FormatDiff(diffs))
+
+	assertEqual(t, "no structural changes\n", internal.FormatDiff(internal.DiffDirectories(after, after)))
+}
+
+func TestDiffDiagram(t *testing.T) {
+	before := loadFixture(t, "testdata/diff/before", "example.com/diff", &internal.Config{})
+	after := loadFixture(t, "testdata/diff/after", "example.com/diff", &internal.Config{})
+	diffs := internal.DiffDirectories(before, after)
+	d := internal.DiffDiagram(diffs, nil)
+
+	dot := internal.FormatDiagram(d)
+	for _, expected := range []string{
+		"    subgraph cluster_example_com_diff_old {\n" +
+			"        label = \"example.com/diff/old\"\n" +
+			"        color = \"#cf222e\"\n",
+		"    \"example.com/diff/store\" -> \"fmt\" [color=\"#cf222e\"]\n",
+		"    \"example.com/diff/api\" -> \"example.com/diff/store\" [color=\"#2da44e\"]\n",
+		"<font color=\"#bf8700\">~ size int -&gt; size int64</font>",
+	} {
+		assertEqual(t, true, strings.Contains(dot, expected), expected)
+	}
+
+	groups := parseSVG(t, internal.FormatSVG(d))
+	assertEqual(t, 3, len(groups["cluster"]))
+	assertEqual(t, []string{"DB", "+ cache map[string]string", "~ size int -> size int64", "- Close() error", "~ Get(string) string -> Get(string, string) string"},
+		find(groups["node"], "example.com/diff/store.DB").Texts)
+}
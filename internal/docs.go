@@ -0,0 +1,351 @@
+package internal
+
+import (
+	"fmt"
+	"go/ast"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const DocsIndex = "README.md"
+
+type DocsPage struct {
+	Name    string
+	Content string
+}
+
+type docsModel struct {
+	module      string
+	config      *Config
+	opts        *DocsOptions
+	packages    []*Package
+	names       map[string]string
+	imports     map[string][]string
+	importers   map[string][]string
+	edges       []*ImportEdge
+	entrypoints []*Entrypoint
+}
+
+func DocsFileName(modulePath, module string) string {
+	dir, name := path.Split(relativeImport(modulePath, module))
+	switch {
+	case name == "":
+		name = "_root"
+	case strings.HasPrefix(name, "_"), dir == "" && strings.EqualFold(name, "README"):
+		name = "_" + name
+	}
+	return fmt.Sprintf("%s%s.md", dir, name)
+}
+
+func docsLink(from, to string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(path.Dir(from)), filepath.FromSlash(to))
+	if err != nil {
+		return to
+	}
+	return filepath.ToSlash(rel)
+}
+
+type DocsOptions struct {
+	Unexported bool
+}
+
+func newDocsModel(directories map[string]*Directory, module string, config *Config, opts *DocsOptions) *docsModel {
+	m := &docsModel{
+		module:    module,
+		config:    config,
+		opts:      opts,
+		names:     map[string]string{},
+		imports:   map[string][]string{},
+		importers: map[string][]string{},
+	}
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			if strings.HasSuffix(pkg.Name, "_test") {
+				continue
+			}
+			sort.Sort(ByFilePath(pkg.Files))
+			m.packages = append(m.packages, pkg)
+			m.names[pkg.ModulePath] = DocsFileName(pkg.ModulePath, module)
+		}
+	}
+
+	edges, _ := FindImportEdges(directories, module, &Config{Requires: config.Requires}, &ImportsOptions{})
+	for _, e := range edges {
+		m.imports[e.From] = append(m.imports[e.From], e.To)
+		if _, ok := m.names[e.To]; ok {
+			m.importers[e.To] = append(m.importers[e.To], e.From)
+			m.edges = append(m.edges, e)
+		}
+	}
+
+	for _, e := range FindEntrypoints(directories) {
+		if e.Kind != EntrypointTestMain {
+			m.entrypoints = append(m.entrypoints, e)
+		}
+	}
+
+	return m
+}
+
+func (m *docsModel) link(from, modulePath string) string {
+	if name, ok := m.names[modulePath]; ok {
+		return fmt.Sprintf("[%s](%s)", modulePath, docsLink(from, name))
+	}
+	return fmt.Sprintf("`%s`", modulePath)
+}
+
+func (m *docsModel) included(name string) bool {
+	return m.opts.Unexported || ast.IsExported(name)
+}
+
+func synopsis(doc string) string {
+	doc = strings.Join(strings.Fields(doc), " ")
+	if idx := strings.Index(doc, ". "); idx >= 0 {
+		return doc[:idx+1]
+	}
+	return doc
+}
+
+func writeMermaid(sb *strings.Builder, edges []*ImportEdge, highlight string) {
+	ids := map[string]string{}
+	var nodes []string
+	for _, e := range edges {
+		for _, n := range []string{e.From, e.To} {
+			if _, ok := ids[n]; !ok {
+				ids[n] = ""
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	sort.Strings(nodes)
+
+	sb.WriteString("```mermaid\n")
+	sb.WriteString("graph LR\n")
+	for i, n := range nodes {
+		ids[n] = fmt.Sprintf("n%d", i)
+		sb.WriteString(fmt.Sprintf("    %s[\"%s\"]\n", ids[n], n))
+	}
+	for _, e := range edges {
+		sb.WriteString(fmt.Sprintf("    %s --> %s\n", ids[e.From], ids[e.To]))
+	}
+	if id, ok := ids[highlight]; ok {
+		sb.WriteString(fmt.Sprintf("    style %s stroke-width:3px\n", id))
+	}
+	sb.WriteString("```\n")
+}
+
+func formatEntrypointDoc(e *Entrypoint) string {
+	line := fmt.Sprintf("`%s` %s in `%s`", e.Kind, e.Function.Name, filepath.Base(e.File.Path))
+	if e.Binary != "" {
+		line = fmt.Sprintf("%s, builds `%s`", line, e.Binary)
+	}
+	if len(e.Constraints) > 0 {
+		line = fmt.Sprintf("%s (%s)", line, strings.Join(e.Constraints, ","))
+	}
+	return line
+}
+
+func (m *docsModel) index() string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# %s\n\n", m.module))
+
+	sb.WriteString("## Packages\n\n")
+	sb.WriteString("| Package | Description |\n")
+	sb.WriteString("|---------|-------------|\n")
+	for _, pkg := range m.packages {
+		sb.WriteString(fmt.Sprintf("| %s | %s |\n", m.link(DocsIndex, pkg.ModulePath), synopsis(pkg.Doc)))
+	}
+
+	if len(m.entrypoints) > 0 {
+		sb.WriteString("\n## Entrypoints\n\n")
+		for _, e := range m.entrypoints {
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", m.link(DocsIndex, e.Package.ModulePath), formatEntrypointDoc(e)))
+		}
+	}
+
+	if len(m.edges) > 0 {
+		sb.WriteString("\n## Dependencies\n\n")
+		writeMermaid(&sb, m.edges, "")
+	}
+
+	return sb.String()
+}
+
+func (m *docsModel) page(pkg *Package) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# %s\n\n", pkg.ModulePath))
+	name := m.names[pkg.ModulePath]
+
+	sb.WriteString(fmt.Sprintf("[Index](%s)\n\n", docsLink(name, DocsIndex)))
+	sb.WriteString(fmt.Sprintf("`package %s`\n", pkg.Name))
+
+	if pkg.Doc != "" {
+		sb.WriteString(fmt.Sprintf("\n%s", pkg.Doc))
+	}
+
+	var entrypoints []*Entrypoint
+	for _, e := range m.entrypoints {
+		if e.Package == pkg {
+			entrypoints = append(entrypoints, e)
+		}
+	}
+	if len(entrypoints) > 0 {
+		sb.WriteString("\n## Entrypoints\n\n")
+		for _, e := range entrypoints {
+			sb.WriteString(fmt.Sprintf("- %s\n", formatEntrypointDoc(e)))
+		}
+	}
+
+	if imports := m.imports[pkg.ModulePath]; len(imports) > 0 {
+		sb.WriteString("\n## Imports\n\n")
+		for _, i := range imports {
+			sb.WriteString(fmt.Sprintf("- %s\n", m.link(name, i)))
+		}
+	}
+
+	if importers := m.importers[pkg.ModulePath]; len(importers) > 0 {
+		sb.WriteString("\n## Imported by\n\n")
+		for _, i := range importers {
+			sb.WriteString(fmt.Sprintf("- %s\n", m.link(name, i)))
+		}
+	}
+
+	var edges []*ImportEdge
+	for _, e := range m.edges {
+		if e.From == pkg.ModulePath || e.To == pkg.ModulePath {
+			edges = append(edges, e)
+		}
+	}
+	if len(edges) > 0 {
+		sb.WriteString("\n## Diagram\n\n")
+		writeMermaid(&sb, edges, pkg.ModulePath)
+	}
+
+	m.writeTypes(&sb, pkg)
+	m.writeFunctions(&sb, pkg)
+
+	return sb.String()
+}
+
+func (m *docsModel) writeTypes(sb *strings.Builder, pkg *Package) {
+	var types strings.Builder
+
+	for _, f := range pkg.Files {
+		if isTestFile(f.Path) {
+			continue
+		}
+
+		sort.Sort(ByStructName(f.Structs))
+
+		for _, s := range f.Structs {
+			if !m.included(s.Name) {
+				continue
+			}
+			types.WriteString(fmt.Sprintf("\n### %s\n\n", s.Name))
+			types.WriteString("```go\n")
+			types.WriteString(fmt.Sprintf("type %s struct {\n", s.Name))
+			var fields []*Field
+			max := 0
+			for _, field := range s.Fields {
+				if !m.included(fieldName(field)) {
+					continue
+				}
+				fields = append(fields, field)
+				if len(field.Name) > max {
+					max = len(field.Name)
+				}
+			}
+			for _, field := range fields {
+				if field.Name == "" {
+					types.WriteString(fmt.Sprintf("\t%s\n", field.Type))
+					continue
+				}
+				types.WriteString(fmt.Sprintf("\t%-*s %s\n", max, field.Name, field.Type))
+			}
+			types.WriteString("}\n")
+			types.WriteString("```\n")
+
+			var methods []string
+			for _, method := range s.Methods {
+				if m.included(methodName(method)) {
+					methods = append(methods, fmt.Sprintf("- `%s`\n", strings.TrimSpace(method.Signature)))
+				}
+			}
+			if len(methods) > 0 {
+				types.WriteString("\nMethods:\n\n")
+				types.WriteString(strings.Join(methods, ""))
+			}
+		}
+
+		for _, i := range f.Interfaces {
+			if !m.included(i.Name) {
+				continue
+			}
+			types.WriteString(fmt.Sprintf("\n### %s\n\n", i.Name))
+			types.WriteString("```go\n")
+			types.WriteString(fmt.Sprintf("type %s interface {\n", i.Name))
+			for _, e := range i.Embedded {
+				types.WriteString(fmt.Sprintf("\t%s\n", e))
+			}
+			for _, method := range i.Methods {
+				types.WriteString(fmt.Sprintf("\t%s\n", strings.TrimSpace(method.Signature)))
+			}
+			types.WriteString("}\n")
+			types.WriteString("```\n")
+		}
+
+		for _, t := range f.Types {
+			if !m.included(t.Name) {
+				continue
+			}
+			assign := " "
+			if t.Alias {
+				assign = " = "
+			}
+			types.WriteString(fmt.Sprintf("\n### %s\n\n", t.Name))
+			types.WriteString(fmt.Sprintf("```go\ntype %s%s%s\n```\n", t.Name, assign, t.Type))
+		}
+	}
+
+	if types.Len() > 0 {
+		sb.WriteString("\n## Types\n")
+		sb.WriteString(types.String())
+	}
+}
+
+func (m *docsModel) writeFunctions(sb *strings.Builder, pkg *Package) {
+	var functions []string
+
+	for _, f := range pkg.Files {
+		if isTestFile(f.Path) {
+			continue
+		}
+		for _, fn := range f.Functions {
+			if fn.Name == "init" || (fn.Name == "main" && pkg.Name == "main") || !m.included(fn.Name) {
+				continue
+			}
+			functions = append(functions, fmt.Sprintf("- `func %s`\n", strings.TrimSpace(fn.Signature)))
+		}
+	}
+
+	if len(functions) > 0 {
+		sb.WriteString("\n## Functions\n\n")
+		sb.WriteString(strings.Join(functions, ""))
+	}
+}
+
+func GenerateDocs(directories map[string]*Directory, module string, config *Config, opts *DocsOptions) []*DocsPage {
+	m := newDocsModel(directories, module, config, opts)
+
+	pages := []*DocsPage{{Name: DocsIndex, Content: m.index()}}
+	for _, pkg := range m.packages {
+		pages = append(pages, &DocsPage{Name: m.names[pkg.ModulePath], Content: m.page(pkg)})
+	}
+
+	return pages
+}
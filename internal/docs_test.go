@@ -0,0 +1,137 @@
+package internal_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func TestGenerateDocs(t *testing.T) {
+	directories := loadFixture(t, "testdata/query", "example.com/query", &internal.Config{})
+	pages := internal.GenerateDocs(directories, "example.com/query", &internal.Config{}, &internal.DocsOptions{})
+
+	var names []string
+	contents := map[string]string{}
+	for _, p := range pages {
+		names = append(names, p.Name)
+		contents[p.Name] = p.Content
+	}
+	assertEqual(t, []string{"README.md", "api.md", "store.md", "web.md"}, names)
+
+	assertEqual(t, strings.Join([]string{
+		"# example.com/query",
+		"",
+		"## Packages",
+		"",
+		"| Package | Description |",
+		"|---------|-------------|",
+		"| [example.com/query/api](api.md) |  |",
+		"| [example.com/query/store](store.md) | Package store keeps database connections. |",
+		"| [example.com/query/web](web.md) |  |",
+		"",
+		"## Dependencies",
+		"",
+		"```mermaid",
+		"graph LR",
+		`    n0["example.com/query/store"]`,
+		`    n1["example.com/query/web"]`,
+		"    n1 --> n0",
+		"```",
+		"",
+	}, "\n"), contents["README.md"])
+
+	assertEqual(t, strings.Join([]string{
+		"# example.com/query/store",
+		"",
+		"[Index](README.md)",
+		"",
+		"`package store`",
+		"",
+		"Package store keeps database connections. It is safe for concurrent use.",
+		"",
+		"Connections are closed with DB.Close.",
+		"",
+		"## Imports",
+		"",
+		"- `database/sql`",
+		"- `sync`",
+		"",
+		"## Imported by",
+		"",
+		"- [example.com/query/web](web.md)",
+		"",
+		"## Diagram",
+		"",
+		"```mermaid",
+		"graph LR",
+		`    n0["example.com/query/store"]`,
+		`    n1["example.com/query/web"]`,
+		"    n1 --> n0",
+		"    style n0 stroke-width:3px",
+		"```",
+		"",
+		"## Types",
+		"",
+		"### DB",
+		"",
+		"```go",
+		"type DB struct {",
+		"\tsync.Mutex",
+		"\tName string",
+		"}",
+		"```",
+		"",
+		"Methods:",
+		"",
+		"- `Close() error`",
+		"",
+	}, "\n"), contents["store.md"])
+}
+
+func TestGenerateDocsUnexported(t *testing.T) {
+	directories := loadFixture(t, "testdata/query", "example.com/query", &internal.Config{})
+	pages := internal.GenerateDocs(directories, "example.com/query", &internal.Config{}, &internal.DocsOptions{Unexported: true})
+
+	for _, p := range pages {
+		if p.Name != "store.md" {
+			continue
+		}
+		assertEqual(t, true, strings.Contains(p.Content, "\tconn *sql.DB\n"))
+		assertEqual(t, true, strings.Contains(p.Content, "### cache\n"))
+	}
+}
+
+func TestDocsFileName(t *testing.T) {
+	for _, tc := range []struct {
+		modulePath string
+		expected   string
+	}{
+		{"example.com/app", "_root.md"},
+		{"example.com/app/app", "app.md"},
+		{"example.com/app/a/b-c", "a/b-c.md"},
+		{"example.com/app/a-b/c", "a-b/c.md"},
+		{"example.com/app/README", "_README.md"},
+		{"example.com/app/readme", "_readme.md"},
+		{"example.com/app/docs/README", "docs/README.md"},
+		{"example.com/app/_root", "__root.md"},
+	} {
+		assertEqual(t, tc.expected, internal.DocsFileName(tc.modulePath, "example.com/app"), tc.modulePath)
+	}
+}
+
+func TestGenerateDocsNested(t *testing.T) {
+	directories := loadFixture(t, "testdata/imports", "example.com/imports", &internal.Config{})
+
+	pages := internal.GenerateDocs(directories, "example.com/imports", &internal.Config{}, &internal.DocsOptions{})
+
+	contents := map[string]string{}
+	for _, p := range pages {
+		contents[p.Name] = p.Content
+	}
+
+	assertEqual(t, true, strings.Contains(contents["README.md"], "| [example.com/imports/internal/store/sql](internal/store/sql.md) |"))
+	assertEqual(t, true, strings.Contains(contents["internal/api.md"], "[Index](../README.md)\n"))
+	assertEqual(t, true, strings.Contains(contents["internal/api.md"], "- [example.com/imports/internal/store/sql](store/sql.md)\n"))
+	assertEqual(t, true, strings.Contains(contents["internal/api.md"], "- [example.com/imports/cmd/app](../cmd/app.md)\n"))
+}
@@ -0,0 +1,380 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+type EmbeddedType struct {
+	Type     string
+	Path     string
+	Struct   *Struct
+	Cycle    bool
+	External bool
+	Children []*EmbeddedType
+	methods  []*Method
+}
+
+func (e *EmbeddedType) Resolved() bool {
+	return e.Struct != nil
+}
+
+type Promoted struct {
+	Name      string
+	Field     *Field
+	Method    *Method
+	Origin    string
+	Selector  string
+	Depth     int
+	Ambiguous []string
+}
+
+func (p *Promoted) Position() Position {
+	if p.Method != nil {
+		return p.Method.Position
+	}
+	return p.Field.Position
+}
+
+type Embedding struct {
+	Struct   *Struct
+	Embedded []*EmbeddedType
+	Promoted []*Promoted
+}
+
+type structEntry struct {
+	pkg    *Package
+	file   *File
+	strukt *Struct
+}
+
+type TypeIndex struct {
+	byPath    map[string][]*Package
+	byPackage map[*Package]map[string]*structEntry
+	methods   map[*Package]map[string][]*Method
+}
+
+func NewTypeIndex(directories map[string]*Directory) *TypeIndex {
+	index := &TypeIndex{
+		byPath:    map[string][]*Package{},
+		byPackage: map[*Package]map[string]*structEntry{},
+		methods:   map[*Package]map[string][]*Method{},
+	}
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			index.byPath[pkg.ModulePath] = append(index.byPath[pkg.ModulePath], pkg)
+			index.byPackage[pkg] = map[string]*structEntry{}
+			index.methods[pkg] = map[string][]*Method{}
+			for _, f := range pkg.Files {
+				for _, s := range f.Structs {
+					index.byPackage[pkg][s.Name] = &structEntry{pkg: pkg, file: f, strukt: s}
+				}
+				for _, m := range f.Methods {
+					index.methods[pkg][m.Receiver] = append(index.methods[pkg][m.Receiver], m)
+				}
+			}
+		}
+	}
+
+	return index
+}
+
+func (index *TypeIndex) Embedding(pkg *Package, f *File, s *Struct) *Embedding {
+	entry := &structEntry{pkg: pkg, file: f, strukt: s}
+
+	embedding := &Embedding{
+		Struct:   s,
+		Embedded: index.embedded(entry, map[*Struct]struct{}{s: {}}),
+	}
+	embedding.Promoted = promote(s, index.methods[pkg][s.Name], embedding.Embedded)
+
+	return embedding
+}
+
+func (index *TypeIndex) embedded(entry *structEntry, visited map[*Struct]struct{}) []*EmbeddedType {
+	var res []*EmbeddedType
+
+	for _, field := range entry.strukt.Fields {
+		if field.Name != "" {
+			continue
+		}
+
+		e := &EmbeddedType{Type: field.Type}
+
+		found := index.resolve(entry, field.Type)
+		if found != nil {
+			e.Path = found.pkg.ModulePath
+			if _, ok := visited[found.strukt]; ok {
+				e.Cycle = true
+			} else {
+				e.Struct = found.strukt
+				e.methods = index.methods[found.pkg][found.strukt.Name]
+				visited[found.strukt] = struct{}{}
+				e.Children = index.embedded(found, visited)
+				delete(visited, found.strukt)
+			}
+		} else if qualifier, _, ok := splitQualified(field.Type); ok {
+			e.Path = index.importPath(entry.file, qualifier)
+			_, inModule := index.byPath[e.Path]
+			e.External = e.Path != "" && !inModule
+		}
+
+		res = append(res, e)
+	}
+
+	return res
+}
+
+func (index *TypeIndex) resolve(entry *structEntry, typ string) *structEntry {
+	qualifier, name, ok := splitQualified(typ)
+	if !ok {
+		return index.byPackage[entry.pkg][name]
+	}
+
+	importPath := index.importPath(entry.file, qualifier)
+	if importPath == "" {
+		return nil
+	}
+
+	for _, pkg := range index.byPath[importPath] {
+		if pkg.Name == "main" || strings.HasSuffix(pkg.Name, "_test") {
+			continue
+		}
+		if found, ok := index.byPackage[pkg][name]; ok {
+			return found
+		}
+	}
+
+	return nil
+}
+
+func (index *TypeIndex) importPath(f *File, qualifier string) string {
+	for _, i := range f.Imports {
+		if i.Name == qualifier {
+			return i.Path
+		}
+	}
+
+	for _, i := range f.Imports {
+		if i.Name != "" {
+			continue
+		}
+		for _, pkg := range index.byPath[i.Path] {
+			if pkg.Name == qualifier {
+				return i.Path
+			}
+		}
+	}
+
+	for _, i := range f.Imports {
+		if i.Name == "" && path.Base(i.Path) == qualifier {
+			return i.Path
+		}
+	}
+
+	return ""
+}
+
+func splitQualified(typ string) (string, string, bool) {
+	typ = strings.TrimPrefix(typ, "*")
+	if idx := strings.Index(typ, "["); idx >= 0 {
+		typ = typ[:idx]
+	}
+	if idx := strings.Index(typ, "."); idx >= 0 {
+		return typ[:idx], typ[idx+1:], true
+	}
+	return "", typ, false
+}
+
+func embeddedName(typ string) string {
+	_, name, _ := splitQualified(typ)
+	return name
+}
+
+func methodName(m *Method) string {
+	if idx := strings.Index(m.Signature, "("); idx >= 0 {
+		return m.Signature[:idx]
+	}
+	return m.Signature
+}
+
+type promotionCandidate struct {
+	field    *Field
+	method   *Method
+	origin   string
+	selector string
+}
+
+type promotionLevel struct {
+	embedded *EmbeddedType
+	selector string
+}
+
+func promote(s *Struct, methods []*Method, embedded []*EmbeddedType) []*Promoted {
+	var promoted []*Promoted
+
+	seen := map[string]struct{}{}
+	for _, f := range s.Fields {
+		if f.Name == "" {
+			seen[embeddedName(f.Type)] = struct{}{}
+			continue
+		}
+		seen[f.Name] = struct{}{}
+	}
+	for _, m := range methods {
+		seen[methodName(m)] = struct{}{}
+	}
+
+	level := make([]promotionLevel, 0, len(embedded))
+	for _, e := range embedded {
+		level = append(level, promotionLevel{embedded: e, selector: embeddedName(e.Type)})
+	}
+
+	for depth := 1; len(level) > 0; depth++ {
+		candidates := map[string][]promotionCandidate{}
+		var next []promotionLevel
+
+		for _, l := range level {
+			if !l.embedded.Resolved() {
+				continue
+			}
+
+			for _, f := range l.embedded.Struct.Fields {
+				name := f.Name
+				if name == "" {
+					name = embeddedName(f.Type)
+				}
+				candidates[name] = append(candidates[name], promotionCandidate{
+					field:    f,
+					origin:   l.embedded.Type,
+					selector: fmt.Sprintf("%s.%s", l.selector, name),
+				})
+			}
+
+			for _, m := range l.embedded.methods {
+				name := methodName(m)
+				candidates[name] = append(candidates[name], promotionCandidate{
+					method:   m,
+					origin:   l.embedded.Type,
+					selector: fmt.Sprintf("%s.%s", l.selector, name),
+				})
+			}
+
+			for _, child := range l.embedded.Children {
+				next = append(next, promotionLevel{
+					embedded: child,
+					selector: fmt.Sprintf("%s.%s", l.selector, embeddedName(child.Type)),
+				})
+			}
+		}
+
+		names := make([]string, 0, len(candidates))
+		for name := range candidates {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if _, ok := seen[name]; ok {
+				continue
+			}
+
+			c := candidates[name]
+			p := &Promoted{
+				Name:     name,
+				Field:    c[0].field,
+				Method:   c[0].method,
+				Origin:   c[0].origin,
+				Selector: c[0].selector,
+				Depth:    depth,
+			}
+
+			if len(c) > 1 {
+				for _, candidate := range c {
+					p.Ambiguous = append(p.Ambiguous, candidate.selector)
+				}
+			}
+
+			promoted = append(promoted, p)
+		}
+
+		for _, name := range names {
+			seen[name] = struct{}{}
+		}
+
+		level = next
+	}
+
+	sort.SliceStable(promoted, func(i, j int) bool {
+		if promoted[i].Depth != promoted[j].Depth {
+			return promoted[i].Depth < promoted[j].Depth
+		}
+		if (promoted[i].Method == nil) != (promoted[j].Method == nil) {
+			return promoted[i].Method == nil
+		}
+		return promoted[i].Name < promoted[j].Name
+	})
+
+	return promoted
+}
+
+func formatPromoted(p *Promoted, positions bool) string {
+	pos := p.Position()
+
+	if len(p.Ambiguous) > 0 {
+		return fmt.Sprintf(
+			"%s    %s!%s %s %sambiguous: %s%s\n",
+			formatPosition(positions, pos), Red, NoColor, p.Name, Red, strings.Join(p.Ambiguous, ", "), NoColor,
+		)
+	}
+
+	var member string
+	if p.Method != nil {
+		member = strings.TrimSpace(p.Method.Signature)
+	} else if p.Field.Name == "" {
+		member = p.Field.Type
+	} else {
+		member = fmt.Sprintf("%s %s", p.Field.Name, p.Field.Type)
+	}
+
+	return fmt.Sprintf(
+		"%s    %s%s %s(%s)%s\n",
+		formatPosition(positions, pos), formatTokenVisibility(p.Name), member, Cyan, p.Selector, NoColor,
+	)
+}
+
+func formatEmbeddingTree(e *Embedding) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("%s\n", e.Struct.Name))
+	formatEmbeddedChildren(&sb, e.Embedded, "")
+
+	return sb.String()
+}
+
+func formatEmbeddedChildren(sb *strings.Builder, embedded []*EmbeddedType, prefix string) {
+	for i, e := range embedded {
+		branch, indent := "├── ", "│   "
+		if i == len(embedded)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		note := ""
+		if e.Cycle {
+			note = fmt.Sprintf(" %s(cycle)%s", Red, NoColor)
+		} else if e.External {
+			note = fmt.Sprintf(" %s(external)%s", Purple, NoColor)
+		} else if !e.Resolved() {
+			note = fmt.Sprintf(" %s(unresolved)%s", Red, NoColor)
+		}
+		if e.Path != "" {
+			note = fmt.Sprintf(" %s%s%s%s", Yellow, e.Path, NoColor, note)
+		}
+
+		sb.WriteString(fmt.Sprintf("%s%s%s%s\n", prefix, branch, e.Type, note))
+		formatEmbeddedChildren(sb, e.Children, prefix+indent)
+	}
+}
@@ -0,0 +1,151 @@
+package internal_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func findStruct(pkg *internal.Package, name string) (*internal.File, *internal.Struct) {
+	for _, f := range pkg.Files {
+		for _, s := range f.Structs {
+			if s.Name == name {
+				return f, s
+			}
+		}
+	}
+	return nil, nil
+}
+
+func formatPromotedNames(embedding *internal.Embedding) []string {
+	var res []string
+	for _, p := range embedding.Promoted {
+		if len(p.Ambiguous) > 0 {
+			res = append(res, fmt.Sprintf("%d %s ambiguous %v", p.Depth, p.Name, p.Ambiguous))
+			continue
+		}
+		res = append(res, fmt.Sprintf("%d %s %s", p.Depth, p.Name, p.Selector))
+	}
+	return res
+}
+
+func TestEmbeddingPromotion(t *testing.T) {
+	directories := loadFixture(t, "testdata/embedding", "example.com/embedding", &internal.Config{})
+	pkg := directories["testdata/embedding"].Packages["embedding"]
+	index := internal.NewTypeIndex(directories)
+
+	f, s := findStruct(pkg, "C")
+	embedding := index.Embedding(pkg, f, s)
+
+	assertEqual(t, []string{
+		"1 CreatedAt Model.CreatedAt",
+		"1 ID A.ID",
+		"1 Name ambiguous [A.Name B.Name]",
+		"1 Hello ambiguous [A.Hello B.Hello]",
+		"1 Touch Model.Touch",
+	}, formatPromotedNames(embedding))
+
+	assertEqual(t, 3, len(embedding.Embedded))
+	assertEqual(t, "example.com/embedding/shared", embedding.Embedded[2].Path)
+	assertEqual(t, true, embedding.Embedded[2].Resolved())
+}
+
+func TestEmbeddingPromotionGenerics(t *testing.T) {
+	directories := loadFixture(t, "testdata/embedding", "example.com/embedding", &internal.Config{})
+	pkg := directories["testdata/embedding"].Packages["embedding"]
+	index := internal.NewTypeIndex(directories)
+
+	f, s := findStruct(pkg, "E")
+	embedding := index.Embedding(pkg, f, s)
+
+	assertEqual(t, []string{
+		"1 Items List.Items",
+		"1 Value Base.Value",
+		"1 Get Base.Get",
+		"1 Len List.Len",
+	}, formatPromotedNames(embedding))
+
+	assertEqual(t, "example.com/embedding", embedding.Embedded[0].Path)
+	assertEqual(t, "example.com/embedding/shared", embedding.Embedded[1].Path)
+	assertEqual(t, true, embedding.Embedded[1].Resolved())
+}
+
+func TestEmbeddingPromotionDepth(t *testing.T) {
+	directories := loadFixture(t, "testdata/embedding", "example.com/embedding", &internal.Config{})
+	pkg := directories["testdata/embedding"].Packages["embedding"]
+	index := internal.NewTypeIndex(directories)
+
+	f, s := findStruct(pkg, "D")
+	embedding := index.Embedding(pkg, f, s)
+
+	assertEqual(t, []string{
+		"1 A C.A",
+		"1 B C.B",
+		"1 Model C.Model",
+		"1 Own C.Own",
+		"2 CreatedAt C.Model.CreatedAt",
+		"2 Name ambiguous [C.A.Name C.B.Name]",
+		"2 Hello ambiguous [C.A.Hello C.B.Hello]",
+		"2 Touch C.Model.Touch",
+	}, formatPromotedNames(embedding))
+
+	mutex := embedding.Embedded[1]
+	assertEqual(t, "sync", mutex.Path)
+	assertEqual(t, true, mutex.External)
+	assertEqual(t, false, mutex.Resolved())
+}
+
+func TestEmbeddingCycle(t *testing.T) {
+	directories := loadFixture(t, "testdata/embedding", "example.com/embedding", &internal.Config{})
+	pkg := directories["testdata/embedding"].Packages["embedding"]
+	index := internal.NewTypeIndex(directories)
+
+	f, s := findStruct(pkg, "Loop")
+	embedding := index.Embedding(pkg, f, s)
+
+	assertEqual(t, 1, len(embedding.Embedded))
+	assertEqual(t, true, embedding.Embedded[0].Cycle)
+	assertEqual(t, 0, len(embedding.Promoted))
+}
+
+func TestFormatTypesEmbedded(t *testing.T) {
+	directories := loadFixture(t, "testdata/embedding", "example.com/embedding", &internal.Config{})
+
+	actual := internal.FormatTypes(directories, "example.com/embedding", &internal.Config{}, &internal.TypesOptions{Embedded: true})
+
+	expected := "" +
+		"__GREEN__+__NOCOLOR__ type __BLUE__D__NOCOLOR__ {\n" +
+		"    __RED__-__NOCOLOR__ sync.Mutex\n" +
+		"    __GREEN__+__NOCOLOR__ C\n" +
+		"    __GREEN__+__NOCOLOR__ ID int\n" +
+		"\n" +
+		"    __GREEN__+__NOCOLOR__ A __CYAN__(C.A)__NOCOLOR__\n" +
+		"    __GREEN__+__NOCOLOR__ *B __CYAN__(C.B)__NOCOLOR__\n" +
+		"    __GREEN__+__NOCOLOR__ base.Model __CYAN__(C.Model)__NOCOLOR__\n" +
+		"    __GREEN__+__NOCOLOR__ Own string __CYAN__(C.Own)__NOCOLOR__\n" +
+		"    __GREEN__+__NOCOLOR__ CreatedAt int64 __CYAN__(C.Model.CreatedAt)__NOCOLOR__\n" +
+		"    __RED__!__NOCOLOR__ Name __RED__ambiguous: C.A.Name, C.B.Name__NOCOLOR__\n" +
+		"    __RED__!__NOCOLOR__ Hello __RED__ambiguous: C.A.Hello, C.B.Hello__NOCOLOR__\n" +
+		"    __GREEN__+__NOCOLOR__ Touch() __CYAN__(C.Model.Touch)__NOCOLOR__\n" +
+		"}\n" +
+		"D\n" +
+		"├── C __YELLOW__example.com/embedding__NOCOLOR__\n" +
+		"│   ├── A __YELLOW__example.com/embedding__NOCOLOR__\n" +
+		"│   ├── *B __YELLOW__example.com/embedding__NOCOLOR__\n" +
+		"│   └── base.Model __YELLOW__example.com/embedding/shared__NOCOLOR__\n" +
+		"└── sync.Mutex __YELLOW__sync__NOCOLOR__ __PURPLE__(external)__NOCOLOR__\n"
+
+	expected = strings.ReplaceAll(expected, "__GREEN__", internal.Green)
+	expected = strings.ReplaceAll(expected, "__YELLOW__", internal.Yellow)
+	expected = strings.ReplaceAll(expected, "__RED__", internal.Red)
+	expected = strings.ReplaceAll(expected, "__BLUE__", internal.Blue)
+	expected = strings.ReplaceAll(expected, "__CYAN__", internal.Cyan)
+	expected = strings.ReplaceAll(expected, "__PURPLE__", internal.Purple)
+	expected = strings.ReplaceAll(expected, "__NOCOLOR__", internal.NoColor)
+
+	if !strings.Contains(actual, expected) {
+		t.Errorf("expected output to contain:\n%s\nactual:\n%s", expected, actual)
+	}
+}
@@ -0,0 +1,201 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type EntrypointKind string
+
+const (
+	EntrypointMain     EntrypointKind = "main"
+	EntrypointGated    EntrypointKind = "gated"
+	EntrypointTool     EntrypointKind = "tool"
+	EntrypointTestMain EntrypointKind = "testmain"
+	EntrypointInit     EntrypointKind = "init"
+)
+
+var entrypointKindOrder = map[EntrypointKind]int{
+	EntrypointMain:     0,
+	EntrypointGated:    1,
+	EntrypointTool:     2,
+	EntrypointTestMain: 3,
+	EntrypointInit:     4,
+}
+
+type Entrypoint struct {
+	Kind        EntrypointKind
+	Binary      string
+	Package     *Package
+	File        *File
+	Function    *Function
+	Constraints []string
+}
+
+func (e *Entrypoint) IsBinary() bool {
+	return e.Kind == EntrypointMain || e.Kind == EntrypointGated || e.Kind == EntrypointTool
+}
+
+func FindEntrypoints(directories map[string]*Directory) []*Entrypoint {
+	var entrypoints []*Entrypoint
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			var found []*Entrypoint
+
+			sort.Sort(ByFilePath(pkg.Files))
+
+			for _, f := range pkg.Files {
+				for _, fn := range f.Functions {
+					e := &Entrypoint{
+						Package:     pkg,
+						File:        f,
+						Function:    fn,
+						Constraints: fileConstraints(f),
+					}
+
+					switch {
+					case fn.Name == "main" && pkg.Name == "main" && !isTestFile(f.Path):
+						e.Kind = EntrypointMain
+						if isToolPackage(pkg.ModulePath) {
+							e.Kind = EntrypointTool
+						} else if len(e.Constraints) > 0 {
+							e.Kind = EntrypointGated
+						}
+						e.Binary = binaryName(pkg.ModulePath)
+					case fn.Name == "TestMain" && isTestFile(f.Path):
+						e.Kind = EntrypointTestMain
+						e.Binary = fmt.Sprintf("%s.test", strings.TrimSuffix(pkg.Name, "_test"))
+					case fn.Name == "init":
+						e.Kind = EntrypointInit
+					default:
+						continue
+					}
+
+					found = append(found, e)
+				}
+			}
+
+			sort.SliceStable(found, func(i, j int) bool {
+				return entrypointKindOrder[found[i].Kind] < entrypointKindOrder[found[j].Kind]
+			})
+
+			entrypoints = append(entrypoints, found...)
+		}
+	}
+
+	return entrypoints
+}
+
+var majorVersionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+func binaryName(modulePath string) string {
+	name := path.Base(modulePath)
+	if majorVersionSuffix.MatchString(name) {
+		name = path.Base(path.Dir(modulePath))
+	}
+	return name
+}
+
+func isToolPackage(modulePath string) bool {
+	return path.Base(path.Dir(modulePath)) == "cmd"
+}
+
+func isTestFile(p string) bool {
+	return strings.HasSuffix(p, "_test.go")
+}
+
+var knownOS = map[string]struct{}{
+	"aix": {}, "android": {}, "darwin": {}, "dragonfly": {}, "freebsd": {},
+	"hurd": {}, "illumos": {}, "ios": {}, "js": {}, "linux": {}, "nacl": {},
+	"netbsd": {}, "openbsd": {}, "plan9": {}, "solaris": {}, "wasip1": {},
+	"windows": {}, "zos": {},
+}
+
+var knownArch = map[string]struct{}{
+	"386": {}, "amd64": {}, "arm": {}, "arm64": {}, "loong64": {}, "mips": {},
+	"mips64": {}, "mips64le": {}, "mipsle": {}, "ppc64": {}, "ppc64le": {},
+	"riscv64": {}, "s390x": {}, "wasm": {},
+}
+
+func fileNameConstraints(p string) []string {
+	name := strings.TrimSuffix(filepath.Base(p), ".go")
+	name = strings.TrimSuffix(name, "_test")
+
+	parts := strings.Split(name, "_")
+	if len(parts) < 2 {
+		return nil
+	}
+
+	last := parts[len(parts)-1]
+	if _, ok := knownArch[last]; ok {
+		if len(parts) >= 3 {
+			if _, ok := knownOS[parts[len(parts)-2]]; ok {
+				return []string{parts[len(parts)-2], last}
+			}
+		}
+		return []string{last}
+	}
+	if _, ok := knownOS[last]; ok {
+		return []string{last}
+	}
+
+	return nil
+}
+
+func fileConstraints(f *File) []string {
+	var constraints []string
+	constraints = append(constraints, f.BuildConstraints...)
+	constraints = append(constraints, fileNameConstraints(f.Path)...)
+	return constraints
+}
+
+type EntrypointsOptions struct {
+	Positions bool
+}
+
+func FormatEntrypoints(directories map[string]*Directory, module string, config *Config, opts *EntrypointsOptions) string {
+	var sb strings.Builder
+
+	entrypoints := FindEntrypoints(directories)
+
+	maxKind, maxBinary, maxPath := 0, 0, 0
+	for _, e := range entrypoints {
+		if len(e.Kind) > maxKind {
+			maxKind = len(e.Kind)
+		}
+		if len(formatBinary(e)) > maxBinary {
+			maxBinary = len(formatBinary(e))
+		}
+		if len(e.Package.ModulePath) > maxPath {
+			maxPath = len(e.Package.ModulePath)
+		}
+	}
+
+	for _, e := range entrypoints {
+		line := fmt.Sprintf(
+			"%s%-*s %s%-*s%s %-*s",
+			formatPosition(opts.Positions, e.Function.Position),
+			maxKind, e.Kind,
+			Blue, maxBinary, formatBinary(e), NoColor,
+			maxPath, e.Package.ModulePath,
+		)
+		if len(e.Constraints) > 0 {
+			line = fmt.Sprintf("%s %s%s%s", line, Red, strings.Join(e.Constraints, ","), NoColor)
+		}
+		sb.WriteString(fmt.Sprintf("%s\n", strings.TrimRight(line, " ")))
+	}
+
+	return sb.String()
+}
+
+func formatBinary(e *Entrypoint) string {
+	if e.Binary == "" {
+		return "-"
+	}
+	return e.Binary
+}
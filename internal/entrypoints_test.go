@@ -0,0 +1,107 @@
+package internal_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func TestFindEntrypoints(t *testing.T) {
+	directories := loadFixture(t, "testdata/entrypoints", "example.com/entrypoints", &internal.Config{IncludeTests: true})
+
+	var actual []string
+	for _, e := range internal.FindEntrypoints(directories) {
+		actual = append(actual, fmt.Sprintf(
+			"%s %s %s %s [%s]",
+			e.Kind, e.Binary, e.Package.ModulePath, e.Function.Position, strings.Join(e.Constraints, ","),
+		))
+	}
+
+	expected := []string{
+		"main entrypoints example.com/entrypoints testdata/entrypoints/server.go:5:6 []",
+		"main app example.com/entrypoints/app/v2 testdata/entrypoints/app/v2/main.go:3:6 []",
+		"tool tool example.com/entrypoints/cmd/tool testdata/entrypoints/cmd/tool/tool.go:9:6 []",
+		"gated daemon example.com/entrypoints/daemon testdata/entrypoints/daemon/daemon_linux.go:5:6 [cgo,linux]",
+		"gated daemon example.com/entrypoints/daemon testdata/entrypoints/daemon/daemon_other.go:5:6 [!linux]",
+		"init  example.com/entrypoints/lib testdata/entrypoints/lib/main.go:12:6 []",
+		"testmain lib.test example.com/entrypoints/lib testdata/entrypoints/lib/lib_test.go:8:6 []",
+	}
+
+	assertEqual(t, expected, actual)
+}
+
+func TestFormatEntrypoints(t *testing.T) {
+	directories := loadFixture(t, "testdata/entrypoints", "example.com/entrypoints", &internal.Config{IncludeTests: true})
+
+	expected := "" +
+		"main     __BLUE__entrypoints__NOCOLOR__ example.com/entrypoints\n" +
+		"main     __BLUE__app        __NOCOLOR__ example.com/entrypoints/app/v2\n" +
+		"tool     __BLUE__tool       __NOCOLOR__ example.com/entrypoints/cmd/tool\n" +
+		"gated    __BLUE__daemon     __NOCOLOR__ example.com/entrypoints/daemon   __RED__cgo,linux__NOCOLOR__\n" +
+		"gated    __BLUE__daemon     __NOCOLOR__ example.com/entrypoints/daemon   __RED__!linux__NOCOLOR__\n" +
+		"init     __BLUE__-          __NOCOLOR__ example.com/entrypoints/lib\n" +
+		"testmain __BLUE__lib.test   __NOCOLOR__ example.com/entrypoints/lib\n"
+
+	expected = strings.ReplaceAll(expected, "__BLUE__", internal.Blue)
+	expected = strings.ReplaceAll(expected, "__RED__", internal.Red)
+	expected = strings.ReplaceAll(expected, "__NOCOLOR__", internal.NoColor)
+
+	assertEqual(t, expected, internal.FormatEntrypoints(directories, "example.com/entrypoints", &internal.Config{}, &internal.EntrypointsOptions{}))
+}
+
+func TestFormatStatsEntrypointsCount(t *testing.T) {
+	directories := loadFixture(t, "testdata/entrypoints", "example.com/entrypoints", &internal.Config{IncludeTests: true})
+
+	actual := internal.FormatStats(directories, "example.com/entrypoints")
+
+	if !strings.Contains(actual, " 4 | entrypoints count\n") {
+		t.Errorf("unexpected entrypoints count:\n%s", actual)
+	}
+}
+
+func TestFindDependencies(t *testing.T) {
+	directories := loadFixture(t, "testdata/entrypoints", "example.com/entrypoints", &internal.Config{IncludeTests: true})
+
+	config := &internal.Config{Requires: []string{"github.com/acme/widgets"}}
+
+	var actual []string
+	for _, deps := range internal.FindDependencies(directories, "example.com/entrypoints", config, &internal.DependenciesOptions{}) {
+		actual = append(actual, fmt.Sprintf(
+			"%s packages=%v modules=%v stdlib=%v",
+			deps.Entrypoint.Binary, deps.Packages, deps.Modules, deps.StdLib,
+		))
+	}
+
+	expected := []string{
+		"entrypoints packages=[example.com/entrypoints/lib example.com/entrypoints/shared] modules=[github.com/acme/widgets github.com/google/uuid] stdlib=[strings]",
+		"app packages=[] modules=[] stdlib=[]",
+		"tool packages=[example.com/entrypoints/shared] modules=[github.com/google/uuid] stdlib=[fmt]",
+		"daemon packages=[] modules=[] stdlib=[]",
+	}
+
+	assertEqual(t, expected, actual)
+}
+
+func TestFormatDependenciesDot(t *testing.T) {
+	directories := loadFixture(t, "testdata/entrypoints", "example.com/entrypoints", &internal.Config{IncludeTests: true})
+
+	expected := `digraph {
+    rankdir="LR"
+
+    subgraph cluster_example_com_entrypoints_cmd_tool {
+        label = "tool"
+        "tool|example.com/entrypoints/cmd/tool" [label="example.com/entrypoints/cmd/tool"]
+        "tool|example.com/entrypoints/shared" [label="example.com/entrypoints/shared"]
+        "tool|github.com/google/uuid" [label="github.com/google/uuid"]
+        "tool|example.com/entrypoints/cmd/tool" -> "tool|example.com/entrypoints/shared"
+        "tool|example.com/entrypoints/shared" -> "tool|github.com/google/uuid"
+    }
+}
+`
+
+	actual := internal.FormatDependenciesDot(directories, "example.com/entrypoints", &internal.Config{}, &internal.DependenciesOptions{Binary: "tool"})
+
+	assertEqual(t, expected, actual)
+}
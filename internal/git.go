@@ -0,0 +1,125 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+func git(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func ExtractRevision(dir, rev, dest string) (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", fmt.Errorf("git executable not found")
+	}
+
+	top, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+
+	prefix, err := git(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := git(top, "rev-parse", "--verify", "--quiet", fmt.Sprintf("%s^{commit}", rev)); err != nil {
+		return "", fmt.Errorf("unknown revision: %s", rev)
+	}
+
+	args := []string{"-C", top, "archive", "--format=tar", rev}
+	if prefix != "" {
+		args = append(args, "--", prefix)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+
+	extractErr := extractSources(stdout, dest)
+	io.Copy(io.Discard, stdout)
+
+	if err := cmd.Wait(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git archive: %s", msg)
+	}
+	if extractErr != nil {
+		return "", extractErr
+	}
+
+	return filepath.Join(dest, filepath.FromSlash(prefix)), nil
+}
+
+func extractSources(r io.Reader, dest string) error {
+	tr := tar.NewReader(r)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid path in archive: %s", header.Name)
+		}
+		if path.Ext(name) != ".go" && path.Base(name) != "go.mod" {
+			continue
+		}
+
+		target := filepath.Join(dest, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+
+		f, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(f, tr)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
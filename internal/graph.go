@@ -0,0 +1,152 @@
+package internal
+
+import (
+	"sort"
+	"strings"
+)
+
+type ImportKind int
+
+const (
+	ModuleImport ImportKind = iota
+	StdLibImport
+	ExternalImport
+)
+
+type ImportGraph struct {
+	Module   string
+	Requires []string
+	packages map[string][]*Package
+	imports  map[string]map[string]struct{}
+}
+
+func NewImportGraph(directories map[string]*Directory, module string, requires []string) *ImportGraph {
+	g := &ImportGraph{
+		Module:   module,
+		Requires: requires,
+		packages: map[string][]*Package{},
+		imports:  map[string]map[string]struct{}{},
+	}
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			g.packages[pkg.ModulePath] = append(g.packages[pkg.ModulePath], pkg)
+
+			if !isImportable(pkg) {
+				continue
+			}
+
+			if _, ok := g.imports[pkg.ModulePath]; !ok {
+				g.imports[pkg.ModulePath] = map[string]struct{}{}
+			}
+
+			for _, i := range packageImports(pkg, false) {
+				g.imports[pkg.ModulePath][i] = struct{}{}
+			}
+		}
+	}
+
+	return g
+}
+
+func isImportable(pkg *Package) bool {
+	return pkg.Name != "main" && !strings.HasSuffix(pkg.Name, "_test")
+}
+
+func packageImports(pkg *Package, includeTests bool) []string {
+	unique := map[string]struct{}{}
+	for _, f := range pkg.Files {
+		if !includeTests && isTestFile(f.Path) {
+			continue
+		}
+		for _, i := range f.Imports {
+			unique[i.Path] = struct{}{}
+		}
+	}
+	return sortedKeys(unique)
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (g *ImportGraph) Packages() []string {
+	paths := make([]string, 0, len(g.imports))
+	for p := range g.imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (g *ImportGraph) Contains(path string) bool {
+	_, ok := g.imports[path]
+	return ok
+}
+
+func (g *ImportGraph) Imports(path string) []string {
+	return sortedKeys(g.imports[path])
+}
+
+func (g *ImportGraph) Kind(path string) ImportKind {
+	if g.Contains(path) || (g.Module != "" && (path == g.Module || strings.HasPrefix(path, g.Module+"/"))) {
+		return ModuleImport
+	}
+	if isStdLib(path) || !strings.Contains(strings.Split(path, "/")[0], ".") {
+		return StdLibImport
+	}
+	return ExternalImport
+}
+
+func (g *ImportGraph) ExternalModule(path string) string {
+	longest := ""
+	for _, r := range g.Requires {
+		if (path == r || strings.HasPrefix(path, r+"/")) && len(r) > len(longest) {
+			longest = r
+		}
+	}
+	if longest != "" {
+		return longest
+	}
+
+	parts := strings.Split(path, "/")
+	switch parts[0] {
+	case "github.com", "gitlab.com", "bitbucket.org":
+		if len(parts) > 3 {
+			return strings.Join(parts[:3], "/")
+		}
+	case "gopkg.in":
+		if len(parts) > 2 {
+			return strings.Join(parts[:2], "/")
+		}
+	}
+	return path
+}
+
+func (g *ImportGraph) Closure(roots []string) map[string]struct{} {
+	visited := map[string]struct{}{}
+	queue := append([]string{}, roots...)
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if _, ok := visited[current]; ok {
+			continue
+		}
+		visited[current] = struct{}{}
+
+		for i := range g.imports[current] {
+			if g.Contains(i) {
+				queue = append(queue, i)
+			}
+		}
+	}
+
+	return visited
+}
@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var graphvizFormats = map[string]string{
+	".pdf": "pdf",
+	".png": "png",
+	".svg": "svg",
+}
+
+func GraphvizFormat(output string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(output))
+	format, ok := graphvizFormats[ext]
+	if !ok {
+		return "", fmt.Errorf("unsupported output file %s, expected one of: .pdf, .png, .svg", output)
+	}
+	return format, nil
+}
+
+func RenderGraphviz(dotPath, source, output string) error {
+	format, err := GraphvizFormat(output)
+	if err != nil {
+		return err
+	}
+
+	bin, err := exec.LookPath(dotPath)
+	if err != nil {
+		return fmt.Errorf("graphviz executable %q not found, install Graphviz or point --dot-path to it", dotPath)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(bin, fmt.Sprintf("-T%s", format), "-o", output)
+	cmd.Stdin = strings.NewReader(source)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("%s: %s", dotPath, msg)
+	}
+
+	return nil
+}
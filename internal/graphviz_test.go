@@ -0,0 +1,55 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func fakeDot(t *testing.T, script string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "dot")
+	assertEqual(t, nil, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755))
+	return path
+}
+
+func TestGraphvizFormat(t *testing.T) {
+	for output, expected := range map[string]string{
+		"diagram.svg": "svg",
+		"diagram.PNG": "png",
+		"out/d.pdf":   "pdf",
+	} {
+		format, err := internal.GraphvizFormat(output)
+		assertEqual(t, nil, err)
+		assertEqual(t, expected, format)
+	}
+
+	_, err := internal.GraphvizFormat("diagram.jpg")
+	assertEqual(t, "unsupported output file diagram.jpg, expected one of: .pdf, .png, .svg", err.Error())
+}
+
+func TestRenderGraphviz(t *testing.T) {
+	dot := fakeDot(t, "echo \"$@\" > \"$3\"\ncat >> \"$3\"\n")
+	output := filepath.Join(t.TempDir(), "diagram.png")
+
+	err := internal.RenderGraphviz(dot, "digraph {}\n", output)
+	assertEqual(t, nil, err)
+
+	content, err := os.ReadFile(output)
+	assertEqual(t, nil, err)
+	assertEqual(t, "-Tpng -o "+output+"\ndigraph {}\n", string(content))
+}
+
+func TestRenderGraphvizErrors(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "diagram.svg")
+
+	err := internal.RenderGraphviz(filepath.Join(t.TempDir(), "missing"), "digraph {}\n", output)
+	assertEqual(t, true, strings.Contains(err.Error(), "not found, install Graphviz or point --dot-path to it"))
+
+	dot := fakeDot(t, "echo 'syntax error in line 1' >&2\nexit 1\n")
+	err = internal.RenderGraphviz(dot, "digraph {", output)
+	assertEqual(t, dot+": syntax error in line 1", err.Error())
+}
@@ -0,0 +1,282 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+type ImportEdge struct {
+	From     string
+	To       string
+	Packages []string
+	Files    int
+}
+
+type importCluster struct {
+	Path     string
+	Label    string
+	Nodes    []string
+	Children map[string]*importCluster
+}
+
+type ImportsOptions struct {
+	Depth   int
+	Cluster bool
+	Weights bool
+	Style   *DiagramStyle
+}
+
+func FindImportEdges(directories map[string]*Directory, module string, config *Config, opts *ImportsOptions) ([]*ImportEdge, map[string]ImportKind) {
+	g := NewImportGraph(directories, module, config.Requires)
+
+	var edges []*ImportEdge
+	byKey := map[[2]string]*ImportEdge{}
+	nodes := map[string]ImportKind{}
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			if isFake(pkg.Name) || isMock(pkg.Name) || isTest(pkg.Name) {
+				continue
+			}
+
+			from := collapseImport(pkg.ModulePath, module, opts.Depth)
+
+			files := map[string]int{}
+			for _, f := range pkg.Files {
+				seen := map[string]struct{}{}
+				for _, i := range f.Imports {
+					if _, ok := seen[i.Path]; ok {
+						continue
+					}
+					seen[i.Path] = struct{}{}
+					files[i.Path]++
+				}
+			}
+
+			for _, i := range sortedCounts(files) {
+				kind := g.Kind(i)
+				if kind == StdLibImport && config.ExcludeStdLib {
+					continue
+				}
+
+				to := i
+				switch kind {
+				case ModuleImport:
+					to = collapseImport(i, module, opts.Depth)
+				case ExternalImport:
+					if opts.Depth > 0 {
+						to = g.ExternalModule(i)
+					}
+				}
+
+				if to == from {
+					continue
+				}
+
+				key := [2]string{from, to}
+				e, ok := byKey[key]
+				if !ok {
+					e = &ImportEdge{From: from, To: to}
+					byKey[key] = e
+					edges = append(edges, e)
+				}
+				e.Packages = appendUnique(e.Packages, pkg.ModulePath)
+				e.Files += files[i]
+
+				if _, ok := nodes[from]; !ok {
+					nodes[from] = ModuleImport
+				}
+				nodes[to] = kind
+			}
+		}
+	}
+
+	return edges, nodes
+}
+
+func sortedCounts(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func collapseImport(p, module string, depth int) string {
+	if depth <= 0 || module == "" || !strings.HasPrefix(p, module+"/") {
+		return p
+	}
+
+	parts := strings.Split(strings.TrimPrefix(p, module+"/"), "/")
+	if len(parts) <= depth {
+		return p
+	}
+
+	return fmt.Sprintf("%s/%s", module, strings.Join(parts[:depth], "/"))
+}
+
+func relativeImport(p, module string) string {
+	if module != "" && p == module {
+		return ""
+	}
+	if module != "" && strings.HasPrefix(p, module+"/") {
+		return strings.TrimPrefix(p, module+"/")
+	}
+	return p
+}
+
+func newImportCluster(p, label string) *importCluster {
+	return &importCluster{Path: p, Label: label, Children: map[string]*importCluster{}}
+}
+
+func buildModuleClusters(root *importCluster, nodes []string, module string) {
+	dirs := map[string]struct{}{}
+	for _, n := range nodes {
+		rel := relativeImport(n, module)
+		for d := path.Dir(rel); d != "." && d != "/" && d != ""; d = path.Dir(d) {
+			dirs[d] = struct{}{}
+		}
+	}
+
+	var lookup func(dir string) *importCluster
+	lookup = func(dir string) *importCluster {
+		if dir == "." || dir == "/" || dir == "" {
+			return root
+		}
+		parent := lookup(path.Dir(dir))
+		c, ok := parent.Children[dir]
+		if !ok {
+			c = newImportCluster(dir, path.Base(dir))
+			parent.Children[dir] = c
+		}
+		return c
+	}
+
+	for _, n := range nodes {
+		rel := relativeImport(n, module)
+		if _, ok := dirs[rel]; ok {
+			lookup(rel).Nodes = append(lookup(rel).Nodes, n)
+			continue
+		}
+		lookup(path.Dir(rel)).Nodes = append(lookup(path.Dir(rel)).Nodes, n)
+	}
+}
+
+func writeImportCluster(sb *strings.Builder, c *importCluster, module string, indent int) {
+	prefix := strings.Repeat("    ", indent)
+
+	if c.Path != "" {
+		sb.WriteString(fmt.Sprintf("%ssubgraph cluster_%s {\n", prefix, normalizePackageName(c.Path)))
+		sb.WriteString(fmt.Sprintf("%s    label = \"%s\"\n", prefix, c.Label))
+		prefix += "    "
+	}
+
+	for _, n := range c.Nodes {
+		label := n
+		if rel := relativeImport(n, module); rel != n && rel != "" {
+			label = path.Base(rel)
+		}
+		sb.WriteString(fmt.Sprintf("%s\"%s\" [label=\"%s\"]\n", prefix, n, label))
+	}
+
+	keys := make([]string, 0, len(c.Children))
+	for k := range c.Children {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		writeImportCluster(sb, c.Children[k], module, len(prefix)/4)
+	}
+
+	if c.Path != "" {
+		sb.WriteString(fmt.Sprintf("%s}\n", strings.Repeat("    ", indent)))
+	}
+}
+
+func buildImportClusters(directories map[string]*Directory, module string, config *Config, nodes map[string]ImportKind) []*importCluster {
+	g := NewImportGraph(directories, module, config.Requires)
+
+	root := newImportCluster("", "")
+	stdlib := newImportCluster("stdlib", "stdlib")
+	external := newImportCluster("external", "third-party")
+
+	var local []string
+	for _, n := range sortedNodes(nodes) {
+		switch nodes[n] {
+		case StdLibImport:
+			stdlib.Nodes = append(stdlib.Nodes, n)
+		case ExternalImport:
+			m := g.ExternalModule(n)
+			c, ok := external.Children[m]
+			if !ok {
+				c = newImportCluster(fmt.Sprintf("external_%s", m), m)
+				external.Children[m] = c
+			}
+			c.Nodes = append(c.Nodes, n)
+		default:
+			local = append(local, n)
+		}
+	}
+
+	buildModuleClusters(root, local, module)
+
+	clusters := []*importCluster{root}
+	if len(stdlib.Nodes) > 0 {
+		clusters = append(clusters, stdlib)
+	}
+	if len(external.Children) > 0 {
+		clusters = append(clusters, external)
+	}
+
+	return clusters
+}
+
+func FormatImports(directories map[string]*Directory, module string, config *Config, opts *ImportsOptions) string {
+	var sb strings.Builder
+	sb.WriteString("digraph {\n")
+	writeDotHeader(&sb, opts.Style)
+	sb.WriteString("\n")
+
+	edges, nodes := FindImportEdges(directories, module, config, opts)
+
+	if opts.Cluster {
+		for _, c := range buildImportClusters(directories, module, config, nodes) {
+			writeImportCluster(&sb, c, module, 1)
+		}
+		sb.WriteString("\n")
+	}
+
+	for _, e := range edges {
+		if !opts.Weights {
+			sb.WriteString(fmt.Sprintf("    \"%s\" -> \"%s\"\n", e.From, e.To))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf(
+			"    \"%s\" -> \"%s\" [weight=%d, penwidth=%d, label=\"%d packages, %d files\"]\n",
+			e.From, e.To, len(e.Packages), penWidth(len(e.Packages)), len(e.Packages), e.Files,
+		))
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func sortedNodes(nodes map[string]ImportKind) []string {
+	keys := make([]string, 0, len(nodes))
+	for k := range nodes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func penWidth(weight int) int {
+	if weight > 5 {
+		return 5
+	}
+	return weight
+}
@@ -0,0 +1,103 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func formatImports(t *testing.T, config *internal.Config, opts *internal.ImportsOptions) string {
+	t.Helper()
+	directories := loadFixture(t, "testdata/imports", "example.com/imports", &internal.Config{})
+	config.Requires = []string{"github.com/acme/widgets"}
+	return internal.FormatImports(directories, "example.com/imports", config, opts)
+}
+
+func TestFormatImportsFlat(t *testing.T) {
+	assertEqual(t, `digraph {
+    rankdir="LR"
+
+    "example.com/imports/cmd/app" -> "example.com/imports/internal/api"
+    "example.com/imports/cmd/app" -> "example.com/imports/internal/store/memory"
+    "example.com/imports/internal/api" -> "example.com/imports/internal/store/sql"
+    "example.com/imports/internal/api" -> "github.com/acme/widgets/render"
+    "example.com/imports/internal/api" -> "github.com/acme/widgets/render/html"
+    "example.com/imports/internal/store/memory" -> "example.com/imports/internal/store/sql"
+}
+`, formatImports(t, &internal.Config{ExcludeStdLib: true}, &internal.ImportsOptions{}))
+}
+
+func TestFormatImportsCollapsedWithWeights(t *testing.T) {
+	assertEqual(t, `digraph {
+    rankdir="LR"
+
+    "example.com/imports/cmd/app" -> "example.com/imports/internal/api" [weight=1, penwidth=1, label="1 packages, 1 files"]
+    "example.com/imports/cmd/app" -> "example.com/imports/internal/store" [weight=1, penwidth=1, label="1 packages, 1 files"]
+    "example.com/imports/internal/api" -> "example.com/imports/internal/store" [weight=1, penwidth=1, label="1 packages, 2 files"]
+    "example.com/imports/internal/api" -> "github.com/acme/widgets" [weight=1, penwidth=1, label="1 packages, 2 files"]
+}
+`, formatImports(t, &internal.Config{ExcludeStdLib: true}, &internal.ImportsOptions{Depth: 2, Weights: true}))
+}
+
+func TestFormatImportsClustered(t *testing.T) {
+	assertEqual(t, `digraph {
+    rankdir="LR"
+
+    subgraph cluster_cmd {
+        label = "cmd"
+        "example.com/imports/cmd/app" [label="app"]
+    }
+    subgraph cluster_internal {
+        label = "internal"
+        "example.com/imports/internal/api" [label="api"]
+        subgraph cluster_internal_store {
+            label = "store"
+            "example.com/imports/internal/store/memory" [label="memory"]
+            "example.com/imports/internal/store/sql" [label="sql"]
+        }
+    }
+    subgraph cluster_stdlib {
+        label = "stdlib"
+        "fmt" [label="fmt"]
+        "strings" [label="strings"]
+    }
+    subgraph cluster_external {
+        label = "third-party"
+        subgraph cluster_external_github_com_acme_widgets {
+            label = "github.com/acme/widgets"
+            "github.com/acme/widgets/render" [label="github.com/acme/widgets/render"]
+            "github.com/acme/widgets/render/html" [label="github.com/acme/widgets/render/html"]
+        }
+    }
+
+    "example.com/imports/cmd/app" -> "example.com/imports/internal/api"
+    "example.com/imports/cmd/app" -> "example.com/imports/internal/store/memory"
+    "example.com/imports/cmd/app" -> "fmt"
+    "example.com/imports/internal/api" -> "example.com/imports/internal/store/sql"
+    "example.com/imports/internal/api" -> "github.com/acme/widgets/render"
+    "example.com/imports/internal/api" -> "github.com/acme/widgets/render/html"
+    "example.com/imports/internal/store/memory" -> "example.com/imports/internal/store/sql"
+    "example.com/imports/internal/store/sql" -> "strings"
+}
+`, formatImports(t, &internal.Config{}, &internal.ImportsOptions{Cluster: true}))
+}
+
+func TestFormatImportsStyle(t *testing.T) {
+	assertEqual(t, `digraph {
+    rankdir="TB"
+    splines="ortho"
+    node [fontname="Helvetica", shape="box", color="#336699"]
+    edge [fontname="Helvetica", color="gray"]
+
+    "example.com/imports/cmd" -> "example.com/imports/internal"
+    "example.com/imports/internal" -> "github.com/acme/widgets"
+}
+`, formatImports(t, &internal.Config{ExcludeStdLib: true}, &internal.ImportsOptions{Depth: 1, Style: &internal.DiagramStyle{
+		RankDir:   "TB",
+		FontName:  "Helvetica",
+		NodeShape: "box",
+		NodeColor: "#336699",
+		EdgeColor: "gray",
+		Splines:   "ortho",
+	}}))
+}
@@ -0,0 +1,458 @@
+package internal
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type FieldLayout struct {
+	Name    string
+	Type    string
+	Offset  int64
+	Size    int64
+	Align   int64
+	Padding int64
+}
+
+type StructLayout struct {
+	Package      string
+	Name         string
+	Position     Position
+	Size         int64
+	Align        int64
+	Padding      int64
+	Fields       []*FieldLayout
+	OptimalSize  int64
+	OptimalOrder []string
+	Errors       []string
+}
+
+func (l *StructLayout) Wasted() int64 {
+	return l.Size - l.OptimalSize
+}
+
+const layoutTestSuffix = " [test]"
+
+type layoutImporter struct {
+	fset     *token.FileSet
+	context  build.Context
+	sizes    types.Sizes
+	files    map[string][]string
+	specs    map[string]map[string]*ast.TypeSpec
+	packages map[string]*types.Package
+	errors   map[string][]types.Error
+}
+
+func newLayoutImporter(directories map[string]*Directory, goos, arch string, tests bool, sizes types.Sizes) *layoutImporter {
+	context := build.Default
+	context.GOOS = goos
+	context.GOARCH = arch
+	context.CgoEnabled = false
+
+	imp := &layoutImporter{
+		fset:     token.NewFileSet(),
+		context:  context,
+		sizes:    sizes,
+		files:    map[string][]string{},
+		specs:    map[string]map[string]*ast.TypeSpec{},
+		packages: map[string]*types.Package{},
+		errors:   map[string][]types.Error{},
+	}
+
+	for _, directory := range directories {
+		for _, pkg := range directory.Packages {
+			external := strings.HasSuffix(pkg.Name, "_test")
+			if external && !tests {
+				continue
+			}
+			for _, f := range pkg.Files {
+				if ok, err := context.MatchFile(filepath.Dir(f.Path), filepath.Base(f.Path)); err != nil || !ok {
+					continue
+				}
+				switch {
+				case external:
+					imp.files[pkg.ModulePath+"_test"] = append(imp.files[pkg.ModulePath+"_test"], f.Path)
+				case isTestFile(f.Path):
+					if tests {
+						imp.files[pkg.ModulePath+layoutTestSuffix] = append(imp.files[pkg.ModulePath+layoutTestSuffix], f.Path)
+					}
+				default:
+					imp.files[pkg.ModulePath] = append(imp.files[pkg.ModulePath], f.Path)
+					if tests {
+						imp.files[pkg.ModulePath+layoutTestSuffix] = append(imp.files[pkg.ModulePath+layoutTestSuffix], f.Path)
+					}
+				}
+			}
+		}
+	}
+
+	for _, files := range imp.files {
+		sort.Strings(files)
+	}
+
+	return imp
+}
+
+func (imp *layoutImporter) Import(path string) (*types.Package, error) {
+	return imp.ImportFrom(path, "", 0)
+}
+
+func (imp *layoutImporter) ImportFrom(path, dir string, _ types.ImportMode) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+
+	if _, ok := imp.files[path]; ok {
+		return imp.check(path)
+	}
+
+	if !isStdLib(path) && strings.Contains(strings.Split(path, "/")[0], ".") {
+		return nil, fmt.Errorf("package %s is not part of the module", path)
+	}
+
+	bp, err := imp.context.Import(path, dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	if pkg, ok := imp.packages[bp.ImportPath]; ok {
+		return pkg, nil
+	}
+
+	var astFiles []*ast.File
+	for _, f := range bp.GoFiles {
+		astFile, err := parser.ParseFile(imp.fset, filepath.Join(bp.Dir, f), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		astFiles = append(astFiles, astFile)
+	}
+
+	conf := types.Config{
+		Importer: imp,
+		Sizes:    imp.sizes,
+		Error:    func(error) {},
+	}
+
+	pkg, _ := conf.Check(bp.ImportPath, imp.fset, astFiles, nil)
+	imp.packages[bp.ImportPath] = pkg
+
+	return pkg, nil
+}
+
+func (imp *layoutImporter) check(id string) (*types.Package, error) {
+	if pkg, ok := imp.packages[id]; ok {
+		return pkg, nil
+	}
+
+	specs := map[string]*ast.TypeSpec{}
+	var astFiles []*ast.File
+	for _, f := range imp.files[id] {
+		astFile, err := parser.ParseFile(imp.fset, f, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		astFiles = append(astFiles, astFile)
+
+		for _, decl := range astFile.Decls {
+			if gd, ok := decl.(*ast.GenDecl); ok {
+				for _, spec := range gd.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						specs[ts.Name.Name] = ts
+					}
+				}
+			}
+		}
+	}
+	imp.specs[id] = specs
+
+	conf := types.Config{
+		Importer: imp,
+		Sizes:    imp.sizes,
+		Error: func(err error) {
+			if e, ok := err.(types.Error); ok {
+				imp.errors[id] = append(imp.errors[id], e)
+			}
+		},
+	}
+
+	pkg, _ := conf.Check(strings.TrimSuffix(id, layoutTestSuffix), imp.fset, astFiles, nil)
+	imp.packages[id] = pkg
+
+	return pkg, nil
+}
+
+func (imp *layoutImporter) structErrors(id, name string) []string {
+	ts, ok := imp.specs[id][name]
+	if !ok {
+		return nil
+	}
+
+	var res []string
+	for _, e := range imp.errors[id] {
+		if e.Pos >= ts.Pos() && e.Pos < ts.End() {
+			res = append(res, e.Error())
+		}
+	}
+	return res
+}
+
+type LayoutOptions struct {
+	OS        string
+	Arch      string
+	Wasted    bool
+	Positions bool
+}
+
+func FindLayouts(directories map[string]*Directory, module string, config *Config, opts *LayoutOptions) ([]*StructLayout, error) {
+	goos := opts.OS
+	if goos == "" {
+		goos = build.Default.GOOS
+	}
+	arch := opts.Arch
+	if arch == "" {
+		arch = build.Default.GOARCH
+	}
+
+	sizes := types.SizesFor("gc", arch)
+	if sizes == nil {
+		return nil, fmt.Errorf("unknown architecture: %s", arch)
+	}
+
+	imp := newLayoutImporter(directories, goos, arch, config.IncludeTests, sizes)
+
+	var res []*StructLayout
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			if !isSelected(config, pkg.ModulePath) {
+				continue
+			}
+
+			id := pkg.ModulePath
+			switch {
+			case strings.HasSuffix(pkg.Name, "_test"):
+				id = pkg.ModulePath + "_test"
+			case config.IncludeTests:
+				id = pkg.ModulePath + layoutTestSuffix
+			}
+			if _, ok := imp.files[id]; !ok {
+				continue
+			}
+
+			typesPkg, err := imp.check(id)
+			if err != nil || typesPkg == nil {
+				continue
+			}
+
+			sort.Sort(ByFilePath(pkg.Files))
+
+			for _, f := range pkg.Files {
+				if isTestFile(f.Path) && !config.IncludeTests {
+					continue
+				}
+				for _, s := range f.Structs {
+					l := structLayout(typesPkg, s, sizes)
+					if l == nil {
+						continue
+					}
+					l.Package = strings.TrimSuffix(id, layoutTestSuffix)
+					if len(l.Errors) > 0 {
+						l.Errors = append(l.Errors, imp.structErrors(id, s.Name)...)
+					}
+					res = append(res, l)
+				}
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func isSelected(config *Config, modulePath string) bool {
+	if len(config.Select) > 0 {
+		found := false
+		for sel := range config.Select {
+			if strings.Contains(modulePath, sel) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return !Set(config.Exclude).Contains(modulePath)
+}
+
+func structLayout(pkg *types.Package, s *Struct, sizes types.Sizes) *StructLayout {
+	obj, ok := pkg.Scope().Lookup(s.Name).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+
+	if named, ok := obj.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+		return nil
+	}
+
+	st, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+
+	var fields []*types.Var
+	var invalid []string
+	for i := 0; i < st.NumFields(); i++ {
+		fields = append(fields, st.Field(i))
+		if !validLayout(st.Field(i).Type(), map[types.Type]bool{}) {
+			invalid = append(invalid, fmt.Sprintf("field %s has an invalid type", st.Field(i).Name()))
+		}
+	}
+
+	if len(invalid) > 0 {
+		return &StructLayout{Name: s.Name, Position: s.Position, Errors: invalid}
+	}
+
+	l := &StructLayout{
+		Name:     s.Name,
+		Position: s.Position,
+		Size:     sizes.Sizeof(st),
+		Align:    sizes.Alignof(st),
+	}
+
+	qualifier := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		return p.Name()
+	}
+	offsets := sizes.Offsetsof(fields)
+	for i, field := range fields {
+		size := sizes.Sizeof(field.Type())
+		end := l.Size
+		if i+1 < len(fields) {
+			end = offsets[i+1]
+		}
+
+		fl := &FieldLayout{
+			Name:    field.Name(),
+			Type:    types.TypeString(field.Type(), qualifier),
+			Offset:  offsets[i],
+			Size:    size,
+			Align:   sizes.Alignof(field.Type()),
+			Padding: end - offsets[i] - size,
+		}
+		l.Padding += fl.Padding
+		l.Fields = append(l.Fields, fl)
+	}
+
+	optimal := optimalFieldOrder(fields, sizes)
+	for _, field := range optimal {
+		l.OptimalOrder = append(l.OptimalOrder, field.Name())
+	}
+	l.OptimalSize = sizes.Sizeof(types.NewStruct(optimal, nil))
+
+	return l
+}
+
+func validLayout(t types.Type, seen map[types.Type]bool) bool {
+	if seen[t] {
+		return true
+	}
+	seen[t] = true
+
+	switch t := t.(type) {
+	case *types.Basic:
+		return t.Kind() != types.Invalid
+	case *types.Named:
+		return validLayout(t.Underlying(), seen)
+	case *types.Array:
+		return validLayout(t.Elem(), seen)
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if !validLayout(t.Field(i).Type(), seen) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func optimalFieldOrder(fields []*types.Var, sizes types.Sizes) []*types.Var {
+	optimal := append([]*types.Var{}, fields...)
+
+	sort.SliceStable(optimal, func(i, j int) bool {
+		a, b := optimal[i].Type(), optimal[j].Type()
+
+		sa, sb := sizes.Sizeof(a), sizes.Sizeof(b)
+		if sa == 0 || sb == 0 {
+			return sa == 0 && sb != 0
+		}
+
+		aa, ab := sizes.Alignof(a), sizes.Alignof(b)
+		if aa != ab {
+			return aa > ab
+		}
+		return sa > sb
+	})
+
+	return optimal
+}
+
+func FormatLayouts(directories map[string]*Directory, module string, config *Config, opts *LayoutOptions) (string, error) {
+	var sb strings.Builder
+
+	layouts, err := FindLayouts(directories, module, config, opts)
+	if err != nil {
+		return "", err
+	}
+
+	for _, l := range layouts {
+		if len(l.Errors) > 0 {
+			sb.WriteString(fmt.Sprintf(
+				"%s%s%s%s.%s%s%s %scannot compute layout: %s%s\n",
+				formatPosition(opts.Positions, l.Position),
+				Yellow, l.Package, NoColor,
+				Blue, l.Name, NoColor,
+				Red, strings.Join(l.Errors, "; "), NoColor,
+			))
+			continue
+		}
+
+		if opts.Wasted && l.Wasted() == 0 {
+			continue
+		}
+
+		header := fmt.Sprintf(
+			"%s%s%s%s.%s%s%s size %d, align %d, padding %d",
+			formatPosition(opts.Positions, l.Position),
+			Yellow, l.Package, NoColor,
+			Blue, l.Name, NoColor,
+			l.Size, l.Align, l.Padding,
+		)
+		if l.Wasted() > 0 {
+			header = fmt.Sprintf(
+				"%s %s(optimal size %d: %s)%s",
+				header, Red, l.OptimalSize, strings.Join(l.OptimalOrder, ", "), NoColor,
+			)
+		}
+		sb.WriteString(fmt.Sprintf("%s\n", header))
+
+		for _, f := range l.Fields {
+			sb.WriteString(fmt.Sprintf("    %4d %4d %4d  %s %s\n", f.Offset, f.Size, f.Align, f.Name, f.Type))
+			if f.Padding > 0 {
+				sb.WriteString(fmt.Sprintf(
+					"    %4d %4d       %s(padding)%s\n",
+					f.Offset+f.Size, f.Padding, Red, NoColor,
+				))
+			}
+		}
+	}
+
+	return sb.String(), nil
+}
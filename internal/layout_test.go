@@ -0,0 +1,96 @@
+package internal_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func findLayouts(t *testing.T, config *internal.Config, opts *internal.LayoutOptions) []string {
+	t.Helper()
+	directories := loadFixture(t, "testdata/layout", "example.com/layout", config)
+	layouts, err := internal.FindLayouts(directories, "example.com/layout", config, opts)
+	assertEqual(t, nil, err)
+
+	var res []string
+	for _, l := range layouts {
+		if len(l.Errors) > 0 {
+			res = append(res, fmt.Sprintf("%s.%s %s", l.Package, l.Name, strings.Join(l.Errors, "; ")))
+			continue
+		}
+		res = append(res, fmt.Sprintf("%s.%s %d %d %d %d", l.Package, l.Name, l.Size, l.Align, l.Padding, l.OptimalSize))
+	}
+	return res
+}
+
+func TestFindLayouts(t *testing.T) {
+	assertEqual(t, []string{
+		"example.com/layout.Padded 24 8 14 16",
+		"example.com/layout.Packed 16 8 6 16",
+		"example.com/layout.Shape 72 8 7 72",
+		"example.com/layout.Empty 0 1 0 0",
+		"example.com/layout/broken.Valid 16 8 7 16",
+		"example.com/layout/broken.Broken field Missing has an invalid type; testdata/layout/broken/broken.go:10:10: undefined: Unknown",
+		"example.com/layout/broken.Dangling field Next has an invalid type; testdata/layout/broken/broken.go:14:7: undefined: Undefined",
+		"example.com/layout/geo.Point 16 8 0 16",
+		"example.com/layout/platform.Process 184 8 0 184",
+	}, findLayouts(t, &internal.Config{}, &internal.LayoutOptions{OS: "linux", Arch: "amd64"}))
+
+	assertEqual(t, []string{
+		"example.com/layout.Padded 16 4 6 12",
+		"example.com/layout.Packed 12 4 2 12",
+		"example.com/layout.Shape 48 4 3 48",
+		"example.com/layout.Empty 0 1 0 0",
+		"example.com/layout/broken.Valid 12 4 3 12",
+		"example.com/layout/broken.Broken field Missing has an invalid type; testdata/layout/broken/broken.go:10:10: undefined: Unknown",
+		"example.com/layout/broken.Dangling field Next has an invalid type; testdata/layout/broken/broken.go:14:7: undefined: Undefined",
+		"example.com/layout/geo.Point 16 4 0 16",
+		"example.com/layout/platform.Process 96 4 0 96",
+	}, findLayouts(t, &internal.Config{}, &internal.LayoutOptions{OS: "linux", Arch: "386"}))
+}
+
+func TestFindLayoutsOS(t *testing.T) {
+	assertEqual(t, []string{
+		"example.com/layout/platform.Process 96 8 0 96",
+	}, findLayouts(t, &internal.Config{Select: map[string]struct{}{"platform": {}}}, &internal.LayoutOptions{OS: "windows", Arch: "amd64"}))
+}
+
+func TestFindLayoutsTests(t *testing.T) {
+	assertEqual(t, []string{
+		"example.com/layout/geo.Point 16 8 0 16",
+		"example.com/layout/geo.fixture 24 8 7 24",
+		"example.com/layout/geo_test.fixture 32 8 0 32",
+	}, findLayouts(t, &internal.Config{IncludeTests: true, Select: map[string]struct{}{"geo": {}}}, &internal.LayoutOptions{Arch: "amd64"}))
+}
+
+func TestFindLayoutsUnknownArch(t *testing.T) {
+	directories := loadFixture(t, "testdata/layout", "example.com/layout", &internal.Config{})
+	_, err := internal.FindLayouts(directories, "example.com/layout", &internal.Config{}, &internal.LayoutOptions{Arch: "z80"})
+	assertEqual(t, "unknown architecture: z80", err.Error())
+}
+
+func TestFormatLayouts(t *testing.T) {
+	expected := "" +
+		"__YELLOW__example.com/layout__NOCOLOR__.__BLUE__Padded__NOCOLOR__ size 24, align 8, padding 14 __RED__(optimal size 16: Count, Enabled, Visible)__NOCOLOR__\n" +
+		"       0    1    1  Enabled bool\n" +
+		"       1    7       __RED__(padding)__NOCOLOR__\n" +
+		"       8    8    8  Count int64\n" +
+		"      16    1    1  Visible bool\n" +
+		"      17    7       __RED__(padding)__NOCOLOR__\n" +
+		"__YELLOW__example.com/layout/broken__NOCOLOR__.__BLUE__Broken__NOCOLOR__ " +
+		"__RED__cannot compute layout: field Missing has an invalid type; testdata/layout/broken/broken.go:10:10: undefined: Unknown__NOCOLOR__\n" +
+		"__YELLOW__example.com/layout/broken__NOCOLOR__.__BLUE__Dangling__NOCOLOR__ " +
+		"__RED__cannot compute layout: field Next has an invalid type; testdata/layout/broken/broken.go:14:7: undefined: Undefined__NOCOLOR__\n"
+
+	expected = strings.ReplaceAll(expected, "__YELLOW__", internal.Yellow)
+	expected = strings.ReplaceAll(expected, "__BLUE__", internal.Blue)
+	expected = strings.ReplaceAll(expected, "__RED__", internal.Red)
+	expected = strings.ReplaceAll(expected, "__NOCOLOR__", internal.NoColor)
+
+	directories := loadFixture(t, "testdata/layout", "example.com/layout", &internal.Config{})
+	actual, err := internal.FormatLayouts(directories, "example.com/layout", &internal.Config{}, &internal.LayoutOptions{Arch: "amd64", Wasted: true})
+	assertEqual(t, nil, err)
+	assertEqual(t, expected, actual)
+}
@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+type LineCounts struct {
+	Code    int
+	Comment int
+	Blank   int
+}
+
+func (l LineCounts) Total() int {
+	return l.Code + l.Comment + l.Blank
+}
+
+func (l *LineCounts) Add(other LineCounts) {
+	l.Code += other.Code
+	l.Comment += other.Comment
+	l.Blank += other.Blank
+}
+
+func countLines(fset *token.FileSet, file *ast.File, src []byte) LineCounts {
+	var counts LineCounts
+
+	stripped := make([]byte, len(src))
+	copy(stripped, src)
+
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			start := fset.Position(c.Pos()).Offset
+			end := fset.Position(c.End()).Offset
+			for i := start; i < end && i < len(stripped); i++ {
+				if stripped[i] != '\n' {
+					stripped[i] = ' '
+				}
+			}
+		}
+	}
+
+	original := bytes.Split(src, []byte("\n"))
+	code := bytes.Split(stripped, []byte("\n"))
+
+	if len(original) > 0 && len(bytes.TrimSpace(original[len(original)-1])) == 0 {
+		original = original[:len(original)-1]
+		code = code[:len(code)-1]
+	}
+
+	for i := range original {
+		switch {
+		case len(bytes.TrimSpace(original[i])) == 0:
+			counts.Blank++
+		case len(bytes.TrimSpace(code[i])) == 0:
+			counts.Comment++
+		default:
+			counts.Code++
+		}
+	}
+
+	return counts
+}
+
+type PackageLines struct {
+	Path      string
+	Files     int
+	TestFiles int
+	Source    LineCounts
+	Test      LineCounts
+}
+
+var LinesColumns = []string{
+	"package", "files", "tests", "code", "comment", "blank", "test-code", "test-comment", "test-blank",
+}
+
+func (p *PackageLines) Value(column string) int {
+	switch column {
+	case "files":
+		return p.Files
+	case "tests":
+		return p.TestFiles
+	case "comment":
+		return p.Source.Comment
+	case "blank":
+		return p.Source.Blank
+	case "test-code":
+		return p.Test.Code
+	case "test-comment":
+		return p.Test.Comment
+	case "test-blank":
+		return p.Test.Blank
+	default:
+		return p.Source.Code
+	}
+}
+
+func FindPackageLines(directories map[string]*Directory) []*PackageLines {
+	byPath := map[string]*PackageLines{}
+	var res []*PackageLines
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			p, ok := byPath[pkg.ModulePath]
+			if !ok {
+				p = &PackageLines{Path: pkg.ModulePath}
+				byPath[pkg.ModulePath] = p
+				res = append(res, p)
+			}
+
+			for _, f := range pkg.Files {
+				if isTestFile(f.Path) {
+					p.TestFiles++
+					p.Test.Add(f.Lines)
+					continue
+				}
+				p.Files++
+				p.Source.Add(f.Lines)
+			}
+		}
+	}
+
+	return res
+}
+
+type LinesOptions struct {
+	Sort string
+}
+
+func FormatPackageLines(directories map[string]*Directory, module string, config *Config, opts *LinesOptions) string {
+	var sb strings.Builder
+
+	packages := FindPackageLines(directories)
+
+	column := strings.ToLower(opts.Sort)
+	sort.SliceStable(packages, func(i, j int) bool {
+		if column == "package" {
+			return packages[i].Path < packages[j].Path
+		}
+		a, b := packages[i].Value(column), packages[j].Value(column)
+		if a == b {
+			return packages[i].Path < packages[j].Path
+		}
+		return a > b
+	})
+
+	max := len("package")
+	for _, p := range packages {
+		if len(p.Path) > max {
+			max = len(p.Path)
+		}
+	}
+
+	header := []interface{}{max, "package"}
+	for _, c := range LinesColumns[1:] {
+		header = append(header, c)
+	}
+	sb.WriteString(fmt.Sprintf("%-*s | %5s | %5s | %6s | %7s | %5s | %9s | %12s | %10s\n", header...))
+
+	for _, p := range packages {
+		sb.WriteString(fmt.Sprintf(
+			"%-*s | %5d | %5d | %6d | %7d | %5d | %9d | %12d | %10d\n",
+			max, p.Path, p.Files, p.TestFiles,
+			p.Source.Code, p.Source.Comment, p.Source.Blank,
+			p.Test.Code, p.Test.Comment, p.Test.Blank,
+		))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,58 @@
+package internal_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func TestLineCounts(t *testing.T) {
+	directories := loadFixture(t, "testdata/lines", "example.com/lines", &internal.Config{IncludeTests: true})
+
+	actual := map[string]internal.LineCounts{}
+	for _, d := range directories {
+		for _, pkg := range d.Packages {
+			for _, f := range pkg.Files {
+				actual[f.Path] = f.Lines
+			}
+		}
+	}
+
+	assertEqual(t, map[string]internal.LineCounts{
+		"testdata/lines/lines.go":      {Code: 5, Comment: 6, Blank: 4},
+		"testdata/lines/lines_test.go": {Code: 5, Comment: 1, Blank: 2},
+		"testdata/lines/util/util.go":  {Code: 4, Comment: 0, Blank: 3},
+	}, actual)
+}
+
+func TestFormatPackageLines(t *testing.T) {
+	directories := loadFixture(t, "testdata/lines", "example.com/lines", &internal.Config{IncludeTests: true})
+
+	expected := "" +
+		"package                | files | tests |   code | comment | blank | test-code | test-comment | test-blank\n" +
+		"example.com/lines      |     1 |     1 |      5 |       6 |     4 |         5 |            1 |          2\n" +
+		"example.com/lines/util |     1 |     0 |      4 |       0 |     3 |         0 |            0 |          0\n"
+
+	assertEqual(t, expected, internal.FormatPackageLines(directories, "example.com/lines", &internal.Config{}, &internal.LinesOptions{Sort: "blank"}))
+}
+
+func TestFormatStatsLines(t *testing.T) {
+	directories := loadFixture(t, "testdata/lines", "example.com/lines", &internal.Config{IncludeTests: true})
+
+	actual := internal.FormatStats(directories, "example.com/lines")
+
+	for _, line := range []string{
+		fmt.Sprintf(" %d | source code lines\n", 9),
+		fmt.Sprintf(" %d | source comment lines\n", 6),
+		fmt.Sprintf(" %d | source blank lines\n", 7),
+		fmt.Sprintf(" %d | test code lines\n", 5),
+		fmt.Sprintf(" %d | test comment lines\n", 1),
+		fmt.Sprintf(" %d | test blank lines\n", 2),
+	} {
+		if !strings.Contains(actual, line) {
+			t.Errorf("expected stats to contain %q:\n%s", line, actual)
+		}
+	}
+}
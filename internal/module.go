@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type GoMod struct {
+	Module   string
+	Requires []string
+}
+
+func ReadGoMod(dir string) (*GoMod, error) {
+	bytes, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	mod := &GoMod{}
+	inBlock := false
+
+	for _, line := range strings.Split(string(bytes), "\n") {
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(line, "module "):
+			mod.Module = strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), "\"")
+		case line == "require (":
+			inBlock = true
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "":
+			mod.Requires = append(mod.Requires, strings.Fields(line)[0])
+		case strings.HasPrefix(line, "require "):
+			mod.Requires = append(mod.Requires, strings.Fields(line)[1])
+		}
+	}
+
+	return mod, nil
+}
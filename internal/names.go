@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+type NameMismatch struct {
+	Path      string
+	Name      string
+	Expected  string
+	Position  Position
+	Importers []*AliasUse
+}
+
+type MixedDirectory struct {
+	Path     string
+	Packages []string
+}
+
+func FindNameMismatches(directories map[string]*Directory) []*NameMismatch {
+	var res []*NameMismatch
+	byPath := map[string]*NameMismatch{}
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			if !isImportable(pkg) {
+				continue
+			}
+
+			expected := directoryPackageName(pkg.ModulePath)
+			if pkg.Name == expected {
+				continue
+			}
+
+			sort.Sort(ByFilePath(pkg.Files))
+
+			m := &NameMismatch{
+				Path:     pkg.ModulePath,
+				Name:     pkg.Name,
+				Expected: expected,
+			}
+			if len(pkg.Files) > 0 {
+				m.Position = pkg.Files[0].Position
+			}
+
+			byPath[pkg.ModulePath] = m
+			res = append(res, m)
+		}
+	}
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			for _, f := range pkg.Files {
+				for _, i := range f.Imports {
+					m, ok := byPath[i.Path]
+					if !ok || i.Name != "" {
+						continue
+					}
+					m.Importers = append(m.Importers, &AliasUse{
+						Importer: pkg.ModulePath,
+						Position: i.Position,
+					})
+				}
+			}
+		}
+	}
+
+	for _, m := range res {
+		sort.SliceStable(m.Importers, func(i, j int) bool {
+			return m.Importers[i].Position.Before(m.Importers[j].Position)
+		})
+	}
+
+	return res
+}
+
+func directoryPackageName(p string) string {
+	name := path.Base(p)
+	if majorVersionSuffix.MatchString(name) && path.Dir(p) != "." {
+		name = path.Base(path.Dir(p))
+	}
+	return strings.NewReplacer("-", "_", ".", "_").Replace(name)
+}
+
+func FindMixedDirectories(directories map[string]*Directory) []*MixedDirectory {
+	var res []*MixedDirectory
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		d := &MixedDirectory{}
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			if strings.HasSuffix(pkg.Name, "_test") {
+				continue
+			}
+			d.Path = pkg.ModulePath
+			d.Packages = append(d.Packages, pkg.Name)
+		}
+		if len(d.Packages) > 1 {
+			res = append(res, d)
+		}
+	}
+
+	return res
+}
+
+type NamesOptions struct {
+	Positions bool
+}
+
+func FormatPackageNames(directories map[string]*Directory, module string, config *Config, opts *NamesOptions) string {
+	var sb strings.Builder
+
+	mismatches := FindNameMismatches(directories)
+	if len(mismatches) > 0 {
+		sb.WriteString("package name differs from directory:\n")
+		for _, m := range mismatches {
+			sb.WriteString(fmt.Sprintf(
+				"%s    %s%s%s package %s%s%s (expected %s)\n",
+				formatPosition(opts.Positions, m.Position),
+				Yellow, m.Path, NoColor,
+				Red, m.Name, NoColor,
+				m.Expected,
+			))
+			for _, u := range m.Importers {
+				sb.WriteString(fmt.Sprintf(
+					"%s        imported without alias by %s\n",
+					formatPosition(opts.Positions, u.Position), u.Importer,
+				))
+			}
+		}
+	}
+
+	mixed := FindMixedDirectories(directories)
+	if len(mixed) > 0 {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("directories with multiple packages:\n")
+		for _, d := range mixed {
+			sb.WriteString(fmt.Sprintf(
+				"    %s%s%s %s%s%s\n",
+				Yellow, d.Path, NoColor, Red, strings.Join(d.Packages, ", "), NoColor,
+			))
+		}
+	}
+
+	return sb.String()
+}
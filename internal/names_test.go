@@ -0,0 +1,57 @@
+package internal_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func TestFindNameMismatches(t *testing.T) {
+	var res []string
+	directories := loadFixture(t, "testdata/names", "example.com/names", &internal.Config{IncludeTests: true})
+	for _, m := range internal.FindNameMismatches(directories) {
+		var importers []string
+		for _, u := range m.Importers {
+			importers = append(importers, u.Importer)
+		}
+		res = append(res, fmt.Sprintf("%s %s %s %v", m.Path, m.Name, m.Expected, importers))
+	}
+
+	assertEqual(t, []string{
+		"example.com/names/go-yaml yaml go_yaml [example.com/names/api]",
+		"example.com/names/mixed other mixed []",
+		"example.com/names/util helpers util [example.com/names/api]",
+		"example.com/names/yaml-go yaml yaml_go []",
+	}, res)
+}
+
+func TestFindMixedDirectories(t *testing.T) {
+	directories := loadFixture(t, "testdata/names", "example.com/names", &internal.Config{IncludeTests: true})
+	res := internal.FindMixedDirectories(directories)
+	assertEqual(t, 1, len(res))
+	assertEqual(t, "example.com/names/mixed", res[0].Path)
+	assertEqual(t, []string{"mixed", "other"}, res[0].Packages)
+}
+
+func TestFormatPackageNames(t *testing.T) {
+	expected := "" +
+		"package name differs from directory:\n" +
+		"    __YELLOW__example.com/names/go-yaml__NOCOLOR__ package __RED__yaml__NOCOLOR__ (expected go_yaml)\n" +
+		"        imported without alias by example.com/names/api\n" +
+		"    __YELLOW__example.com/names/mixed__NOCOLOR__ package __RED__other__NOCOLOR__ (expected mixed)\n" +
+		"    __YELLOW__example.com/names/util__NOCOLOR__ package __RED__helpers__NOCOLOR__ (expected util)\n" +
+		"        imported without alias by example.com/names/api\n" +
+		"    __YELLOW__example.com/names/yaml-go__NOCOLOR__ package __RED__yaml__NOCOLOR__ (expected yaml_go)\n" +
+		"\n" +
+		"directories with multiple packages:\n" +
+		"    __YELLOW__example.com/names/mixed__NOCOLOR__ __RED__mixed, other__NOCOLOR__\n"
+
+	expected = strings.ReplaceAll(expected, "__YELLOW__", internal.Yellow)
+	expected = strings.ReplaceAll(expected, "__RED__", internal.Red)
+	expected = strings.ReplaceAll(expected, "__NOCOLOR__", internal.NoColor)
+
+	directories := loadFixture(t, "testdata/names", "example.com/names", &internal.Config{IncludeTests: true})
+	assertEqual(t, expected, internal.FormatPackageNames(directories, "example.com/names", &internal.Config{}, &internal.NamesOptions{}))
+}
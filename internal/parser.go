@@ -11,8 +11,6 @@ import (
 	"strings"
 )
 
-var currentFile string
-
 const (
 	NoColor = "\033[0m"
 	Red     = "\033[0;31m"
@@ -30,14 +28,51 @@ const (
 	Public
 )
 
+type Position struct {
+	Filename string
+	Line     int
+	Column   int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+}
+
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) Before(other Position) bool {
+	if p.Filename != other.Filename {
+		return p.Filename < other.Filename
+	}
+	if p.Line != other.Line {
+		return p.Line < other.Line
+	}
+	return p.Column < other.Column
+}
+
+func newPosition(fset *token.FileSet, pos token.Pos) Position {
+	position := fset.Position(pos)
+	return Position{
+		Filename: position.Filename,
+		Line:     position.Line,
+		Column:   position.Column,
+	}
+}
+
 type Struct struct {
-	Name    string
-	Fields  []*Field
-	Methods []*Method
+	Name     string
+	Fields   []*Field
+	Methods  []*Method
+	Position Position
 }
 
 type Method struct {
-	Signature string
+	Signature  string
+	Receiver   string
+	Position   Position
+	Complexity *Complexity
 }
 
 func (m *Method) Visibility() Visibility {
@@ -47,15 +82,38 @@ func (m *Method) Visibility() Visibility {
 	return Private
 }
 
+type Interface struct {
+	Name     string
+	Methods  []*Method
+	Embedded []string
+	Position Position
+}
+
+type NamedType struct {
+	Name     string
+	Type     string
+	Alias    bool
+	Position Position
+}
+
+type Function struct {
+	Name       string
+	Signature  string
+	Position   Position
+	Complexity *Complexity
+}
+
 type Import struct {
-	Name   string
-	Path   string
-	StdLib bool
+	Name     string
+	Path     string
+	StdLib   bool
+	Position Position
 }
 
 type Field struct {
-	Name string
-	Type string
+	Name     string
+	Type     string
+	Position Position
 }
 
 func (f *Field) Visibility() Visibility {
@@ -70,6 +128,12 @@ type File struct {
 	BuildConstraints []string
 	Structs          []*Struct
 	Imports          []*Import
+	Functions        []*Function
+	Methods          []*Method
+	Interfaces       []*Interface
+	Types            []*NamedType
+	Lines            LineCounts
+	Position         Position
 }
 
 type Directory struct {
@@ -81,6 +145,7 @@ type Package struct {
 	Name       string
 	Path       string
 	ModulePath string
+	Doc        string
 	Files      []*File
 }
 
@@ -90,6 +155,10 @@ type Config struct {
 	Select        map[string]struct{}
 	ExcludeStdLib bool
 	IncludeTests  bool
+	Requires      []string
+	GOOS          string
+	GOARCH        string
+	BuildTags     []string
 }
 
 type Set map[string]struct{}
@@ -144,27 +213,55 @@ func ParsePackage(directory *Directory, module, target string, config *Config) e
 		}
 
 		var files []*File
+		docs := map[string]string{}
 
 		for fileName, astFile := range astPkg.Files {
 			f := &File{
-				Imports: []*Import{},
+				Imports:    []*Import{},
+				Functions:  []*Function{},
+				Methods:    []*Method{},
+				Interfaces: []*Interface{},
+				Types:      []*NamedType{},
 			}
 			f.Path = fileName
-			structs := []*Struct{}
-			currentFile = fileName
-			methods := map[string][]*Method{}
+			f.Position = newPosition(fset, astFile.Package)
 
 			if bc, ok := buildConstraints(astFile); ok {
 				f.BuildConstraints = bc
 			}
 
+			ok, err := matchBuildTags(f, config)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+
+			if astFile.Doc != nil {
+				docs[fileName] = astFile.Doc.Text()
+			}
+
+			src, err := os.ReadFile(fileName)
+			if err != nil {
+				return err
+			}
+			f.Lines = countLines(fset, astFile, src)
+			structs := []*Struct{}
+			methods := map[string][]*Method{}
+
 			for _, node := range astFile.Imports {
 				name := ""
 				if node.Name != nil {
 					name = node.Name.Name
 				}
 				path := strings.ReplaceAll(node.Path.Value, "\"", "")
-				f.Imports = append(f.Imports, &Import{Name: name, Path: path, StdLib: isStdLib(path)})
+				f.Imports = append(f.Imports, &Import{
+					Name:     name,
+					Path:     path,
+					StdLib:   isStdLib(path),
+					Position: newPosition(fset, node.Pos()),
+				})
 			}
 
 			for _, node := range astFile.Decls {
@@ -172,48 +269,47 @@ func ParsePackage(directory *Directory, module, target string, config *Config) e
 				case *ast.GenDecl:
 					for _, spec := range v.Specs {
 						if ts, ok := spec.(*ast.TypeSpec); ok {
-							s := extractStruct(ts)
-							if s == nil {
+							if s := extractStruct(fset, ts); s != nil {
+								structs = append(structs, s)
 								continue
 							}
-							structs = append(structs, s)
+							if i := extractInterface(fset, ts); i != nil {
+								f.Interfaces = append(f.Interfaces, i)
+								continue
+							}
+							f.Types = append(f.Types, &NamedType{
+								Name:     ts.Name.Name,
+								Type:     getType(ts.Type),
+								Alias:    ts.Assign != token.NoPos,
+								Position: newPosition(fset, ts.Name.Pos()),
+							})
 						}
 					}
 				case *ast.FuncDecl:
 					if v.Recv == nil {
+						f.Functions = append(f.Functions, &Function{
+							Name:       v.Name.Name,
+							Signature:  funcSignature(v),
+							Position:   newPosition(fset, v.Name.Pos()),
+							Complexity: measureComplexity(fset, v),
+						})
 						continue
 					}
 
-					var receiver string
-					switch t := v.Recv.List[0].Type.(type) {
-					case *ast.StarExpr:
-						if indexExpr, ok := t.X.(*ast.IndexExpr); ok {
-							receiver = indexExpr.X.(*ast.Ident).Name
-							continue
-						}
-
-						receiver = t.X.(*ast.Ident).Name
-					case *ast.Ident:
-						receiver = t.Name
-					}
-					method := v.Name.Name
-					params := getCommaSeparated(v.Type.Params)
-					results := getCommaSeparated(v.Type.Results)
-
-					signature := fmt.Sprintf(
-						"%s(%s) %s",
-						method,
-						params,
-						results,
-					)
-
+					receiver := receiverName(v.Recv.List[0].Type)
 					if _, ok := methods[receiver]; !ok {
 						methods[receiver] = []*Method{}
 					}
 
-					methods[receiver] = append(methods[receiver], &Method{
-						Signature: signature,
-					})
+					method := &Method{
+						Signature:  funcSignature(v),
+						Receiver:   receiver,
+						Position:   newPosition(fset, v.Name.Pos()),
+						Complexity: measureComplexity(fset, v),
+					}
+
+					methods[receiver] = append(methods[receiver], method)
+					f.Methods = append(f.Methods, method)
 
 				default:
 					panic(fmt.Sprintf("unknown decl: %v", node))
@@ -238,6 +334,7 @@ func ParsePackage(directory *Directory, module, target string, config *Config) e
 			}
 		}
 
+		pkg.Doc = packageDoc(docs)
 		pkg.Files = files
 		directory.Packages[pkgName] = pkg
 	}
@@ -245,6 +342,46 @@ func ParsePackage(directory *Directory, module, target string, config *Config) e
 	return nil
 }
 
+func packageDoc(docs map[string]string) string {
+	var names []string
+	for name := range docs {
+		if filepath.Base(name) == "doc.go" {
+			return docs[name]
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	return docs[names[0]]
+}
+
+func receiverName(e ast.Expr) string {
+	switch t := e.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.ParenExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+func funcSignature(v *ast.FuncDecl) string {
+	return fmt.Sprintf(
+		"%s(%s) %s",
+		v.Name.Name,
+		getCommaSeparated(v.Type.Params),
+		getCommaSeparated(v.Type.Results),
+	)
+}
+
 func LoadPackages(path, module, target string) (map[string]*Directory, error) {
 	res := map[string]*Directory{}
 
@@ -253,9 +390,12 @@ func LoadPackages(path, module, target string) (map[string]*Directory, error) {
 		func(p string, info os.FileInfo, err error) error {
 			// TODO: don't follow symlinks
 			if err != nil {
-				panic(err)
+				return err
 			}
 			if info.IsDir() {
+				if p != path && info.Name() == "testdata" {
+					return filepath.SkipDir
+				}
 				if strings.Contains(p, "vendor") {
 					return nil
 				}
@@ -281,7 +421,7 @@ func LoadStructs(path string) ([]*Struct, error) {
 	fset := token.NewFileSet()
 	file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	var structs []*Struct
@@ -291,7 +431,7 @@ func LoadStructs(path string) ([]*Struct, error) {
 		case *ast.GenDecl:
 			for _, spec := range v.Specs {
 				if ts, ok := spec.(*ast.TypeSpec); ok {
-					s := extractStruct(ts)
+					s := extractStruct(fset, ts)
 					if s == nil {
 						continue
 					}
@@ -304,7 +444,7 @@ func LoadStructs(path string) ([]*Struct, error) {
 	return structs, nil
 }
 
-func extractStruct(n *ast.TypeSpec) *Struct {
+func extractStruct(fset *token.FileSet, n *ast.TypeSpec) *Struct {
 	s := &Struct{}
 
 	st, ok := n.Type.(*ast.StructType)
@@ -313,19 +453,22 @@ func extractStruct(n *ast.TypeSpec) *Struct {
 	}
 
 	s.Name = n.Name.Name
+	s.Position = newPosition(fset, n.Name.Pos())
 
 	for _, f := range st.Fields.List {
 		if len(f.Names) == 0 {
 			s.Fields = append(s.Fields, &Field{
-				Name: "",
-				Type: getType(f.Type),
+				Name:     "",
+				Type:     getType(f.Type),
+				Position: newPosition(fset, f.Type.Pos()),
 			})
 			continue
 		}
 		for _, n := range f.Names {
 			s.Fields = append(s.Fields, &Field{
-				Name: n.Name,
-				Type: getType(f.Type),
+				Name:     n.Name,
+				Type:     getType(f.Type),
+				Position: newPosition(fset, n.Pos()),
 			})
 		}
 	}
@@ -333,6 +476,39 @@ func extractStruct(n *ast.TypeSpec) *Struct {
 	return s
 }
 
+func extractInterface(fset *token.FileSet, n *ast.TypeSpec) *Interface {
+	it, ok := n.Type.(*ast.InterfaceType)
+	if !ok {
+		return nil
+	}
+
+	i := &Interface{
+		Name:     n.Name.Name,
+		Position: newPosition(fset, n.Name.Pos()),
+	}
+
+	for _, m := range it.Methods.List {
+		ft, ok := m.Type.(*ast.FuncType)
+		if !ok || len(m.Names) == 0 {
+			i.Embedded = append(i.Embedded, getType(m.Type))
+			continue
+		}
+		for _, name := range m.Names {
+			i.Methods = append(i.Methods, &Method{
+				Signature: fmt.Sprintf(
+					"%s(%s) %s",
+					name.Name,
+					getCommaSeparated(ft.Params),
+					getCommaSeparated(ft.Results),
+				),
+				Position: newPosition(fset, name.Pos()),
+			})
+		}
+	}
+
+	return i
+}
+
 func isPublic(name, typ string) bool {
 	if len(name) > 0 {
 		return ast.IsExported(name)
@@ -345,7 +521,15 @@ func getType(e ast.Expr) string {
 	case *ast.Ident:
 		return v.Name
 	case *ast.ArrayType:
-		return fmt.Sprintf("[]%s", getType(v.Elt))
+		switch v.Len.(type) {
+		case nil:
+			return fmt.Sprintf("[]%s", getType(v.Elt))
+		case *ast.Ellipsis:
+			return fmt.Sprintf("[...]%s", getType(v.Elt))
+		}
+		return fmt.Sprintf("[%s]%s", getType(v.Len), getType(v.Elt))
+	case *ast.BasicLit:
+		return v.Value
 	case *ast.StarExpr:
 		return fmt.Sprintf("*%s", getType(v.X))
 	case *ast.SelectorExpr:
@@ -374,11 +558,21 @@ func getType(e ast.Expr) string {
 	case *ast.StructType:
 		return fmt.Sprintf("struct{ %s }", getStructFields(v.Fields))
 	case *ast.IndexExpr:
-		return "TODO: INDEX EXPR"
+		return fmt.Sprintf("%s[%s]", getType(v.X), getType(v.Index))
 	case *ast.Ellipsis:
 		return fmt.Sprintf("...%s", getType(v.Elt))
 	case *ast.ParenExpr:
-		return "TODO: PAREN EXPR"
+		return fmt.Sprintf("(%s)", getType(v.X))
+	case *ast.IndexListExpr:
+		indices := make([]string, 0, len(v.Indices))
+		for _, i := range v.Indices {
+			indices = append(indices, getType(i))
+		}
+		return fmt.Sprintf("%s[%s]", getType(v.X), strings.Join(indices, ", "))
+	case *ast.UnaryExpr:
+		return fmt.Sprintf("%s%s", v.Op, getType(v.X))
+	case *ast.BinaryExpr:
+		return fmt.Sprintf("%s %s %s", getType(v.X), v.Op, getType(v.Y))
 	default:
 		panic(fmt.Sprintf("unhandled type: %#v", e))
 	}
@@ -418,32 +612,18 @@ func getStructFields(fl *ast.FieldList) string {
 	return strings.Join(fields, ", ")
 }
 
-func FormatPackages(directories map[string]*Directory) string {
+const structNodeDefaults = "    node [style=filled, shape=plaintext, pencolor=\"#00000044\", fontname=\"Helvetica,Arial,sans-serif\"]\n"
+
+func FormatPackages(directories map[string]*Directory, style *DiagramStyle) string {
 	var sb strings.Builder
 	sb.WriteString("digraph {\n")
-	sb.WriteString(`    graph [
-        label = "Basic git concepts and operations\n\n"
-        labelloc = t
-        fontname = "Helvetica,Arial,sans-serif"
-        fontsize = 20
-        layout = dot
-        rankdir = LR
-        newrank = true
-    ]
-
-    node [
-        style=filled
-        shape=rect
-        pencolor="#00000044" // frames color
-        fontname="Helvetica,Arial,sans-serif"
-        shape=plaintext
-    ]
-`)
+	sb.WriteString(structNodeDefaults)
+	writeDotHeader(&sb, style)
 
 	for _, directory := range DirectoryMap(directories).SortedDirectories() {
 		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
 			sb.WriteString(fmt.Sprintf("\n    subgraph cluster_%s {", normalizePackageName(directory.Path)))
-			sb.WriteString(fmt.Sprintf("\n        label = \"%s\"", directory.Path))
+			sb.WriteString(fmt.Sprintf("\n        label = \"%s\"", dotQuote(directory.Path)))
 			sb.WriteString("\n")
 			for _, f := range pkg.Files {
 				for _, s := range f.Structs {
@@ -459,27 +639,11 @@ func FormatPackages(directories map[string]*Directory) string {
 	return sb.String()
 }
 
-func Format(structs []*Struct) string {
+func Format(structs []*Struct, style *DiagramStyle) string {
 	var sb strings.Builder
 	sb.WriteString("digraph {\n")
-	sb.WriteString(`    graph [
-        label = "Basic git concepts and operations\n\n"
-        labelloc = t
-        fontname = "Helvetica,Arial,sans-serif"
-        fontsize = 20
-        layout = dot
-        rankdir = LR
-        newrank = true
-    ]
-
-    node [
-        style=filled
-        shape=rect
-        pencolor="#00000044" // frames color
-        fontname="Helvetica,Arial,sans-serif"
-        shape=plaintext
-    ]`)
-	sb.WriteString("\n")
+	sb.WriteString(structNodeDefaults)
+	writeDotHeader(&sb, style)
 	for _, s := range structs {
 		formatStruct(&sb, s, false)
 	}
@@ -515,19 +679,12 @@ func formatStruct(sb *strings.Builder, s *Struct, indent bool) {
             </td></tr>
         </table>>
         shape=plain
-    ]`, s.Name, s.Name, formatStructFields(s), formatStructMethods(s))))
+    ]`, dotQuote(s.Name), xmlEscape(s.Name), formatStructFields(s), formatStructMethods(s))))
 	sb.WriteString("\n")
 }
 
 const tab = "    "
 
-func escape(v string) string {
-	if v == "" {
-		return v
-	}
-	return strings.ReplaceAll(strings.ReplaceAll(v, "<", "&lt;"), ">", "&gt;")
-}
-
 func formatStructFields(s *Struct) string {
 	var sb strings.Builder
 
@@ -536,15 +693,15 @@ func formatStructFields(s *Struct) string {
 			sb.WriteString(fmt.Sprintf(
 				"\n%s%s<br/>",
 				strings.Repeat(tab, 4),
-				escape(f.Type),
+				xmlEscape(f.Type),
 			))
 			continue
 		}
 		sb.WriteString(fmt.Sprintf(
 			"\n%s%s %s<br/>",
 			strings.Repeat(tab, 4),
-			escape(f.Name),
-			escape(f.Type),
+			xmlEscape(f.Name),
+			xmlEscape(f.Type),
 		))
 	}
 
@@ -558,7 +715,7 @@ func formatStructMethods(s *Struct) string {
 		sb.WriteString(fmt.Sprintf(
 			"\n%s%s<br/>",
 			strings.Repeat(tab, 4),
-			strings.ReplaceAll(strings.ReplaceAll(m.Signature, "<", "&lt;"), ">", "&gt;"),
+			xmlEscape(m.Signature),
 		))
 	}
 
@@ -581,85 +738,41 @@ func normalizePackageName(name string) string {
 	return strings.ReplaceAll(strings.ReplaceAll(strings.ReplaceAll(name, "/", "_"), ".", "_"), "-", "_")
 }
 
-func FormatImports(directories map[string]*Directory) string {
-	var sb strings.Builder
-	sb.WriteString("digraph {\n")
-	sb.WriteString("    rankdir=\"LR\"\n\n")
-
-	for _, directory := range DirectoryMap(directories).SortedDirectories() {
-		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
-			if isFake(pkg.Name) || isMock(pkg.Name) || isTest(pkg.Name) {
-				continue
-			}
-
-			unique := map[string]struct{}{}
-			for _, f := range pkg.Files {
-				for _, i := range f.Imports {
-					if _, ok := unique[i.Path]; !ok {
-						unique[i.Path] = struct{}{}
-					}
-				}
-			}
-			sortedUnique := make([]string, 0, len(unique))
-			for i, _ := range unique {
-				sortedUnique = append(sortedUnique, i)
-			}
-			sort.Strings(sortedUnique)
-
-			for _, i := range sortedUnique {
-				if isStdLib(strings.ReplaceAll(i, "\"", "")) {
-					continue
-				}
-
-				sb.WriteString(fmt.Sprintf("    \"%s\" -> \"%s\"\n", pkg.ModulePath, i))
-			}
-		}
-	}
-	sb.WriteString("}\n")
-	return sb.String()
+type ImportCount struct {
+	Path     string
+	Count    int
+	Position Position
 }
 
-func FormatImportsTable(directories map[string]*Directory, module string, config *Config) string {
-	var sb strings.Builder
-
-	type Stat struct {
-		Path  string
-		Count int
-	}
-
-	stats := map[string]Stat{}
+func FindImportCounts(directories map[string]*Directory) []*ImportCount {
+	stats := map[string]*ImportCount{}
 
 	for _, directory := range directories {
 		for _, pkg := range directory.Packages {
-			unique := map[string]struct{}{}
+			unique := map[string]Position{}
 			for _, f := range pkg.Files {
 				for _, i := range f.Imports {
-					if _, ok := unique[i.Path]; !ok {
-						unique[i.Path] = struct{}{}
+					if pos, ok := unique[i.Path]; !ok || i.Position.Before(pos) {
+						unique[i.Path] = i.Position
 					}
 				}
 			}
 
-			for p, _ := range unique {
-				if _, ok := stats[p]; ok {
-					stat := stats[p]
+			for p, pos := range unique {
+				if stat, ok := stats[p]; ok {
 					stat.Count++
-					stats[p] = stat
+					if pos.Before(stat.Position) {
+						stat.Position = pos
+					}
 				} else {
-					stats[p] = Stat{Path: p, Count: 1}
+					stats[p] = &ImportCount{Path: p, Count: 1, Position: pos}
 				}
 			}
 		}
 	}
 
-	sortedStats := make([]Stat, 0, len(stats))
-
-	max := 0
-
+	sortedStats := make([]*ImportCount, 0, len(stats))
 	for _, stat := range stats {
-		if stat.Count > max {
-			max = stat.Count
-		}
 		sortedStats = append(sortedStats, stat)
 	}
 
@@ -670,12 +783,32 @@ func FormatImportsTable(directories map[string]*Directory, module string, config
 		return sortedStats[i].Count > sortedStats[j].Count
 	})
 
-	for _, stat := range sortedStats {
+	return sortedStats
+}
+
+type ImportsTableOptions struct {
+	Positions bool
+}
+
+func FormatImportsTable(directories map[string]*Directory, module string, config *Config, opts *ImportsTableOptions) string {
+	var sb strings.Builder
+
+	stats := FindImportCounts(directories)
+
+	max := 0
+	for _, stat := range stats {
+		if stat.Count > max {
+			max = stat.Count
+		}
+	}
+
+	for _, stat := range stats {
 		if config.ExcludeStdLib && isStdLib(stat.Path) {
 			continue
 		}
 		sb.WriteString(fmt.Sprintf(
-			"%*d %s\n",
+			"%s%*d %s\n",
+			formatPosition(opts.Positions, stat.Position),
 			digitsCount(max), stat.Count, colorize(stat.Path, module),
 		))
 	}
@@ -704,13 +837,21 @@ func maybeAddBuildConstraint(f *File) string {
 	return ""
 }
 
-func formatStructForConsole(s *Struct, f *File) string {
+func formatPosition(positions bool, p Position) string {
+	if !positions || !p.IsValid() {
+		return ""
+	}
+	return fmt.Sprintf("%s: ", p)
+}
+
+func formatStructForConsole(s *Struct, f *File, embedding *Embedding, opts *TypesOptions) string {
 	var sb strings.Builder
 
 	// TODO: sort fields and methods by visibility (or alphabetically, or do no sorting optionally)
 	// TODO: move visibility logic to fields and methods parsing
 	sb.WriteString(fmt.Sprintf(
-		"%stype %s%s%s {%s\n",
+		"%s%stype %s%s%s {%s\n",
+		formatPosition(opts.Positions, s.Position),
 		formatTokenVisibility(s.Name), Blue, s.Name, NoColor,
 		maybeAddBuildConstraint(f),
 	))
@@ -719,86 +860,112 @@ func formatStructForConsole(s *Struct, f *File) string {
 	sort.Sort(ByMethodVisibility(s.Methods))
 
 	for _, f := range s.Fields {
+		pos := formatPosition(opts.Positions, f.Position)
 		if f.Name == "" {
-			sb.WriteString(fmt.Sprintf("    %s%s\n", formatStructFieldVisibility(f), f.Type))
+			sb.WriteString(fmt.Sprintf("%s    %s%s\n", pos, formatStructFieldVisibility(f), f.Type))
 		} else {
-			sb.WriteString(fmt.Sprintf("    %s%s %s\n", formatStructFieldVisibility(f), f.Name, f.Type))
+			sb.WriteString(fmt.Sprintf("%s    %s%s %s\n", pos, formatStructFieldVisibility(f), f.Name, f.Type))
 		}
 	}
 	if len(s.Fields) > 0 && len(s.Methods) > 0 {
 		sb.WriteString("\n")
 	}
 	for _, m := range s.Methods {
-		sb.WriteString(fmt.Sprintf("    %s%s\n", formatTokenVisibility(m.Signature), m.Signature))
+		sb.WriteString(fmt.Sprintf(
+			"%s    %s%s\n",
+			formatPosition(opts.Positions, m.Position), formatTokenVisibility(m.Signature), m.Signature,
+		))
 	}
-	sb.WriteString("}\n")
-
-	return sb.String()
-}
-
-func FormatEntrypoints(directories map[string]*Directory, module string) string {
-	var sb strings.Builder
-
-	for _, directory := range DirectoryMap(directories).SortedDirectories() {
-		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
-			for _, f := range pkg.Files {
-				if strings.HasSuffix(f.Path, "/main.go") {
-					sb.WriteString(fmt.Sprintf("%s %s%s%s\n", pkg.ModulePath, Red, strings.Join(f.BuildConstraints, ","), NoColor))
-				}
-			}
+	if embedding != nil && len(embedding.Promoted) > 0 {
+		if len(s.Fields) > 0 || len(s.Methods) > 0 {
+			sb.WriteString("\n")
 		}
+		for _, p := range embedding.Promoted {
+			sb.WriteString(formatPromoted(p, opts.Positions))
+		}
+	}
+	sb.WriteString("}\n")
+	if embedding != nil && len(embedding.Embedded) > 0 {
+		sb.WriteString(formatEmbeddingTree(embedding))
 	}
 
 	return sb.String()
 }
 
-func FormatStats(directories map[string]*Directory, module string) string {
-	var sb strings.Builder
+type Stats struct {
+	Packages                  int
+	Files                     int
+	SourceFiles               int
+	TestFiles                 int
+	FilesWithBuildConstraints int
+	Structs                   int
+	Entrypoints               int
+	SourceLines               LineCounts
+	TestLines                 LineCounts
+}
 
-	packagesCount := 0
-	filesCount := 0
-	testFilesCount := 0
-	sourceFilesCount := 0
-	filesWithBuildConstraints := 0
-	structsCount := 0
-	entrypointsCount := 0
+func FindStats(directories map[string]*Directory) *Stats {
+	res := &Stats{}
 
 	for _, directory := range DirectoryMap(directories).SortedDirectories() {
 		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
-			packagesCount++
+			res.Packages++
 			for _, f := range pkg.Files {
-				_ = f
-				filesCount++
+				res.Files++
 				if strings.HasSuffix(f.Path, "_test.go") {
-					testFilesCount++
+					res.TestFiles++
+					res.TestLines.Add(f.Lines)
+				} else {
+					res.SourceLines.Add(f.Lines)
 				}
 				if len(f.BuildConstraints) > 0 {
-					filesWithBuildConstraints++
-				}
-				if strings.HasSuffix(f.Path, "/main.go") {
-					entrypointsCount++
+					res.FilesWithBuildConstraints++
 				}
-				structsCount += len(f.Structs)
+				res.Structs += len(f.Structs)
 			}
 		}
 	}
 
-	sourceFilesCount = filesCount - testFilesCount
+	res.SourceFiles = res.Files - res.TestFiles
 
-	type Stat struct {
-		Name   string
-		Number int
+	binaries := map[string]struct{}{}
+	for _, e := range FindEntrypoints(directories) {
+		if e.IsBinary() {
+			binaries[e.Package.ModulePath] = struct{}{}
+		}
 	}
+	res.Entrypoints = len(binaries)
+
+	return res
+}
 
-	stats := []Stat{
-		{"packages count", packagesCount},
-		{"files count", filesCount},
-		{"source files", sourceFilesCount},
-		{"test files", testFilesCount},
-		{"files with build constraints", filesWithBuildConstraints},
-		{"structs count", structsCount},
-		{"entrypoints count", entrypointsCount},
+type StatRow struct {
+	Name   string
+	Number int
+}
+
+func (s *Stats) Rows() []StatRow {
+	return []StatRow{
+		{"packages count", s.Packages},
+		{"files count", s.Files},
+		{"source files", s.SourceFiles},
+		{"test files", s.TestFiles},
+		{"files with build constraints", s.FilesWithBuildConstraints},
+		{"structs count", s.Structs},
+		{"entrypoints count", s.Entrypoints},
+		{"source code lines", s.SourceLines.Code},
+		{"source comment lines", s.SourceLines.Comment},
+		{"source blank lines", s.SourceLines.Blank},
+		{"test code lines", s.TestLines.Code},
+		{"test comment lines", s.TestLines.Comment},
+		{"test blank lines", s.TestLines.Blank},
 	}
+}
+
+func FormatStats(directories map[string]*Directory, module string) string {
+	var sb strings.Builder
+
+	stats := FindStats(directories).Rows()
 
 	max := 0
 	for _, s := range stats {
@@ -815,9 +982,28 @@ func FormatStats(directories map[string]*Directory, module string) string {
 	return sb.String()
 }
 
-func FormatTypes(directories map[string]*Directory, module string) string {
+type TypesOptions struct {
+	Embedded  bool
+	Positions bool
+}
+
+func FormatTypes(directories map[string]*Directory, module string, config *Config, opts *TypesOptions) string {
 	var sb strings.Builder
 
+	embeddings := map[*Struct]*Embedding{}
+	if opts.Embedded {
+		index := NewTypeIndex(directories)
+		for _, directory := range directories {
+			for _, pkg := range directory.Packages {
+				for _, f := range pkg.Files {
+					for _, s := range f.Structs {
+						embeddings[s] = index.Embedding(pkg, f, s)
+					}
+				}
+			}
+		}
+	}
+
 	for _, directory := range DirectoryMap(directories).SortedDirectories() {
 		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
 			sb.WriteString(fmt.Sprintf("%s%s%s\n", Yellow, pkg.ModulePath, NoColor))
@@ -830,7 +1016,7 @@ func FormatTypes(directories map[string]*Directory, module string) string {
 				sort.Sort(ByStructName(f.Structs))
 
 				for _, s := range f.Structs {
-					sb.WriteString(fmt.Sprintf("\n%s", formatStructForConsole(s, f)))
+					sb.WriteString(fmt.Sprintf("\n%s", formatStructForConsole(s, f, embeddings[s], opts)))
 					pkgEmpty = false
 				}
 			}
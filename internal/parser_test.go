@@ -3,6 +3,7 @@ package internal_test
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
@@ -13,28 +14,32 @@ func TestLoadStructs(t *testing.T) {
 	actual, err := internal.LoadStructs("../examples/factory.go")
 	expected := []*internal.Struct{
 		{
-			Name: "Factory",
+			Name:     "Factory",
+			Position: pos("../examples/factory.go", 7, 6),
 			Fields: []*internal.Field{
-				{Name: "Name", Type: "string"},
+				{Name: "Name", Type: "string", Position: pos("../examples/factory.go", 8, 2)},
 			},
 		},
 		{
-			Name: "Mechanic",
+			Name:     "Mechanic",
+			Position: pos("../examples/factory.go", 11, 6),
 			Fields: []*internal.Field{
-				{Name: "Skills", Type: "[]string"},
-				{Name: "Colleagues", Type: "[]*Mechanic"},
+				{Name: "Skills", Type: "[]string", Position: pos("../examples/factory.go", 12, 2)},
+				{Name: "Colleagues", Type: "[]*Mechanic", Position: pos("../examples/factory.go", 13, 2)},
 			},
 		},
 		{
-			Name: "Manager",
+			Name:     "Manager",
+			Position: pos("../examples/factory.go", 16, 6),
 			Fields: []*internal.Field{
-				{Name: "Pointer", Type: "*Mechanic"},
+				{Name: "Pointer", Type: "*Mechanic", Position: pos("../examples/factory.go", 17, 2)},
 			},
 		},
 		{
-			Name: "tool",
+			Name:     "tool",
+			Position: pos("../examples/factory.go", 20, 6),
 			Fields: []*internal.Field{
-				{Name: "name", Type: "string"},
+				{Name: "name", Type: "string", Position: pos("../examples/factory.go", 21, 2)},
 			},
 		},
 	}
@@ -47,23 +52,8 @@ func TestFormatStructs(t *testing.T) {
 	assertEqual(t, nil, err)
 
 	expected := `digraph {
-    graph [
-        label = "Basic git concepts and operations\n\n"
-        labelloc = t
-        fontname = "Helvetica,Arial,sans-serif"
-        fontsize = 20
-        layout = dot
-        rankdir = LR
-        newrank = true
-    ]
-
-    node [
-        style=filled
-        shape=rect
-        pencolor="#00000044" // frames color
-        fontname="Helvetica,Arial,sans-serif"
-        shape=plaintext
-    ]
+    node [style=filled, shape=plaintext, pencolor="#00000044", fontname="Helvetica,Arial,sans-serif"]
+    rankdir="LR"
 
     "Factory" [
         fillcolor="#88ff0022"
@@ -119,7 +109,7 @@ func TestFormatStructs(t *testing.T) {
     ]
 }
 `
-	actualLines := strings.Split(internal.Format(actual), "\n")
+	actualLines := strings.Split(internal.Format(actual, nil), "\n")
 	expectedLines := strings.Split(expected, "\n")
 
 	assertEqual(t, len(expectedLines), len(actualLines))
@@ -128,7 +118,31 @@ func TestFormatStructs(t *testing.T) {
 	}
 }
 
+func TestLoadPackagesSkipsTestdata(t *testing.T) {
+	directories, err := internal.LoadPackages("testdata/skip", "example.com/skip", "testdata/skip")
+	assertEqual(t, nil, err)
+
+	var paths []string
+	for p := range directories {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	assertEqual(t, []string{"testdata/skip", "testdata/skip/api"}, paths)
+}
+
 func TestLoadPackages(t *testing.T) {
+	startEngine := &internal.Method{
+		Signature:  "StartEngine() error",
+		Receiver:   "Vehicle",
+		Position:   pos("../examples/other/vehicle.go", 7, 19),
+		Complexity: &internal.Complexity{Cyclomatic: 1, Lines: 3},
+	}
+	stopEngine := &internal.Method{
+		Signature:  "StopEngine() error",
+		Receiver:   "Vehicle",
+		Position:   pos("../examples/other/vehicle.go", 11, 18),
+		Complexity: &internal.Complexity{Cyclomatic: 1, Lines: 3},
+	}
 	actual, err := internal.LoadPackages("../examples", "", "")
 	for _, p := range actual {
 		err := internal.ParsePackage(p, "", "", &internal.Config{})
@@ -143,32 +157,39 @@ func TestLoadPackages(t *testing.T) {
 					ModulePath: "../examples/cars",
 					Files: []*internal.File{
 						{
-							Path: "../examples/cars/car.go",
+							Path:       "../examples/cars/car.go",
+							Position:   pos("../examples/cars/car.go", 1, 1),
+							Lines:      internal.LineCounts{Code: 22, Comment: 1, Blank: 5},
+							Methods:    []*internal.Method{},
+							Interfaces: []*internal.Interface{},
+							Types:      []*internal.NamedType{},
+							Functions:  []*internal.Function{},
 							Imports: []*internal.Import{
-								{Name: "", Path: "sync", StdLib: true},
-								{Name: "", Path: "github.com/slavsan/godiss/examples/other", StdLib: false},
+								{Name: "", Path: "sync", StdLib: true, Position: pos("../examples/cars/car.go", 4, 2)},
+								{Name: "", Path: "github.com/slavsan/godiss/examples/other", StdLib: false, Position: pos("../examples/cars/car.go", 6, 2)},
 							},
 							Structs: []*internal.Struct{
 								{
-									Name: "Camaro",
+									Name:     "Camaro",
+									Position: pos("../examples/cars/car.go", 9, 6),
 									Fields: []*internal.Field{
-										{Name: "", Type: "other.Vehicle"},
-										{Name: "Name", Type: "string"},
-										{Name: "Features", Type: "map[string]int"},
-										{Name: "Callback", Type: "func(string, int) (int64, error)"},
-										{Name: "Fuel", Type: "interface{}"},
-										{Name: "ChNoPos", Type: "chan string"},
-										{Name: "ChRecv", Type: "<-chan int32"},
-										{Name: "ChSend", Type: "chan<- int32"},
-										{Name: "Struct", Type: "struct{ XXX int }"},
-										{Name: "One", Type: "string"},
-										{Name: "Two", Type: "string"},
-										{Name: "Ellipsis", Type: "func(...string)"},
-										{Name: "ExampleMutex", Type: "func(sync.Mutex)"},
-										{Name: "Three", Type: "sync.Mutex"},
-										{Name: "Four", Type: "sync.Mutex"},
-										{Name: "AnotherStruct", Type: "struct{  sync.Mutex }"},
-										{Name: "", Type: "sync.Mutex"},
+										{Name: "", Type: "other.Vehicle", Position: pos("../examples/cars/car.go", 10, 2)},
+										{Name: "Name", Type: "string", Position: pos("../examples/cars/car.go", 12, 2)},
+										{Name: "Features", Type: "map[string]int", Position: pos("../examples/cars/car.go", 13, 2)},
+										{Name: "Callback", Type: "func(string, int) (int64, error)", Position: pos("../examples/cars/car.go", 14, 2)},
+										{Name: "Fuel", Type: "interface{}", Position: pos("../examples/cars/car.go", 15, 2)},
+										{Name: "ChNoPos", Type: "chan string", Position: pos("../examples/cars/car.go", 16, 2)},
+										{Name: "ChRecv", Type: "<-chan int32", Position: pos("../examples/cars/car.go", 17, 2)},
+										{Name: "ChSend", Type: "chan<- int32", Position: pos("../examples/cars/car.go", 18, 2)},
+										{Name: "Struct", Type: "struct{ XXX int }", Position: pos("../examples/cars/car.go", 19, 2)},
+										{Name: "One", Type: "string", Position: pos("../examples/cars/car.go", 20, 2)},
+										{Name: "Two", Type: "string", Position: pos("../examples/cars/car.go", 20, 7)},
+										{Name: "Ellipsis", Type: "func(...string)", Position: pos("../examples/cars/car.go", 21, 2)},
+										{Name: "ExampleMutex", Type: "func(sync.Mutex)", Position: pos("../examples/cars/car.go", 22, 2)},
+										{Name: "Three", Type: "sync.Mutex", Position: pos("../examples/cars/car.go", 23, 2)},
+										{Name: "Four", Type: "sync.Mutex", Position: pos("../examples/cars/car.go", 23, 9)},
+										{Name: "AnotherStruct", Type: "struct{  sync.Mutex }", Position: pos("../examples/cars/car.go", 24, 2)},
+										{Name: "", Type: "sync.Mutex", Position: pos("../examples/cars/car.go", 27, 2)},
 									},
 								},
 							},
@@ -181,13 +202,20 @@ func TestLoadPackages(t *testing.T) {
 					Files: []*internal.File{
 						{
 							Path:             "../examples/cars/main.go",
+							Position:         pos("../examples/cars/main.go", 4, 1),
+							Lines:            internal.LineCounts{Code: 4, Comment: 2, Blank: 2},
+							Methods:          []*internal.Method{},
+							Interfaces:       []*internal.Interface{},
+							Types:            []*internal.NamedType{},
 							Imports:          []*internal.Import{},
+							Functions:        []*internal.Function{},
 							BuildConstraints: []string{"mytag"},
 							Structs: []*internal.Struct{
 								{
-									Name: "Foo",
+									Name:     "Foo",
+									Position: pos("../examples/cars/main.go", 6, 6),
 									Fields: []*internal.Field{
-										{Name: "Bar", Type: "string"},
+										{Name: "Bar", Type: "string", Position: pos("../examples/cars/main.go", 7, 2)},
 									},
 								},
 							},
@@ -204,17 +232,31 @@ func TestLoadPackages(t *testing.T) {
 					ModulePath: "../examples/other",
 					Files: []*internal.File{
 						{
-							Path:    "../examples/other/vehicle.go",
-							Imports: []*internal.Import{},
+							Path:       "../examples/other/vehicle.go",
+							Position:   pos("../examples/other/vehicle.go", 1, 1),
+							Lines:      internal.LineCounts{Code: 13, Comment: 0, Blank: 4},
+							Interfaces: []*internal.Interface{},
+							Types:      []*internal.NamedType{},
+							Imports:    []*internal.Import{},
+							Methods:    []*internal.Method{startEngine, stopEngine},
+							Functions: []*internal.Function{
+								{
+									Name:       "RateVehicle",
+									Signature:  "RateVehicle() int",
+									Position:   pos("../examples/other/vehicle.go", 15, 6),
+									Complexity: &internal.Complexity{Cyclomatic: 1, Lines: 3},
+								},
+							},
 							Structs: []*internal.Struct{
 								{
-									Name: "Vehicle",
+									Name:     "Vehicle",
+									Position: pos("../examples/other/vehicle.go", 3, 6),
 									Fields: []*internal.Field{
-										{Name: "Doors", Type: "int"},
+										{Name: "Doors", Type: "int", Position: pos("../examples/other/vehicle.go", 4, 2)},
 									},
 									Methods: []*internal.Method{
-										{Signature: "StartEngine() error"},
-										{Signature: "StopEngine() error"},
+										startEngine,
+										stopEngine,
 									},
 								},
 							},
@@ -231,34 +273,53 @@ func TestLoadPackages(t *testing.T) {
 					ModulePath: "../examples",
 					Files: []*internal.File{
 						{
-							Path: "../examples/factory.go",
+							Path:      "../examples/factory.go",
+							Position:  pos("../examples/factory.go", 1, 1),
+							Lines:     internal.LineCounts{Code: 21, Comment: 0, Blank: 6},
+							Methods:   []*internal.Method{},
+							Functions: []*internal.Function{},
+							Interfaces: []*internal.Interface{
+								{
+									Name:     "IMechanic",
+									Position: pos("../examples/factory.go", 24, 6),
+									Methods: []*internal.Method{
+										{Signature: "DoWork() ", Position: pos("../examples/factory.go", 25, 2)},
+										{Signature: "BuildCamaro() *carmodel.Camaro, error", Position: pos("../examples/factory.go", 26, 2)},
+									},
+								},
+							},
+							Types: []*internal.NamedType{},
 							Imports: []*internal.Import{
-								{Name: "carmodel", Path: "github.com/slavsan/godiss/examples/cars", StdLib: false},
+								{Name: "carmodel", Path: "github.com/slavsan/godiss/examples/cars", StdLib: false, Position: pos("../examples/factory.go", 4, 2)},
 							},
 							Structs: []*internal.Struct{
 								{
-									Name: "Factory",
+									Name:     "Factory",
+									Position: pos("../examples/factory.go", 7, 6),
 									Fields: []*internal.Field{
-										{Name: "Name", Type: "string"},
+										{Name: "Name", Type: "string", Position: pos("../examples/factory.go", 8, 2)},
 									},
 								},
 								{
-									Name: "Mechanic",
+									Name:     "Mechanic",
+									Position: pos("../examples/factory.go", 11, 6),
 									Fields: []*internal.Field{
-										{Name: "Skills", Type: "[]string"},
-										{Name: "Colleagues", Type: "[]*Mechanic"},
+										{Name: "Skills", Type: "[]string", Position: pos("../examples/factory.go", 12, 2)},
+										{Name: "Colleagues", Type: "[]*Mechanic", Position: pos("../examples/factory.go", 13, 2)},
 									},
 								},
 								{
-									Name: "Manager",
+									Name:     "Manager",
+									Position: pos("../examples/factory.go", 16, 6),
 									Fields: []*internal.Field{
-										{Name: "Pointer", Type: "*Mechanic"},
+										{Name: "Pointer", Type: "*Mechanic", Position: pos("../examples/factory.go", 17, 2)},
 									},
 								},
 								{
-									Name: "tool",
+									Name:     "tool",
+									Position: pos("../examples/factory.go", 20, 6),
 									Fields: []*internal.Field{
-										{Name: "name", Type: "string"},
+										{Name: "name", Type: "string", Position: pos("../examples/factory.go", 21, 2)},
 									},
 								},
 							},
@@ -280,23 +341,8 @@ func TestFormatPackages(t *testing.T) {
 		assertEqual(t, nil, err)
 	}
 	expected := `digraph {
-    graph [
-        label = "Basic git concepts and operations\n\n"
-        labelloc = t
-        fontname = "Helvetica,Arial,sans-serif"
-        fontsize = 20
-        layout = dot
-        rankdir = LR
-        newrank = true
-    ]
-
-    node [
-        style=filled
-        shape=rect
-        pencolor="#00000044" // frames color
-        fontname="Helvetica,Arial,sans-serif"
-        shape=plaintext
-    ]
+    node [style=filled, shape=plaintext, pencolor="#00000044", fontname="Helvetica,Arial,sans-serif"]
+    rankdir="LR"
 
     subgraph cluster____examples {
         label = "../examples"
@@ -426,7 +472,7 @@ func TestFormatPackages(t *testing.T) {
 }
 `
 
-	actualLines := strings.Split(internal.FormatPackages(actual), "\n")
+	actualLines := strings.Split(internal.FormatPackages(actual, nil), "\n")
 	expectedLines := strings.Split(expected, "\n")
 
 	assertEqual(t, len(expectedLines), len(actualLines))
@@ -450,7 +496,7 @@ func TestFormatImports(t *testing.T) {
 }
 `
 
-	actualLines := strings.Split(internal.FormatImports(actual), "\n")
+	actualLines := strings.Split(internal.FormatImports(actual, "", &internal.Config{ExcludeStdLib: true}, &internal.ImportsOptions{}), "\n")
 	expectedLines := strings.Split(expected, "\n")
 
 	assertEqual(t, len(expectedLines), len(actualLines))
@@ -471,7 +517,7 @@ func TestFormatImportsTable(t *testing.T) {
 		fmt.Sprintf("1 %sgithub.com/slavsan/godiss/examples/other%s\n", internal.Green, internal.NoColor) +
 		fmt.Sprintf("1 %ssync%s\n", internal.Yellow, internal.NoColor)
 
-	actualLines := strings.Split(internal.FormatImportsTable(actual, "github.com/slavsan/godiss", &internal.Config{}), "\n")
+	actualLines := strings.Split(internal.FormatImportsTable(actual, "github.com/slavsan/godiss", &internal.Config{}, &internal.ImportsTableOptions{}), "\n")
 	expectedLines := strings.Split(expected, "\n")
 
 	assertEqual(t, len(expectedLines), len(actualLines))
@@ -480,6 +526,53 @@ func TestFormatImportsTable(t *testing.T) {
 	}
 }
 
+func TestFormatImportsTableWithPositions(t *testing.T) {
+	actual, err := internal.LoadPackages("../examples", "", "")
+	assertEqual(t, nil, err)
+	for _, p := range actual {
+		err := internal.ParsePackage(p, "", "", &internal.Config{})
+		assertEqual(t, nil, err)
+	}
+	expected := "" +
+		fmt.Sprintf("../examples/factory.go:4:2: 1 %sgithub.com/slavsan/godiss/examples/cars%s\n", internal.Green, internal.NoColor) +
+		fmt.Sprintf("../examples/cars/car.go:6:2: 1 %sgithub.com/slavsan/godiss/examples/other%s\n", internal.Green, internal.NoColor) +
+		fmt.Sprintf("../examples/cars/car.go:4:2: 1 %ssync%s\n", internal.Yellow, internal.NoColor)
+
+	actualLines := strings.Split(internal.FormatImportsTable(actual, "github.com/slavsan/godiss", &internal.Config{}, &internal.ImportsTableOptions{Positions: true}), "\n")
+	expectedLines := strings.Split(expected, "\n")
+
+	assertEqual(t, len(expectedLines), len(actualLines))
+	for i := range expectedLines {
+		assertEqual(t, expectedLines[i], actualLines[i], fmt.Sprintf("failed on line %d", i))
+	}
+}
+
+func TestFormatTypesWithPositions(t *testing.T) {
+	actual, err := internal.LoadPackages("../examples/other", "", "")
+	assertEqual(t, nil, err)
+	for _, p := range actual {
+		err := internal.ParsePackage(p, "", "", &internal.Config{})
+		assertEqual(t, nil, err)
+	}
+	expected := "" +
+		"__YELLOW__../examples/other__NOCOLOR__\n" +
+		"\n" +
+		"../examples/other/vehicle.go:3:6: __GREEN__+__NOCOLOR__ type __BLUE__Vehicle__NOCOLOR__ {\n" +
+		"../examples/other/vehicle.go:4:2:     __GREEN__+__NOCOLOR__ Doors int\n" +
+		"\n" +
+		"../examples/other/vehicle.go:7:19:     __GREEN__+__NOCOLOR__ StartEngine() error\n" +
+		"../examples/other/vehicle.go:11:18:     __GREEN__+__NOCOLOR__ StopEngine() error\n" +
+		"}\n" +
+		"\n"
+
+	expected = strings.ReplaceAll(expected, "__GREEN__", internal.Green)
+	expected = strings.ReplaceAll(expected, "__YELLOW__", internal.Yellow)
+	expected = strings.ReplaceAll(expected, "__BLUE__", internal.Blue)
+	expected = strings.ReplaceAll(expected, "__NOCOLOR__", internal.NoColor)
+
+	assertEqual(t, expected, internal.FormatTypes(actual, "", &internal.Config{}, &internal.TypesOptions{Positions: true}))
+}
+
 func TestFormatTypes(t *testing.T) {
 	actual, err := internal.LoadPackages("../examples", "", "")
 	assertEqual(t, nil, err)
@@ -551,7 +644,7 @@ func TestFormatTypes(t *testing.T) {
 	expected = strings.ReplaceAll(expected, "__BLUE__", internal.Blue)
 	expected = strings.ReplaceAll(expected, "__NOCOLOR__", internal.NoColor)
 
-	actualLines := strings.Split(internal.FormatTypes(actual, "github.com/slavsan/godiss"), "\n")
+	actualLines := strings.Split(internal.FormatTypes(actual, "github.com/slavsan/godiss", &internal.Config{}, &internal.TypesOptions{}), "\n")
 	expectedLines := strings.Split(expected, "\n")
 
 	assertEqual(t, len(expectedLines), len(actualLines))
@@ -560,6 +653,21 @@ func TestFormatTypes(t *testing.T) {
 	}
 }
 
+func pos(filename string, line, column int) internal.Position {
+	return internal.Position{Filename: filename, Line: line, Column: column}
+}
+
+func loadFixture(t *testing.T, dir, module string, config *internal.Config) map[string]*internal.Directory {
+	t.Helper()
+	directories, err := internal.LoadPackages(dir, module, dir)
+	assertEqual(t, nil, err)
+	for _, d := range directories {
+		err := internal.ParsePackage(d, module, dir, config)
+		assertEqual(t, nil, err)
+	}
+	return directories
+}
+
 func assertEqual(t *testing.T, expected, actual any, msg ...string) {
 	t.Helper()
 	if !reflect.DeepEqual(expected, actual) {
@@ -666,3 +774,61 @@ func expand(v reflect.Value) string {
 	}
 	return sb.String()
 }
+
+func TestFormatStyle(t *testing.T) {
+	structs := []*internal.Struct{{Name: "Car"}}
+	style := &internal.DiagramStyle{RankDir: "TB", FontName: "Fira Sans", NodeColor: "#333333", Splines: "ortho"}
+
+	expected := `digraph {
+    node [style=filled, shape=plaintext, pencolor="#00000044", fontname="Helvetica,Arial,sans-serif"]
+    rankdir="TB"
+    splines="ortho"
+    node [fontname="Fira Sans", color="#333333"]
+    edge [fontname="Fira Sans"]
+`
+	assertEqual(t, true, strings.HasPrefix(internal.Format(structs, style), expected))
+	assertEqual(t, true, strings.HasPrefix(internal.FormatPackages(map[string]*internal.Directory{}, style), expected))
+}
+
+func TestLoadStructsGenerics(t *testing.T) {
+	actual, err := internal.LoadStructs("testdata/generics/generics.go")
+	assertEqual(t, nil, err)
+
+	var fields []string
+	for _, f := range actual[2].Fields {
+		fields = append(fields, fmt.Sprintf("%s %s", f.Name, f.Type))
+	}
+	assertEqual(t, []string{
+		"Entries map[string]Pair[string, int]",
+		"Current *Box[Pair[string, int]]",
+		"Handlers []func(*Box[string]) (error)",
+	}, fields)
+}
+
+func TestFormatTypesGenericReceivers(t *testing.T) {
+	directories := loadFixture(t, "testdata/generics", "example.com/generics", &internal.Config{})
+
+	expected := "" +
+		"__YELLOW__example.com/generics__NOCOLOR__\n" +
+		"\n" +
+		"__GREEN__+__NOCOLOR__ type __BLUE__Box__NOCOLOR__ {\n" +
+		"    __GREEN__+__NOCOLOR__ Item T\n" +
+		"\n" +
+		"    __GREEN__+__NOCOLOR__ Get() T\n" +
+		"}\n" +
+		"\n" +
+		"__GREEN__+__NOCOLOR__ type __BLUE__Pair__NOCOLOR__ {\n" +
+		"    __GREEN__+__NOCOLOR__ Key K\n" +
+		"    __GREEN__+__NOCOLOR__ Value V\n" +
+		"\n" +
+		"    __GREEN__+__NOCOLOR__ String() string\n" +
+		"}\n"
+
+	expected = strings.ReplaceAll(expected, "__YELLOW__", internal.Yellow)
+	expected = strings.ReplaceAll(expected, "__GREEN__", internal.Green)
+	expected = strings.ReplaceAll(expected, "__BLUE__", internal.Blue)
+	expected = strings.ReplaceAll(expected, "__NOCOLOR__", internal.NoColor)
+
+	actual := internal.FormatTypes(directories, "example.com/generics", &internal.Config{}, &internal.TypesOptions{})
+	assertEqual(t, true, strings.HasPrefix(actual, expected))
+}
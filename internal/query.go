@@ -0,0 +1,1038 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type queryKind int
+
+const (
+	queryString queryKind = iota
+	queryInt
+	queryBool
+	queryList
+)
+
+type queryField struct {
+	Name string
+	Kind queryKind
+}
+
+type queryRow map[string]any
+
+type queryEntity struct {
+	Fields  []queryField
+	Columns []string
+	Rows    func(directories map[string]*Directory) []queryRow
+}
+
+func (e *queryEntity) field(name string) (queryField, bool) {
+	for _, f := range e.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return queryField{}, false
+}
+
+func (e *queryEntity) fieldNames() []string {
+	names := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+var queryEntities = map[string]*queryEntity{
+	"packages": {
+		Fields: []queryField{
+			{"path", queryString},
+			{"name", queryString},
+			{"test", queryBool},
+			{"files", queryInt},
+			{"structs", queryInt},
+			{"interfaces", queryInt},
+			{"functions", queryInt},
+			{"methods", queryInt},
+			{"lines", queryInt},
+			{"imports", queryList},
+		},
+		Columns: []string{"path", "name", "files", "structs", "lines"},
+		Rows:    packageRows,
+	},
+	"files": {
+		Fields: []queryField{
+			{"path", queryString},
+			{"name", queryString},
+			{"package", queryString},
+			{"test", queryBool},
+			{"lines", queryInt},
+			{"structs", queryInt},
+			{"functions", queryInt},
+			{"methods", queryInt},
+			{"imports", queryList},
+			{"constraints", queryList},
+		},
+		Columns: []string{"path", "package", "lines"},
+		Rows:    fileRows,
+	},
+	"structs": {
+		Fields: []queryField{
+			{"name", queryString},
+			{"package", queryString},
+			{"file", queryString},
+			{"position", queryString},
+			{"exported", queryBool},
+			{"fields", queryInt},
+			{"methods", queryInt},
+			{"embeds", queryList},
+		},
+		Columns: []string{"package", "name", "fields", "methods"},
+		Rows:    structRows,
+	},
+	"fields": {
+		Fields: []queryField{
+			{"name", queryString},
+			{"type", queryString},
+			{"struct", queryString},
+			{"package", queryString},
+			{"position", queryString},
+			{"exported", queryBool},
+			{"embedded", queryBool},
+		},
+		Columns: []string{"package", "struct", "name", "type"},
+		Rows:    fieldRows,
+	},
+	"methods": {
+		Fields: []queryField{
+			{"name", queryString},
+			{"receiver", queryString},
+			{"signature", queryString},
+			{"package", queryString},
+			{"position", queryString},
+			{"exported", queryBool},
+			{"cyclomatic", queryInt},
+			{"cognitive", queryInt},
+			{"lines", queryInt},
+			{"params", queryInt},
+		},
+		Columns: []string{"package", "receiver", "signature"},
+		Rows:    methodRows,
+	},
+	"functions": {
+		Fields: []queryField{
+			{"name", queryString},
+			{"signature", queryString},
+			{"package", queryString},
+			{"position", queryString},
+			{"exported", queryBool},
+			{"cyclomatic", queryInt},
+			{"cognitive", queryInt},
+			{"lines", queryInt},
+			{"params", queryInt},
+		},
+		Columns: []string{"package", "signature"},
+		Rows:    functionRows,
+	},
+	"interfaces": {
+		Fields: []queryField{
+			{"name", queryString},
+			{"package", queryString},
+			{"position", queryString},
+			{"exported", queryBool},
+			{"methods", queryInt},
+			{"embeds", queryList},
+		},
+		Columns: []string{"package", "name", "methods"},
+		Rows:    interfaceRows,
+	},
+	"imports": {
+		Fields: []queryField{
+			{"path", queryString},
+			{"alias", queryString},
+			{"package", queryString},
+			{"file", queryString},
+			{"position", queryString},
+			{"stdlib", queryBool},
+		},
+		Columns: []string{"package", "path", "alias"},
+		Rows:    importRows,
+	},
+}
+
+func forEachFile(directories map[string]*Directory, fn func(pkg *Package, f *File)) {
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			sort.Sort(ByFilePath(pkg.Files))
+			for _, f := range pkg.Files {
+				fn(pkg, f)
+			}
+		}
+	}
+}
+
+func packageRows(directories map[string]*Directory) []queryRow {
+	var rows []queryRow
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			row := queryRow{
+				"path":       pkg.ModulePath,
+				"name":       pkg.Name,
+				"test":       strings.HasSuffix(pkg.Name, "_test"),
+				"files":      len(pkg.Files),
+				"structs":    0,
+				"interfaces": 0,
+				"functions":  0,
+				"methods":    0,
+				"lines":      0,
+			}
+			imports := map[string]struct{}{}
+			for _, f := range pkg.Files {
+				row["structs"] = row["structs"].(int) + len(f.Structs)
+				row["interfaces"] = row["interfaces"].(int) + len(f.Interfaces)
+				row["functions"] = row["functions"].(int) + len(f.Functions)
+				row["methods"] = row["methods"].(int) + len(f.Methods)
+				row["lines"] = row["lines"].(int) + f.Lines.Code
+				for _, i := range f.Imports {
+					imports[i.Path] = struct{}{}
+				}
+			}
+			row["imports"] = sortedKeys(imports)
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func fileRows(directories map[string]*Directory) []queryRow {
+	var rows []queryRow
+	forEachFile(directories, func(pkg *Package, f *File) {
+		var imports []string
+		for _, i := range f.Imports {
+			imports = append(imports, i.Path)
+		}
+		rows = append(rows, queryRow{
+			"path":        f.Path,
+			"name":        filepath.Base(f.Path),
+			"package":     pkg.ModulePath,
+			"test":        isTestFile(f.Path),
+			"lines":       f.Lines.Code,
+			"structs":     len(f.Structs),
+			"functions":   len(f.Functions),
+			"methods":     len(f.Methods),
+			"imports":     imports,
+			"constraints": f.BuildConstraints,
+		})
+	})
+	return rows
+}
+
+func structRows(directories map[string]*Directory) []queryRow {
+	var rows []queryRow
+	forEachFile(directories, func(pkg *Package, f *File) {
+		for _, s := range f.Structs {
+			var embeds []string
+			for _, field := range s.Fields {
+				if field.Name == "" {
+					embeds = append(embeds, strings.TrimPrefix(field.Type, "*"))
+				}
+			}
+			rows = append(rows, queryRow{
+				"name":     s.Name,
+				"package":  pkg.ModulePath,
+				"file":     f.Path,
+				"position": s.Position.String(),
+				"exported": ast.IsExported(s.Name),
+				"fields":   len(s.Fields),
+				"methods":  len(s.Methods),
+				"embeds":   embeds,
+			})
+		}
+	})
+	return rows
+}
+
+func fieldName(field *Field) string {
+	if field.Name != "" {
+		return field.Name
+	}
+	name := strings.TrimPrefix(field.Type, "*")
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return name
+}
+
+func fieldRows(directories map[string]*Directory) []queryRow {
+	var rows []queryRow
+	forEachFile(directories, func(pkg *Package, f *File) {
+		for _, s := range f.Structs {
+			for _, field := range s.Fields {
+				rows = append(rows, queryRow{
+					"name":     fieldName(field),
+					"type":     field.Type,
+					"struct":   s.Name,
+					"package":  pkg.ModulePath,
+					"position": field.Position.String(),
+					"exported": ast.IsExported(fieldName(field)),
+					"embedded": field.Name == "",
+				})
+			}
+		}
+	})
+	return rows
+}
+
+func complexityRow(row queryRow, c *Complexity) queryRow {
+	if c == nil {
+		c = &Complexity{}
+	}
+	row["cyclomatic"] = c.Cyclomatic
+	row["cognitive"] = c.Cognitive
+	row["lines"] = c.Lines
+	row["params"] = c.Params
+	return row
+}
+
+func methodRows(directories map[string]*Directory) []queryRow {
+	var rows []queryRow
+	forEachFile(directories, func(pkg *Package, f *File) {
+		for _, m := range f.Methods {
+			rows = append(rows, complexityRow(queryRow{
+				"name":      methodName(m),
+				"receiver":  m.Receiver,
+				"signature": m.Signature,
+				"package":   pkg.ModulePath,
+				"position":  m.Position.String(),
+				"exported":  ast.IsExported(methodName(m)),
+			}, m.Complexity))
+		}
+	})
+	return rows
+}
+
+func functionRows(directories map[string]*Directory) []queryRow {
+	var rows []queryRow
+	forEachFile(directories, func(pkg *Package, f *File) {
+		for _, fn := range f.Functions {
+			rows = append(rows, complexityRow(queryRow{
+				"name":      fn.Name,
+				"signature": fn.Signature,
+				"package":   pkg.ModulePath,
+				"position":  fn.Position.String(),
+				"exported":  ast.IsExported(fn.Name),
+			}, fn.Complexity))
+		}
+	})
+	return rows
+}
+
+func interfaceRows(directories map[string]*Directory) []queryRow {
+	var rows []queryRow
+	forEachFile(directories, func(pkg *Package, f *File) {
+		for _, i := range f.Interfaces {
+			rows = append(rows, queryRow{
+				"name":     i.Name,
+				"package":  pkg.ModulePath,
+				"position": i.Position.String(),
+				"exported": ast.IsExported(i.Name),
+				"methods":  len(i.Methods),
+				"embeds":   i.Embedded,
+			})
+		}
+	})
+	return rows
+}
+
+func importRows(directories map[string]*Directory) []queryRow {
+	var rows []queryRow
+	forEachFile(directories, func(pkg *Package, f *File) {
+		for _, i := range f.Imports {
+			rows = append(rows, queryRow{
+				"path":     i.Path,
+				"alias":    i.Name,
+				"package":  pkg.ModulePath,
+				"file":     f.Path,
+				"position": i.Position.String(),
+				"stdlib":   i.StdLib,
+			})
+		}
+	})
+	return rows
+}
+
+type queryTokenKind int
+
+const (
+	tokenEOF queryTokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type queryToken struct {
+	Kind  queryTokenKind
+	Value string
+	Pos   int
+}
+
+func (t queryToken) String() string {
+	if t.Kind == tokenEOF {
+		return "end of query"
+	}
+	return strconv.Quote(t.Value)
+}
+
+func tokenizeQuery(src string) ([]queryToken, error) {
+	var tokens []queryToken
+
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{tokenLParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{tokenRParen, ")", i})
+			i++
+		case r == ',':
+			tokens = append(tokens, queryToken{tokenComma, ",", i})
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			tokens = append(tokens, queryToken{tokenString, sb.String(), start})
+			i++
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i++; i < len(runes) && unicode.IsDigit(runes[i]); i++ {
+			}
+			tokens = append(tokens, queryToken{tokenNumber, string(runes[start:i]), start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for ; i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_'); i++ {
+			}
+			tokens = append(tokens, queryToken{tokenIdent, string(runes[start:i]), start})
+		case strings.ContainsRune("=!<>~", r):
+			start := i
+			op := string(r)
+			if r != '~' && i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("unexpected %q at position %d", op, start)
+			}
+			i += len(op)
+			if op == "==" {
+				op = "="
+			}
+			tokens = append(tokens, queryToken{tokenOperator, op, start})
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", string(r), i)
+		}
+	}
+
+	return append(tokens, queryToken{tokenEOF, "", len(runes)}), nil
+}
+
+type queryExpr interface {
+	eval(row queryRow) bool
+}
+
+type queryAnd struct{ left, right queryExpr }
+
+func (e *queryAnd) eval(row queryRow) bool { return e.left.eval(row) && e.right.eval(row) }
+
+type queryOr struct{ left, right queryExpr }
+
+func (e *queryOr) eval(row queryRow) bool { return e.left.eval(row) || e.right.eval(row) }
+
+type queryNot struct{ expr queryExpr }
+
+func (e *queryNot) eval(row queryRow) bool { return !e.expr.eval(row) }
+
+type queryTruthy struct{ field string }
+
+func (e *queryTruthy) eval(row queryRow) bool {
+	switch v := row[e.field].(type) {
+	case bool:
+		return v
+	case int:
+		return v != 0
+	case string:
+		return v != ""
+	case []string:
+		return len(v) > 0
+	}
+	return false
+}
+
+type queryCompare struct {
+	field string
+	op    string
+	value any
+	re    *regexp.Regexp
+}
+
+func (e *queryCompare) eval(row queryRow) bool {
+	switch v := row[e.field].(type) {
+	case string:
+		switch e.op {
+		case "~":
+			return e.re.MatchString(v)
+		case "contains":
+			return strings.Contains(v, e.value.(string))
+		}
+		return compareOp(e.op, strings.Compare(v, e.value.(string)))
+	case int:
+		return compareOp(e.op, v-e.value.(int))
+	case bool:
+		return compareOp(e.op, boolCompare(v, e.value.(bool)))
+	case []string:
+		switch e.op {
+		case "~":
+			for _, item := range v {
+				if e.re.MatchString(item) {
+					return true
+				}
+			}
+			return false
+		case "contains":
+			for _, item := range v {
+				if item == e.value.(string) {
+					return true
+				}
+			}
+			return false
+		}
+		return compareOp(e.op, len(v)-e.value.(int))
+	}
+	return false
+}
+
+func boolCompare(a, b bool) int {
+	if a == b {
+		return 0
+	}
+	if !a {
+		return -1
+	}
+	return 1
+}
+
+func compareOp(op string, cmp int) bool {
+	switch op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+type Query struct {
+	Entity  string
+	Where   queryExpr
+	Select  []string
+	Count   bool
+	CountBy string
+	OrderBy string
+	Desc    bool
+	Limit   int
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+	entity *queryEntity
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	t := p.tokens[p.pos]
+	if t.Kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *queryParser) keyword(word string) bool {
+	t := p.peek()
+	if t.Kind == tokenIdent && strings.EqualFold(t.Value, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) unexpected(t queryToken) error {
+	return fmt.Errorf("unexpected %s at position %d", t, t.Pos)
+}
+
+func (p *queryParser) number(t queryToken) (int, error) {
+	n, err := strconv.Atoi(t.Value)
+	if err != nil {
+		return 0, fmt.Errorf("number %s at position %d is out of range", t.Value, t.Pos)
+	}
+	return n, nil
+}
+
+func (p *queryParser) field() (queryField, error) {
+	t := p.next()
+	if t.Kind != tokenIdent {
+		return queryField{}, p.unexpected(t)
+	}
+	f, ok := p.entity.field(t.Value)
+	if !ok {
+		return queryField{}, fmt.Errorf(
+			"unknown field %q at position %d, expected one of: %s",
+			t.Value, t.Pos, strings.Join(p.entity.fieldNames(), ", "),
+		)
+	}
+	return f, nil
+}
+
+func ParseQuery(src string) (*Query, error) {
+	tokens, err := tokenizeQuery(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{tokens: tokens}
+
+	t := p.next()
+	if t.Kind != tokenIdent {
+		return nil, fmt.Errorf("expected an entity, got %s", t)
+	}
+	entity, ok := queryEntities[strings.ToLower(t.Value)]
+	if !ok {
+		names := make([]string, 0, len(queryEntities))
+		for name := range queryEntities {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown entity %q, expected one of: %s", t.Value, strings.Join(names, ", "))
+	}
+	p.entity = entity
+
+	q := &Query{Entity: strings.ToLower(t.Value)}
+
+	if p.keyword("where") {
+		q.Where, err = p.or()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case p.keyword("select"):
+		for {
+			f, err := p.field()
+			if err != nil {
+				return nil, err
+			}
+			q.Select = append(q.Select, f.Name)
+			if p.peek().Kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	case p.keyword("count"):
+		q.Count = true
+		if p.keyword("by") {
+			f, err := p.field()
+			if err != nil {
+				return nil, err
+			}
+			if f.Kind == queryList {
+				return nil, fmt.Errorf("cannot count by list field %q", f.Name)
+			}
+			q.CountBy = f.Name
+		}
+	}
+
+	if p.keyword("order") {
+		if !p.keyword("by") {
+			return nil, p.unexpected(p.peek())
+		}
+		t := p.next()
+		if t.Kind != tokenIdent {
+			return nil, p.unexpected(t)
+		}
+		switch {
+		case q.Count && (t.Value == "count" || t.Value == q.CountBy):
+		case q.Count:
+			return nil, fmt.Errorf("cannot order counts by %q", t.Value)
+		default:
+			if _, ok := entity.field(t.Value); !ok {
+				return nil, fmt.Errorf(
+					"unknown field %q at position %d, expected one of: %s",
+					t.Value, t.Pos, strings.Join(entity.fieldNames(), ", "),
+				)
+			}
+		}
+		q.OrderBy = t.Value
+		if p.keyword("desc") {
+			q.Desc = true
+		} else {
+			p.keyword("asc")
+		}
+	}
+
+	if p.keyword("limit") {
+		t := p.next()
+		if t.Kind != tokenNumber {
+			return nil, p.unexpected(t)
+		}
+		q.Limit, err = p.number(t)
+		if err != nil {
+			return nil, err
+		}
+		if q.Limit < 1 {
+			return nil, fmt.Errorf("invalid limit %s at position %d, expected a positive number", t.Value, t.Pos)
+		}
+	}
+
+	if t := p.peek(); t.Kind != tokenEOF {
+		return nil, p.unexpected(t)
+	}
+
+	return q, nil
+}
+
+func (p *queryParser) or() (queryExpr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = &queryOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) and() (queryExpr, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &queryAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) unary() (queryExpr, error) {
+	if p.keyword("not") {
+		expr, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &queryNot{expr}, nil
+	}
+
+	if p.peek().Kind == tokenLParen {
+		p.next()
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.Kind != tokenRParen {
+			return nil, p.unexpected(t)
+		}
+		return expr, nil
+	}
+
+	return p.comparison()
+}
+
+func (p *queryParser) comparison() (queryExpr, error) {
+	f, err := p.field()
+	if err != nil {
+		return nil, err
+	}
+
+	var op string
+	switch t := p.peek(); {
+	case t.Kind == tokenOperator:
+		op = t.Value
+	case t.Kind == tokenIdent && strings.EqualFold(t.Value, "contains"):
+		op = "contains"
+	default:
+		return &queryTruthy{f.Name}, nil
+	}
+	opToken := p.next()
+
+	t := p.next()
+	e := &queryCompare{field: f.Name, op: op}
+
+	invalid := fmt.Errorf("invalid comparison %s %s %s at position %d", f.Name, op, t, opToken.Pos)
+
+	switch {
+	case op == "~":
+		if t.Kind != tokenString || (f.Kind != queryString && f.Kind != queryList) {
+			return nil, invalid
+		}
+		e.re, err = regexp.Compile(t.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", t.Value, err)
+		}
+		e.value = t.Value
+	case op == "contains":
+		if t.Kind != tokenString || (f.Kind != queryString && f.Kind != queryList) {
+			return nil, invalid
+		}
+		e.value = t.Value
+	case f.Kind == queryString:
+		if t.Kind != tokenString {
+			return nil, invalid
+		}
+		e.value = t.Value
+	case f.Kind == queryInt || f.Kind == queryList:
+		if t.Kind != tokenNumber {
+			return nil, invalid
+		}
+		e.value, err = p.number(t)
+		if err != nil {
+			return nil, err
+		}
+	case f.Kind == queryBool:
+		if t.Kind != tokenIdent || (t.Value != "true" && t.Value != "false") || (op != "=" && op != "!=") {
+			return nil, invalid
+		}
+		e.value = t.Value == "true"
+	}
+
+	return e, nil
+}
+
+type QueryResult struct {
+	Columns []string
+	Rows    [][]any
+}
+
+func compareValues(a, b any) int {
+	switch v := a.(type) {
+	case string:
+		return strings.Compare(v, b.(string))
+	case int:
+		return v - b.(int)
+	case bool:
+		return boolCompare(v, b.(bool))
+	case []string:
+		return len(v) - len(b.([]string))
+	}
+	return 0
+}
+
+func RunQuery(directories map[string]*Directory, q *Query) *QueryResult {
+	entity := queryEntities[q.Entity]
+
+	var rows []queryRow
+	for _, row := range entity.Rows(directories) {
+		if q.Where == nil || q.Where.eval(row) {
+			rows = append(rows, row)
+		}
+	}
+
+	if q.Count {
+		return countRows(rows, q)
+	}
+
+	if q.OrderBy != "" {
+		sort.SliceStable(rows, func(i, j int) bool {
+			cmp := compareValues(rows[i][q.OrderBy], rows[j][q.OrderBy])
+			if q.Desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		})
+	}
+
+	if q.Limit > 0 && len(rows) > q.Limit {
+		rows = rows[:q.Limit]
+	}
+
+	columns := q.Select
+	if len(columns) == 0 {
+		columns = entity.Columns
+	}
+
+	res := &QueryResult{Columns: columns}
+	for _, row := range rows {
+		values := make([]any, 0, len(columns))
+		for _, c := range columns {
+			values = append(values, row[c])
+		}
+		res.Rows = append(res.Rows, values)
+	}
+
+	return res
+}
+
+func countRows(rows []queryRow, q *Query) *QueryResult {
+	if q.CountBy == "" {
+		return &QueryResult{Columns: []string{"count"}, Rows: [][]any{{len(rows)}}}
+	}
+
+	counts := map[any]int{}
+	var keys []any
+	for _, row := range rows {
+		key := row[q.CountBy]
+		if _, ok := counts[key]; !ok {
+			keys = append(keys, key)
+		}
+		counts[key]++
+	}
+
+	res := &QueryResult{Columns: []string{q.CountBy, "count"}}
+	for _, key := range keys {
+		res.Rows = append(res.Rows, []any{key, counts[key]})
+	}
+
+	column, desc := 1, true
+	if q.OrderBy == q.CountBy {
+		column, desc = 0, q.Desc
+	} else if q.OrderBy == "count" {
+		desc = q.Desc
+	}
+	sort.SliceStable(res.Rows, func(i, j int) bool {
+		cmp := compareValues(res.Rows[i][column], res.Rows[j][column])
+		if cmp == 0 && column == 1 {
+			return compareValues(res.Rows[i][0], res.Rows[j][0]) < 0
+		}
+		if desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+
+	if q.Limit > 0 && len(res.Rows) > q.Limit {
+		res.Rows = res.Rows[:q.Limit]
+	}
+
+	return res
+}
+
+func formatQueryValue(v any) string {
+	switch v := v.(type) {
+	case []string:
+		return strings.Join(v, ", ")
+	case nil:
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func FormatQueryTable(res *QueryResult) string {
+	var sb strings.Builder
+
+	widths := make([]int, len(res.Columns))
+	for i, c := range res.Columns {
+		widths[i] = len(c)
+	}
+	for _, row := range res.Rows {
+		for i, v := range row {
+			if len(formatQueryValue(v)) > widths[i] {
+				widths[i] = len(formatQueryValue(v))
+			}
+		}
+	}
+
+	writeRow := func(values []string, numeric []bool) {
+		for i, v := range values {
+			if i > 0 {
+				sb.WriteString(" | ")
+			}
+			switch {
+			case numeric[i]:
+				sb.WriteString(fmt.Sprintf("%*s", widths[i], v))
+			case i == len(values)-1:
+				sb.WriteString(v)
+			default:
+				sb.WriteString(fmt.Sprintf("%-*s", widths[i], v))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	writeRow(res.Columns, make([]bool, len(res.Columns)))
+	for _, row := range res.Rows {
+		values := make([]string, 0, len(row))
+		numeric := make([]bool, 0, len(row))
+		for _, v := range row {
+			_, ok := v.(int)
+			values = append(values, formatQueryValue(v))
+			numeric = append(numeric, ok)
+		}
+		writeRow(values, numeric)
+	}
+
+	return sb.String()
+}
+
+func FormatQueryJSON(res *QueryResult) (string, error) {
+	rows := make([]map[string]any, 0, len(res.Rows))
+	for _, row := range res.Rows {
+		obj := map[string]any{}
+		for i, c := range res.Columns {
+			v := row[i]
+			if list, ok := v.([]string); ok && list == nil {
+				v = []string{}
+			}
+			obj[c] = v
+		}
+		rows = append(rows, obj)
+	}
+
+	data, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s\n", data), nil
+}
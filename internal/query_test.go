@@ -0,0 +1,149 @@
+package internal_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func runQuery(t *testing.T, src string) string {
+	t.Helper()
+	q, err := internal.ParseQuery(src)
+	assertEqual(t, nil, err)
+	directories := loadFixture(t, "testdata/query", "example.com/query", &internal.Config{})
+	return internal.FormatQueryTable(internal.RunQuery(directories, q))
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{
+			query: `structs where embeds contains "sync.Mutex"`,
+			expected: []string{
+				"package                 | name | fields | methods",
+				"example.com/query/store | DB   |      3 |       1",
+			},
+		},
+		{
+			query: `structs where exported and fields > 2 select name, embeds`,
+			expected: []string{
+				"name   | embeds",
+				"DB     | sync.Mutex",
+				"Server | store.DB",
+			},
+		},
+		{
+			query: `packages where imports contains "net/http" and imports contains "database/sql" select path`,
+			expected: []string{
+				"path",
+				"example.com/query/web",
+			},
+		},
+		{
+			query: `fields where not exported and (type ~ "^\\*sql\\." or type = "sync.RWMutex") select struct, name`,
+			expected: []string{
+				"struct | name",
+				"DB     | conn",
+				"cache  | mu",
+				"Server | tx",
+			},
+		},
+		{
+			query: `imports where not path ~ "^example" count by path`,
+			expected: []string{
+				"path         | count",
+				"database/sql |     2",
+				"net/http     |     2",
+				"sync         |     1",
+			},
+		},
+		{
+			query: `methods where cyclomatic >= 2 or name = "Close" select receiver, name, cyclomatic order by cyclomatic desc`,
+			expected: []string{
+				"receiver | name      | cyclomatic",
+				"Server   | ServeHTTP |          3",
+				"DB       | Close     |          1",
+			},
+		},
+		{
+			query: `interfaces where embeds ~ "http" select name, methods, embeds`,
+			expected: []string{
+				"name    | methods | embeds",
+				"Handler |       1 | http.Handler",
+			},
+		},
+		{
+			query: `structs count`,
+			expected: []string{
+				"count",
+				"    4",
+			},
+		},
+		{
+			query: `functions select name order by name limit 1`,
+			expected: []string{
+				"name",
+				"New",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			assertEqual(t, strings.Join(tt.expected, "\n")+"\n", runQuery(t, tt.query))
+		})
+	}
+}
+
+func TestQueryJSON(t *testing.T) {
+	q, err := internal.ParseQuery(`structs where name = "Route" select name, fields, embeds`)
+	assertEqual(t, nil, err)
+
+	directories := loadFixture(t, "testdata/query", "example.com/query", &internal.Config{})
+	out, err := internal.FormatQueryJSON(internal.RunQuery(directories, q))
+	assertEqual(t, nil, err)
+
+	assertEqual(t, strings.Join([]string{
+		`[`,
+		`  {`,
+		`    "embeds": [],`,
+		`    "fields": 2,`,
+		`    "name": "Route"`,
+		`  }`,
+		`]`,
+		``,
+	}, "\n"), out)
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{`widgets`, `unknown entity "widgets", expected one of: fields, files, functions, imports, interfaces, methods, packages, structs`},
+		{`structs where size > 3`, `unknown field "size" at position 14, expected one of: name, package, file, position, exported, fields, methods, embeds`},
+		{`structs where name > 3`, `invalid comparison name > "3" at position 19`},
+		{`structs where exported = "yes"`, `invalid comparison exported = "yes" at position 23`},
+		{`structs where (exported`, `unexpected end of query at position 23`},
+		{`structs where name = "DB`, `unterminated string at position 21`},
+		{`structs count by embeds`, `cannot count by list field "embeds"`},
+		{`structs select name extra`, `unexpected "extra" at position 20`},
+		{`structs limit -1`, `invalid limit -1 at position 14, expected a positive number`},
+		{`structs limit 0`, `invalid limit 0 at position 14, expected a positive number`},
+		{`structs limit 99999999999999999999`, `number 99999999999999999999 at position 14 is out of range`},
+		{`structs where fields > 99999999999999999999`, `number 99999999999999999999 at position 23 is out of range`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := internal.ParseQuery(tt.query)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			assertEqual(t, tt.expected, err.Error())
+		})
+	}
+}
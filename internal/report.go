@@ -0,0 +1,222 @@
+package internal
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//go:embed report.html
+var reportTemplate string
+
+type ReportType struct {
+	Kind     string   `json:"kind"`
+	Name     string   `json:"name"`
+	Fields   []string `json:"fields"`
+	Methods  []string `json:"methods"`
+	Position string   `json:"position"`
+	Uses     []string `json:"uses"`
+	refs     []string
+}
+
+type ReportPackage struct {
+	Path      string        `json:"path"`
+	Name      string        `json:"name"`
+	Doc       string        `json:"doc"`
+	Files     int           `json:"files"`
+	Lines     int           `json:"lines"`
+	Types     []*ReportType `json:"types"`
+	Functions []string      `json:"functions"`
+	Imports   []string      `json:"imports"`
+	Importers []string      `json:"importers"`
+}
+
+type ReportEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type ReportImport struct {
+	Path   string `json:"path"`
+	Count  int    `json:"count"`
+	StdLib bool   `json:"stdlib"`
+}
+
+type ReportStat struct {
+	Name   string `json:"name"`
+	Number int    `json:"number"`
+}
+
+type ReportData struct {
+	Module   string           `json:"module"`
+	Packages []*ReportPackage `json:"packages"`
+	Edges    []*ReportEdge    `json:"edges"`
+	Stats    []*ReportStat    `json:"stats"`
+	Imports  []*ReportImport  `json:"imports"`
+	Live     bool             `json:"-"`
+}
+
+func reportStruct(s *Struct) *ReportType {
+	t := &ReportType{
+		Kind:     "struct",
+		Name:     s.Name,
+		Fields:   []string{},
+		Methods:  []string{},
+		Position: s.Position.String(),
+	}
+	for _, f := range s.Fields {
+		t.Fields = append(t.Fields, strings.TrimSpace(fmt.Sprintf("%s %s", f.Name, f.Type)))
+		t.refs = append(t.refs, f.Type)
+	}
+	for _, m := range s.Methods {
+		t.Methods = append(t.Methods, strings.TrimSpace(m.Signature))
+	}
+	return t
+}
+
+func reportInterface(i *Interface) *ReportType {
+	t := &ReportType{
+		Kind:     "interface",
+		Name:     i.Name,
+		Fields:   append([]string{}, i.Embedded...),
+		Methods:  []string{},
+		Position: i.Position.String(),
+		refs:     i.Embedded,
+	}
+	for _, m := range i.Methods {
+		t.Methods = append(t.Methods, strings.TrimSpace(m.Signature))
+	}
+	return t
+}
+
+var identifierPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_.]*`)
+
+func linkReportTypes(types []*ReportType) {
+	names := map[string]struct{}{}
+	for _, t := range types {
+		names[t.Name] = struct{}{}
+	}
+
+	for _, t := range types {
+		uses := map[string]struct{}{}
+		for _, ref := range t.refs {
+			for _, ident := range identifierPattern.FindAllString(ref, -1) {
+				if _, ok := names[ident]; ok && ident != t.Name {
+					uses[ident] = struct{}{}
+				}
+			}
+		}
+		t.Uses = sortedKeys(uses)
+	}
+}
+
+func NewReportData(directories map[string]*Directory, module string, config *Config) *ReportData {
+	data := &ReportData{
+		Module:   module,
+		Packages: []*ReportPackage{},
+		Edges:    []*ReportEdge{},
+		Stats:    []*ReportStat{},
+		Imports:  []*ReportImport{},
+	}
+
+	byPath := map[string]*ReportPackage{}
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			path := pkg.ModulePath
+			if strings.HasSuffix(pkg.Name, "_test") {
+				if !config.IncludeTests {
+					continue
+				}
+				path += "_test"
+			}
+
+			p := &ReportPackage{
+				Path:      path,
+				Name:      pkg.Name,
+				Doc:       pkg.Doc,
+				Types:     []*ReportType{},
+				Functions: []string{},
+				Imports:   []string{},
+				Importers: []string{},
+			}
+
+			imports := map[string]struct{}{}
+
+			sort.Sort(ByFilePath(pkg.Files))
+
+			for _, f := range pkg.Files {
+				p.Files++
+				p.Lines += f.Lines.Code
+
+				sort.Sort(ByStructName(f.Structs))
+
+				for _, s := range f.Structs {
+					p.Types = append(p.Types, reportStruct(s))
+				}
+				for _, i := range f.Interfaces {
+					p.Types = append(p.Types, reportInterface(i))
+				}
+				for _, t := range f.Types {
+					p.Types = append(p.Types, &ReportType{
+						Kind:     "type",
+						Name:     t.Name,
+						Fields:   []string{t.Type},
+						Methods:  []string{},
+						Position: t.Position.String(),
+						refs:     []string{t.Type},
+					})
+				}
+				for _, fn := range f.Functions {
+					p.Functions = append(p.Functions, strings.TrimSpace(fn.Signature))
+				}
+				for _, i := range f.Imports {
+					imports[i.Path] = struct{}{}
+				}
+			}
+
+			p.Imports = append(p.Imports, sortedKeys(imports)...)
+			linkReportTypes(p.Types)
+
+			byPath[path] = p
+			data.Packages = append(data.Packages, p)
+		}
+	}
+
+	edges, _ := FindImportEdges(directories, module, &Config{Requires: config.Requires, ExcludeStdLib: true}, &ImportsOptions{})
+	for _, e := range edges {
+		to, ok := byPath[e.To]
+		if !ok {
+			continue
+		}
+		to.Importers = append(to.Importers, e.From)
+		data.Edges = append(data.Edges, &ReportEdge{From: e.From, To: e.To})
+	}
+
+	for _, row := range FindStats(directories).Rows() {
+		data.Stats = append(data.Stats, &ReportStat{Name: row.Name, Number: row.Number})
+	}
+
+	for _, i := range FindImportCounts(directories) {
+		data.Imports = append(data.Imports, &ReportImport{Path: i.Path, Count: i.Count, StdLib: isStdLib(i.Path)})
+	}
+
+	return data
+}
+
+func FormatReport(directories map[string]*Directory, module string, config *Config) (string, error) {
+	tmpl, err := template.New("report").Parse(reportTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, NewReportData(directories, module, config)); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,65 @@
+package internal_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func TestNewReportData(t *testing.T) {
+	directories := loadFixture(t, "testdata/query", "example.com/query", &internal.Config{})
+	data := internal.NewReportData(directories, "example.com/query", &internal.Config{})
+
+	var packages []string
+	for _, p := range data.Packages {
+		packages = append(packages, p.Path)
+	}
+	assertEqual(t, []string{"example.com/query/api", "example.com/query/store", "example.com/query/web"}, packages)
+
+	assertEqual(t, 1, len(data.Edges))
+	assertEqual(t, "example.com/query/web", data.Edges[0].From)
+	assertEqual(t, "example.com/query/store", data.Edges[0].To)
+	assertEqual(t, []string{"example.com/query/web"}, data.Packages[1].Importers)
+
+	store := data.Packages[1]
+	assertEqual(t, "DB", store.Types[0].Name)
+	assertEqual(t, []string{"sync.Mutex", "conn *sql.DB", "Name string"}, store.Types[0].Fields)
+	assertEqual(t, []string{"Close() error"}, store.Types[0].Methods)
+
+	assertEqual(t, "packages count", data.Stats[0].Name)
+	assertEqual(t, 3, data.Stats[0].Number)
+
+	assertEqual(t, "database/sql", data.Imports[0].Path)
+	assertEqual(t, 2, data.Imports[0].Count)
+}
+
+func TestNewReportDataTests(t *testing.T) {
+	packages := func(config *internal.Config) []string {
+		var res []string
+		directories := loadFixture(t, "testdata/tests", "example.com/tests", &internal.Config{IncludeTests: true})
+		for _, p := range internal.NewReportData(directories, "example.com/tests", config).Packages {
+			res = append(res, p.Path)
+		}
+		return res
+	}
+
+	assertEqual(t, []string{"example.com/tests/calc", "example.com/tests/untested"}, packages(&internal.Config{}))
+	assertEqual(t, []string{
+		"example.com/tests/calc",
+		"example.com/tests/calc_test",
+		"example.com/tests/untested",
+	}, packages(&internal.Config{IncludeTests: true}))
+}
+
+func TestFormatReport(t *testing.T) {
+	directories := loadFixture(t, "testdata/query", "example.com/query", &internal.Config{})
+	res, err := internal.FormatReport(directories, "example.com/query", &internal.Config{})
+	assertEqual(t, nil, err)
+
+	assertEqual(t, true, strings.HasPrefix(res, "<!DOCTYPE html>"))
+	assertEqual(t, true, strings.Contains(res, "<title>example.com/query - godiss report</title>"))
+	assertEqual(t, true, strings.Contains(res, `const data = {"module":"example.com/query","packages":[`))
+	assertEqual(t, false, strings.Contains(res, "<script src"))
+	assertEqual(t, false, strings.Contains(res, "<link"))
+}
@@ -0,0 +1,256 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Server struct {
+	target      string
+	module      string
+	config      *Config
+	load        func() (map[string]*Directory, error)
+	template    *template.Template
+	mu          sync.RWMutex
+	directories map[string]*Directory
+	fingerprint map[string]string
+	data        *ReportData
+	version     int
+	subscribers map[chan int]struct{}
+}
+
+func NewServer(target, module string, config *Config, load func() (map[string]*Directory, error)) (*Server, error) {
+	tmpl, err := template.New("report").Parse(reportTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		target:      target,
+		module:      module,
+		config:      config,
+		load:        load,
+		template:    tmpl,
+		directories: map[string]*Directory{},
+		fingerprint: map[string]string{},
+		subscribers: map[chan int]struct{}{},
+	}
+
+	if _, err := s.Refresh(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func directoryFingerprint(path string) string {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return ""
+	}
+
+	var parts []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".go" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d:%d", entry.Name(), info.Size(), info.ModTime().UnixNano()))
+	}
+	sort.Strings(parts)
+
+	return strings.Join(parts, "\n")
+}
+
+func (s *Server) Refresh() (bool, error) {
+	listing, err := s.load()
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	current := s.directories
+	fingerprints := s.fingerprint
+	s.mu.RUnlock()
+
+	directories := map[string]*Directory{}
+	fingerprint := map[string]string{}
+	changed := len(listing) != len(current)
+
+	for p, directory := range listing {
+		fingerprint[p] = directoryFingerprint(directory.Path)
+
+		if old, ok := current[p]; ok && fingerprints[p] == fingerprint[p] {
+			directories[p] = old
+			continue
+		}
+
+		changed = true
+		if err := ParsePackage(directory, s.module, s.target, s.config); err != nil {
+			return false, err
+		}
+		directories[p] = directory
+	}
+
+	if !changed {
+		return false, nil
+	}
+
+	data := NewReportData(directories, s.module, s.config)
+
+	s.mu.Lock()
+	s.directories = directories
+	s.fingerprint = fingerprint
+	s.data = data
+	s.version++
+	version := s.version
+	for ch := range s.subscribers {
+		select {
+		case ch <- version:
+		default:
+		}
+	}
+	s.mu.Unlock()
+
+	return true, nil
+}
+
+func (s *Server) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Refresh(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+func (s *Server) snapshot() (*ReportData, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data, s.version
+}
+
+func (s *Server) subscribe() chan int {
+	ch := make(chan int, 1)
+	s.mu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+	return ch
+}
+
+func (s *Server) unsubscribe(ch chan int) {
+	s.mu.Lock()
+	delete(s.subscribers, ch)
+	s.mu.Unlock()
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		data, _ := s.snapshot()
+		live := *data
+		live.Live = true
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := s.template.Execute(w, &live); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	mux.HandleFunc("/api/model", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := s.snapshot()
+		writeJSON(w, data)
+	})
+
+	mux.HandleFunc("/api/stats", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := s.snapshot()
+		writeJSON(w, data.Stats)
+	})
+
+	mux.HandleFunc("/api/imports", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := s.snapshot()
+		writeJSON(w, data.Imports)
+	})
+
+	mux.HandleFunc("/api/edges", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := s.snapshot()
+		writeJSON(w, data.Edges)
+	})
+
+	mux.HandleFunc("/api/packages", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := s.snapshot()
+		writeJSON(w, data.Packages)
+	})
+
+	mux.HandleFunc("/api/packages/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/packages/")
+		data, _ := s.snapshot()
+		for _, p := range data.Packages {
+			if p.Path == path {
+				writeJSON(w, p)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+
+		ch := s.subscribe()
+		defer s.unsubscribe(ch)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+
+		_, version := s.snapshot()
+		fmt.Fprintf(w, "event: version\ndata: %d\n\n", version)
+		flusher.Flush()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case version := <-ch:
+				fmt.Fprintf(w, "event: update\ndata: %d\n\n", version)
+				flusher.Flush()
+			}
+		}
+	})
+
+	return mux
+}
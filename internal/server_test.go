@@ -0,0 +1,110 @@
+package internal_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	assertEqual(t, nil, os.MkdirAll(filepath.Dir(path), 0o755))
+	assertEqual(t, nil, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func newTestServer(t *testing.T) (*internal.Server, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/live\n\ngo 1.19\n")
+	writeFile(t, filepath.Join(dir, "api", "api.go"), "package api\n\ntype Request struct {\n\tID string\n}\n")
+
+	server, err := internal.NewServer(dir, "example.com/live", &internal.Config{}, func() (map[string]*internal.Directory, error) {
+		return internal.LoadPackages(dir, "example.com/live", dir)
+	})
+	assertEqual(t, nil, err)
+
+	return server, dir
+}
+
+func getJSON(t *testing.T, h http.Handler, path string, v any) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if rec.Code == http.StatusOK {
+		assertEqual(t, nil, json.Unmarshal(rec.Body.Bytes(), v))
+	}
+	return rec.Code
+}
+
+func TestServerEndpoints(t *testing.T) {
+	server, _ := newTestServer(t)
+	h := server.Handler()
+
+	var packages []*internal.ReportPackage
+	assertEqual(t, http.StatusOK, getJSON(t, h, "/api/packages", &packages))
+	assertEqual(t, 1, len(packages))
+	assertEqual(t, "example.com/live/api", packages[0].Path)
+
+	var pkg internal.ReportPackage
+	assertEqual(t, http.StatusOK, getJSON(t, h, "/api/packages/example.com/live/api", &pkg))
+	assertEqual(t, "Request", pkg.Types[0].Name)
+	assertEqual(t, http.StatusNotFound, getJSON(t, h, "/api/packages/example.com/live/missing", &pkg))
+
+	var stats []*internal.ReportStat
+	assertEqual(t, http.StatusOK, getJSON(t, h, "/api/stats", &stats))
+	assertEqual(t, 1, stats[0].Number)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assertEqual(t, http.StatusOK, rec.Code)
+	assertEqual(t, true, strings.Contains(rec.Body.String(), `new EventSource("/events")`))
+}
+
+func TestServerRefresh(t *testing.T) {
+	server, dir := newTestServer(t)
+
+	changed, err := server.Refresh()
+	assertEqual(t, nil, err)
+	assertEqual(t, false, changed)
+
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/events")
+	assertEqual(t, nil, err)
+	defer res.Body.Close()
+
+	events := bufio.NewReader(res.Body)
+	readEvent := func() string {
+		var lines []string
+		for {
+			line, err := events.ReadString('\n')
+			assertEqual(t, nil, err)
+			if line == "\n" {
+				return strings.Join(lines, "")
+			}
+			lines = append(lines, line)
+		}
+	}
+	assertEqual(t, "event: version\ndata: 1\n", readEvent())
+
+	writeFile(t, filepath.Join(dir, "store", "store.go"), "package store\n\ntype DB struct{}\n")
+
+	changed, err = server.Refresh()
+	assertEqual(t, nil, err)
+	assertEqual(t, true, changed)
+	assertEqual(t, "event: update\ndata: 2\n", readEvent())
+
+	var packages []*internal.ReportPackage
+	getJSON(t, server.Handler(), "/api/packages", &packages)
+	assertEqual(t, 2, len(packages))
+	assertEqual(t, "example.com/live/store", packages[1].Path)
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+type DiagramStyle struct {
+	RankDir   string `json:"rankdir"`
+	FontName  string `json:"fontname"`
+	NodeShape string `json:"node_shape"`
+	NodeColor string `json:"node_color"`
+	EdgeColor string `json:"edge_color"`
+	Splines   string `json:"splines"`
+}
+
+func writeDotHeader(sb *strings.Builder, style *DiagramStyle) {
+	if style == nil {
+		style = &DiagramStyle{}
+	}
+
+	rankdir := style.RankDir
+	if rankdir == "" {
+		rankdir = "LR"
+	}
+	sb.WriteString(fmt.Sprintf("    rankdir=\"%s\"\n", rankdir))
+	if style.Splines != "" {
+		sb.WriteString(fmt.Sprintf("    splines=\"%s\"\n", style.Splines))
+	}
+
+	var node []string
+	if style.FontName != "" {
+		node = append(node, fmt.Sprintf("fontname=\"%s\"", style.FontName))
+	}
+	if style.NodeShape != "" {
+		node = append(node, fmt.Sprintf("shape=\"%s\"", style.NodeShape))
+	}
+	if style.NodeColor != "" {
+		node = append(node, fmt.Sprintf("color=\"%s\"", style.NodeColor))
+	}
+	if len(node) > 0 {
+		sb.WriteString(fmt.Sprintf("    node [%s]\n", strings.Join(node, ", ")))
+	}
+
+	var edge []string
+	if style.FontName != "" {
+		edge = append(edge, fmt.Sprintf("fontname=\"%s\"", style.FontName))
+	}
+	if style.EdgeColor != "" {
+		edge = append(edge, fmt.Sprintf("color=\"%s\"", style.EdgeColor))
+	}
+	if len(edge) > 0 {
+		sb.WriteString(fmt.Sprintf("    edge [%s]\n", strings.Join(edge, ", ")))
+	}
+}
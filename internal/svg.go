@@ -0,0 +1,972 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	svgFontSize     = 12.0
+	svgCharWidth    = 7.0
+	svgLineHeight   = 16.0
+	svgHeaderHeight = 24.0
+	svgNodeHeight   = 30.0
+	svgNodeSep      = 16.0
+	svgRankSep      = 60.0
+	svgClusterPad   = 12.0
+	svgLabelHeight  = 20.0
+	svgDummySize    = 8.0
+	svgMargin       = 16.0
+	svgSweeps       = 12
+)
+
+type layoutNode struct {
+	node    *DiagramNode
+	cluster *layoutCluster
+	rank    int
+	index   int
+	pos     float64
+	width   float64
+	height  float64
+	along   float64
+	across  float64
+	in      []*layoutNode
+	out     []*layoutNode
+	x, y    float64
+}
+
+type layoutCluster struct {
+	cluster  *DiagramCluster
+	parent   *layoutCluster
+	children []*layoutCluster
+	nodes    []*layoutNode
+	seq      int
+	depth    int
+	empty    bool
+	start    float64
+	size     float64
+	self     float64
+	selfSize float64
+	box      [4]float64
+}
+
+type layoutEdge struct {
+	edge     *DiagramEdge
+	chain    []*layoutNode
+	reversed bool
+}
+
+type diagramLayout struct {
+	style     *DiagramStyle
+	vertical  bool
+	flip      bool
+	ellipse   bool
+	nodes     []*layoutNode
+	byID      map[string]*layoutNode
+	root      *layoutCluster
+	clusters  []*layoutCluster
+	edges     []*layoutEdge
+	loops     []*layoutEdge
+	layers    [][]*layoutNode
+	rankStart []float64
+	rankEnd   []float64
+	bounds    [4]float64
+}
+
+func textWidth(s string) float64 {
+	return float64(utf8.RuneCountInString(s)) * svgCharWidth
+}
+
+func svgNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64)
+}
+
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\"", "&quot;")
+
+func xmlEscape(s string) string {
+	return xmlEscaper.Replace(s)
+}
+
+func sectionHeight(rows []string) float64 {
+	return float64(len(rows))*svgLineHeight + 8
+}
+
+func (l *diagramLayout) measure(n *DiagramNode) (float64, float64) {
+	if n.Sections == nil {
+		w, h := textWidth(n.Label)+24, svgNodeHeight
+		if l.ellipse {
+			w, h = w*1.25, h*1.2
+		}
+		return w, h
+	}
+
+	w := textWidth(n.Label) + 24
+	h := svgHeaderHeight
+	for _, rows := range n.Sections {
+		for _, row := range rows {
+			w = math.Max(w, textWidth(row)+16)
+		}
+		h += sectionHeight(rows)
+	}
+	return w, h
+}
+
+func newDiagramLayout(d *Diagram) *diagramLayout {
+	style := d.Style
+	if style == nil {
+		style = &DiagramStyle{}
+	}
+
+	rankdir := strings.ToUpper(style.RankDir)
+	shape := strings.ToLower(style.NodeShape)
+
+	l := &diagramLayout{
+		style:    style,
+		vertical: rankdir == "TB" || rankdir == "BT",
+		flip:     rankdir == "RL" || rankdir == "BT",
+		ellipse:  shape == "ellipse" || shape == "oval" || shape == "circle",
+		byID:     map[string]*layoutNode{},
+		root:     &layoutCluster{},
+	}
+
+	byCluster := map[string]*layoutCluster{}
+	for _, c := range d.Clusters {
+		if _, ok := byCluster[c.ID]; ok || c.ID == "" {
+			continue
+		}
+		lc := &layoutCluster{cluster: c}
+		byCluster[c.ID] = lc
+		l.clusters = append(l.clusters, lc)
+	}
+	for _, lc := range l.clusters {
+		parent, ok := byCluster[lc.cluster.Parent]
+		if !ok || parent == lc {
+			parent = l.root
+		}
+		lc.parent = parent
+		parent.children = append(parent.children, lc)
+	}
+
+	for _, n := range d.Nodes {
+		if _, ok := l.byID[n.ID]; ok {
+			continue
+		}
+		c, ok := byCluster[n.Cluster]
+		if !ok {
+			c = l.root
+		}
+		ln := &layoutNode{node: n, cluster: c}
+		ln.width, ln.height = l.measure(n)
+		ln.along, ln.across = ln.width, ln.height
+		if l.vertical {
+			ln.along, ln.across = ln.height, ln.width
+		}
+		c.nodes = append(c.nodes, ln)
+		l.byID[n.ID] = ln
+		l.nodes = append(l.nodes, ln)
+	}
+
+	for _, e := range d.Edges {
+		from, ok := l.byID[e.From]
+		if !ok {
+			continue
+		}
+		to, ok := l.byID[e.To]
+		if !ok {
+			continue
+		}
+		le := &layoutEdge{edge: e, chain: []*layoutNode{from, to}}
+		if from == to {
+			l.loops = append(l.loops, le)
+			continue
+		}
+		l.edges = append(l.edges, le)
+	}
+
+	l.breakCycles()
+	l.assignRanks()
+	l.insertDummies()
+	l.orderLayers()
+	l.measureCluster(l.root, 0)
+	l.placeCluster(l.root, 0)
+	l.assignPositions()
+	l.assignCoordinates()
+
+	return l
+}
+
+func (l *diagramLayout) breakCycles() {
+	outgoing := map[*layoutNode][]*layoutEdge{}
+	for _, e := range l.edges {
+		outgoing[e.chain[0]] = append(outgoing[e.chain[0]], e)
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[*layoutNode]int{}
+
+	var visit func(n *layoutNode)
+	visit = func(n *layoutNode) {
+		state[n] = visiting
+		for _, e := range outgoing[n] {
+			switch state[e.chain[1]] {
+			case visiting:
+				e.reversed = true
+			case 0:
+				visit(e.chain[1])
+			}
+		}
+		state[n] = visited
+	}
+
+	for _, n := range l.nodes {
+		if state[n] == 0 {
+			visit(n)
+		}
+	}
+
+	for _, e := range l.edges {
+		if e.reversed {
+			e.chain[0], e.chain[1] = e.chain[1], e.chain[0]
+		}
+	}
+}
+
+func (l *diagramLayout) assignRanks() {
+	indegree := map[*layoutNode]int{}
+	outgoing := map[*layoutNode][]*layoutNode{}
+	for _, e := range l.edges {
+		indegree[e.chain[1]]++
+		outgoing[e.chain[0]] = append(outgoing[e.chain[0]], e.chain[1])
+	}
+
+	var queue []*layoutNode
+	for _, n := range l.nodes {
+		if indegree[n] == 0 {
+			queue = append(queue, n)
+		}
+	}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, m := range outgoing[n] {
+			if n.rank+1 > m.rank {
+				m.rank = n.rank + 1
+			}
+			indegree[m]--
+			if indegree[m] == 0 {
+				queue = append(queue, m)
+			}
+		}
+	}
+}
+
+func commonCluster(a, b *layoutCluster) *layoutCluster {
+	ancestors := map[*layoutCluster]struct{}{}
+	for c := a; c != nil; c = c.parent {
+		ancestors[c] = struct{}{}
+	}
+	for c := b; c != nil; c = c.parent {
+		if _, ok := ancestors[c]; ok {
+			return c
+		}
+	}
+	return nil
+}
+
+func (l *diagramLayout) insertDummies() {
+	for _, e := range l.edges {
+		from, to := e.chain[0], e.chain[1]
+		chain := []*layoutNode{from}
+		cluster := commonCluster(from.cluster, to.cluster)
+		for r := from.rank + 1; r < to.rank; r++ {
+			dummy := &layoutNode{cluster: cluster, rank: r, across: svgDummySize}
+			cluster.nodes = append(cluster.nodes, dummy)
+			l.nodes = append(l.nodes, dummy)
+			chain = append(chain, dummy)
+		}
+		chain = append(chain, to)
+
+		for i := 1; i < len(chain); i++ {
+			chain[i-1].out = append(chain[i-1].out, chain[i])
+			chain[i].in = append(chain[i].in, chain[i-1])
+		}
+		e.chain = chain
+	}
+}
+
+func (l *diagramLayout) numberClusters(c *layoutCluster, seq int) int {
+	c.seq = seq
+	seq++
+	for _, child := range c.children {
+		child.depth = c.depth + 1
+		seq = l.numberClusters(child, seq)
+	}
+	return seq
+}
+
+func (l *diagramLayout) orderLayers() {
+	l.numberClusters(l.root, 0)
+
+	for _, n := range l.nodes {
+		for len(l.layers) <= n.rank {
+			l.layers = append(l.layers, nil)
+		}
+		l.layers[n.rank] = append(l.layers[n.rank], n)
+	}
+
+	for r := range l.layers {
+		layer := l.layers[r]
+		for i, n := range layer {
+			n.pos = float64(i)
+		}
+		l.sortLayer(layer)
+	}
+
+	for i := 0; i < svgSweeps; i++ {
+		if i%2 == 0 {
+			for r := 1; r < len(l.layers); r++ {
+				l.reorderLayer(l.layers[r], true)
+			}
+			continue
+		}
+		for r := len(l.layers) - 2; r >= 0; r-- {
+			l.reorderLayer(l.layers[r], false)
+		}
+	}
+}
+
+func (l *diagramLayout) sortLayer(layer []*layoutNode) {
+	sort.SliceStable(layer, func(i, j int) bool {
+		if layer[i].cluster.seq != layer[j].cluster.seq {
+			return layer[i].cluster.seq < layer[j].cluster.seq
+		}
+		return layer[i].pos < layer[j].pos
+	})
+	for i, n := range layer {
+		n.index = i
+	}
+}
+
+func (l *diagramLayout) reorderLayer(layer []*layoutNode, down bool) {
+	for _, n := range layer {
+		neighbours := n.out
+		if down {
+			neighbours = n.in
+		}
+		if len(neighbours) == 0 {
+			n.pos = float64(n.index)
+			continue
+		}
+		sum := 0.0
+		for _, m := range neighbours {
+			sum += float64(m.index)
+		}
+		n.pos = sum / float64(len(neighbours))
+	}
+	l.sortLayer(layer)
+}
+
+func (l *diagramLayout) clusterLabelWidth(c *layoutCluster) float64 {
+	return textWidth(c.cluster.Label) + 2*svgClusterPad
+}
+
+func (l *diagramLayout) measureCluster(c *layoutCluster, depth int) {
+	extent := map[int]float64{}
+	count := map[int]int{}
+	for _, n := range c.nodes {
+		extent[n.rank] += n.across
+		count[n.rank]++
+	}
+	for r, e := range extent {
+		e += float64(count[r]-1) * svgNodeSep
+		if e > c.selfSize {
+			c.selfSize = e
+		}
+	}
+
+	inner := c.selfSize
+	for _, child := range c.children {
+		l.measureCluster(child, depth+1)
+		if child.empty {
+			continue
+		}
+		if inner > 0 {
+			inner += svgNodeSep
+		}
+		inner += child.size
+	}
+
+	if inner == 0 {
+		c.empty = true
+		return
+	}
+
+	if c == l.root {
+		c.size = inner
+		return
+	}
+
+	if l.vertical {
+		c.size = math.Max(inner+2*svgClusterPad, l.clusterLabelWidth(c))
+		return
+	}
+	c.size = inner + 2*svgClusterPad + svgLabelHeight
+}
+
+func (l *diagramLayout) placeCluster(c *layoutCluster, start float64) {
+	c.start = start
+	offset := start
+	if c != l.root {
+		offset += svgClusterPad
+		if !l.vertical {
+			offset += svgLabelHeight
+		}
+	}
+	c.self = offset
+	offset += c.selfSize
+
+	for _, child := range c.children {
+		if child.empty {
+			continue
+		}
+		if offset > c.self {
+			offset += svgNodeSep
+		}
+		l.placeCluster(child, offset)
+		offset += child.size
+	}
+}
+
+func (l *diagramLayout) groups(layer []*layoutNode) [][]*layoutNode {
+	var groups [][]*layoutNode
+	for i, n := range layer {
+		if i == 0 || layer[i-1].cluster != n.cluster {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], n)
+	}
+	return groups
+}
+
+func placeRow(row []*layoutNode, lo, hi float64) {
+	for i, n := range row {
+		min := lo + n.across/2
+		if i > 0 {
+			min = row[i-1].pos + row[i-1].across/2 + svgNodeSep + n.across/2
+		}
+		if n.pos < min {
+			n.pos = min
+		}
+	}
+	for i := len(row) - 1; i >= 0; i-- {
+		n := row[i]
+		max := hi - n.across/2
+		if i < len(row)-1 {
+			max = row[i+1].pos - row[i+1].across/2 - svgNodeSep - n.across/2
+		}
+		if n.pos > max {
+			n.pos = max
+		}
+	}
+}
+
+func (l *diagramLayout) assignPositions() {
+	for _, layer := range l.layers {
+		for _, group := range l.groups(layer) {
+			c := group[0].cluster
+			cursor := c.self
+			for _, n := range group {
+				n.pos = cursor + n.across/2
+				cursor += n.across + svgNodeSep
+			}
+		}
+	}
+
+	for i := 0; i < svgSweeps; i++ {
+		down := i%2 == 0
+		for k := range l.layers {
+			r := k
+			if !down {
+				r = len(l.layers) - 1 - k
+			}
+			for _, n := range l.layers[r] {
+				neighbours := n.out
+				if down {
+					neighbours = n.in
+				}
+				if len(neighbours) == 0 {
+					continue
+				}
+				sum := 0.0
+				for _, m := range neighbours {
+					sum += m.pos
+				}
+				n.pos = sum / float64(len(neighbours))
+			}
+			for _, group := range l.groups(l.layers[r]) {
+				c := group[0].cluster
+				placeRow(group, c.self, c.self+c.selfSize)
+			}
+		}
+	}
+}
+
+func (l *diagramLayout) rankSep() float64 {
+	depth := 0
+	for _, c := range l.clusters {
+		if !c.empty && c.depth > depth {
+			depth = c.depth
+		}
+	}
+
+	sep := svgRankSep + float64(depth)*2*svgClusterPad
+	if l.vertical {
+		sep += float64(depth) * svgLabelHeight
+		return sep
+	}
+
+	for _, e := range l.edges {
+		if e.edge.Label != "" {
+			sep = math.Max(sep, textWidth(e.edge.Label)+2*svgNodeSep)
+		}
+	}
+	return sep
+}
+
+func (l *diagramLayout) point(r, o float64) (float64, float64) {
+	if l.flip {
+		r = -r
+	}
+	if l.vertical {
+		return o, r
+	}
+	return r, o
+}
+
+func (l *diagramLayout) assignCoordinates() {
+	sep := l.rankSep()
+	cursor := 0.0
+	for _, layer := range l.layers {
+		thickness := 0.0
+		for _, n := range layer {
+			thickness = math.Max(thickness, n.along)
+		}
+		l.rankStart = append(l.rankStart, cursor)
+		l.rankEnd = append(l.rankEnd, cursor+thickness)
+		cursor += thickness + sep
+	}
+
+	for _, n := range l.nodes {
+		n.x, n.y = l.point(l.rankCenter(n), n.pos)
+	}
+
+	l.boxCluster(l.root)
+}
+
+func (l *diagramLayout) rankCenter(n *layoutNode) float64 {
+	return (l.rankStart[n.rank] + l.rankEnd[n.rank]) / 2
+}
+
+func (l *diagramLayout) boxCluster(c *layoutCluster) (float64, float64, bool) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, n := range c.nodes {
+		if n.node == nil {
+			continue
+		}
+		center := l.rankCenter(n)
+		if l.flip {
+			center = -center
+		}
+		lo = math.Min(lo, center-n.along/2)
+		hi = math.Max(hi, center+n.along/2)
+	}
+	for _, child := range c.children {
+		if child.empty {
+			continue
+		}
+		clo, chi, _ := l.boxCluster(child)
+		lo = math.Min(lo, clo)
+		hi = math.Max(hi, chi)
+	}
+
+	if c == l.root || c.empty {
+		return lo, hi, false
+	}
+
+	lo -= svgClusterPad
+	hi += svgClusterPad
+	if l.vertical {
+		lo -= svgLabelHeight
+		c.box = [4]float64{c.start, lo, c.start + c.size, hi}
+		return lo, hi, true
+	}
+
+	hi = math.Max(hi, lo+l.clusterLabelWidth(c))
+	c.box = [4]float64{lo, c.start, hi, c.start + c.size}
+	return lo, hi, true
+}
+
+func (l *diagramLayout) route(e *layoutEdge) (string, float64, float64) {
+	type pt struct{ r, o float64 }
+
+	first, last := e.chain[0], e.chain[len(e.chain)-1]
+	points := []pt{{l.rankCenter(first) + first.along/2, first.pos}}
+	for _, n := range e.chain[1 : len(e.chain)-1] {
+		points = append(points, pt{l.rankStart[n.rank], n.pos}, pt{l.rankEnd[n.rank], n.pos})
+	}
+	points = append(points, pt{l.rankCenter(last) - last.along/2, last.pos})
+
+	ortho := strings.ToLower(l.style.Splines) == "ortho"
+
+	var sb strings.Builder
+	var lx, ly float64
+	write := func(cmd string, r, o float64) {
+		x, y := l.point(r, o)
+		l.extend(x, y, x, y)
+		sb.WriteString(fmt.Sprintf("%s%s,%s ", cmd, svgNumber(x), svgNumber(y)))
+	}
+
+	write("M", points[0].r, points[0].o)
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		if i%2 == 0 {
+			write("L", b.r, b.o)
+			continue
+		}
+
+		rank := e.chain[(i-1)/2].rank
+		gap := (l.rankEnd[rank] + l.rankStart[rank+1]) / 2
+		if i == 1 {
+			lx, ly = l.point(gap, (a.o+b.o)/2)
+		}
+
+		switch {
+		case a.o == b.o:
+			write("L", b.r, b.o)
+		case ortho:
+			write("L", gap, a.o)
+			write("L", gap, b.o)
+			write("L", b.r, b.o)
+		default:
+			write("C", gap, a.o)
+			write("", gap, b.o)
+			write("", b.r, b.o)
+		}
+	}
+
+	return strings.TrimSpace(sb.String()), lx, ly
+}
+
+func (l *diagramLayout) extend(x0, y0, x1, y1 float64) {
+	l.bounds[0] = math.Min(l.bounds[0], x0)
+	l.bounds[1] = math.Min(l.bounds[1], y0)
+	l.bounds[2] = math.Max(l.bounds[2], x1)
+	l.bounds[3] = math.Max(l.bounds[3], y1)
+}
+
+func orDefault(v, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
+
+func FormatSVG(d *Diagram) string {
+	l := newDiagramLayout(d)
+
+	l.bounds = [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, n := range l.nodes {
+		if n.node != nil {
+			l.extend(n.x-n.width/2, n.y-n.height/2, n.x+n.width/2, n.y+n.height/2)
+		}
+	}
+	for _, c := range l.clusters {
+		if !c.empty {
+			l.extend(c.box[0], c.box[1], c.box[2], c.box[3])
+		}
+	}
+
+	type routed struct {
+		edge   *layoutEdge
+		path   string
+		lx, ly float64
+	}
+	var edges []routed
+	for _, e := range l.edges {
+		path, lx, ly := l.route(e)
+		if e.edge.Label != "" {
+			w := textWidth(e.edge.Label)
+			l.extend(lx-w/2, ly-svgFontSize, lx+w/2, ly+svgFontSize)
+		}
+		edges = append(edges, routed{e, path, lx, ly})
+	}
+	for _, e := range l.loops {
+		n := e.chain[0]
+		l.extend(n.x, n.y-n.height/2-24, n.x+n.width/2+32, n.y)
+	}
+
+	if len(l.nodes) == 0 {
+		l.bounds = [4]float64{0, 0, 0, 0}
+	}
+
+	dx, dy := svgMargin-l.bounds[0], svgMargin-l.bounds[1]
+	width := l.bounds[2] - l.bounds[0] + 2*svgMargin
+	height := l.bounds[3] - l.bounds[1] + 2*svgMargin
+
+	font := orDefault(l.style.FontName, "Helvetica,Arial,sans-serif")
+	nodeColor := orDefault(l.style.NodeColor, "#333333")
+	edgeColor := orDefault(l.style.EdgeColor, "#555555")
+
+	var sb strings.Builder
+	sb.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	sb.WriteString(fmt.Sprintf(
+		"<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%s\" viewBox=\"0 0 %s %s\" font-family=\"%s\" font-size=\"%s\">\n",
+		svgNumber(width), svgNumber(height), svgNumber(width), svgNumber(height), xmlEscape(font), svgNumber(svgFontSize),
+	))
+	markers := map[string]string{edgeColor: "arrow"}
+	colors := []string{edgeColor}
+	for _, e := range append(append([]*layoutEdge{}, l.edges...), l.loops...) {
+		if _, ok := markers[e.edge.Color]; !ok && e.edge.Color != "" {
+			markers[e.edge.Color] = fmt.Sprintf("arrow-%d", len(colors))
+			colors = append(colors, e.edge.Color)
+		}
+	}
+
+	sb.WriteString("  <defs>\n")
+	for _, color := range colors {
+		sb.WriteString(fmt.Sprintf(
+			"    <marker id=\"%s\" viewBox=\"0 0 10 10\" refX=\"10\" refY=\"5\" markerWidth=\"8\" markerHeight=\"8\" orient=\"auto-start-reverse\"><path d=\"M0,0 L10,5 L0,10 z\" fill=\"%s\"/></marker>\n",
+			markers[color], xmlEscape(color),
+		))
+	}
+	sb.WriteString("  </defs>\n")
+	sb.WriteString(fmt.Sprintf("  <g transform=\"translate(%s,%s)\">\n", svgNumber(dx), svgNumber(dy)))
+
+	for _, c := range l.clusters {
+		if c.empty {
+			continue
+		}
+		x0, y0, x1, y1 := c.box[0], c.box[1], c.box[2], c.box[3]
+		sb.WriteString(fmt.Sprintf("    <g class=\"cluster\">\n      <title>%s</title>\n", xmlEscape(c.cluster.ID)))
+		sb.WriteString(fmt.Sprintf(
+			"      <rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" rx=\"4\" fill=\"#000000\" fill-opacity=\"0.03\" stroke=\"%s\"/>\n",
+			svgNumber(x0), svgNumber(y0), svgNumber(x1-x0), svgNumber(y1-y0), xmlEscape(orDefault(c.cluster.Color, "#999999")),
+		))
+		sb.WriteString(fmt.Sprintf(
+			"      <text x=\"%s\" y=\"%s\" font-weight=\"bold\">%s</text>\n",
+			svgNumber(x0+svgClusterPad), svgNumber(y0+svgLabelHeight-4), xmlEscape(c.cluster.Label),
+		))
+		sb.WriteString("    </g>\n")
+	}
+
+	for _, r := range edges {
+		marker := "marker-end"
+		if r.edge.reversed {
+			marker = "marker-start"
+		}
+		width := r.edge.edge.Width
+		if width < 1 {
+			width = 1
+		}
+		color := orDefault(r.edge.edge.Color, edgeColor)
+		sb.WriteString(fmt.Sprintf(
+			"    <g class=\"edge\">\n      <title>%s -&gt; %s</title>\n",
+			xmlEscape(r.edge.edge.From), xmlEscape(r.edge.edge.To),
+		))
+		sb.WriteString(fmt.Sprintf(
+			"      <path d=\"%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"%d\" %s=\"url(#%s)\"/>\n",
+			r.path, xmlEscape(color), width, marker, markers[color],
+		))
+		if r.edge.edge.Label != "" {
+			sb.WriteString(fmt.Sprintf(
+				"      <text x=\"%s\" y=\"%s\" text-anchor=\"middle\" font-size=\"10\" fill=\"%s\" stroke=\"#ffffff\" stroke-width=\"3\" paint-order=\"stroke\">%s</text>\n",
+				svgNumber(r.lx), svgNumber(r.ly-4), xmlEscape(color), xmlEscape(r.edge.edge.Label),
+			))
+		}
+		sb.WriteString("    </g>\n")
+	}
+
+	for _, e := range l.loops {
+		n := e.chain[0]
+		x, y := n.x+n.width/2, n.y
+		color := orDefault(e.edge.Color, edgeColor)
+		sb.WriteString(fmt.Sprintf(
+			"    <g class=\"edge\">\n      <title>%s -&gt; %s</title>\n",
+			xmlEscape(e.edge.From), xmlEscape(e.edge.To),
+		))
+		sb.WriteString(fmt.Sprintf(
+			"      <path d=\"M%s,%s C%s,%s %s,%s %s,%s\" fill=\"none\" stroke=\"%s\" marker-end=\"url(#%s)\"/>\n",
+			svgNumber(x-8), svgNumber(y-n.height/2), svgNumber(x+24), svgNumber(y-n.height/2-24),
+			svgNumber(x+32), svgNumber(y), svgNumber(x), svgNumber(y), xmlEscape(color), markers[color],
+		))
+		sb.WriteString("    </g>\n")
+	}
+
+	for _, n := range l.nodes {
+		if n.node != nil {
+			writeSVGNode(&sb, n, l.ellipse, orDefault(n.node.Color, nodeColor))
+		}
+	}
+
+	sb.WriteString("  </g>\n")
+	sb.WriteString("</svg>\n")
+
+	return sb.String()
+}
+
+func writeSVGNode(sb *strings.Builder, n *layoutNode, ellipse bool, color string) {
+	x0, y0 := n.x-n.width/2, n.y-n.height/2
+
+	sb.WriteString(fmt.Sprintf("    <g class=\"node\">\n      <title>%s</title>\n", xmlEscape(n.node.ID)))
+
+	if n.node.Sections == nil {
+		if ellipse {
+			sb.WriteString(fmt.Sprintf(
+				"      <ellipse cx=\"%s\" cy=\"%s\" rx=\"%s\" ry=\"%s\" fill=\"#ffffff\" stroke=\"%s\"/>\n",
+				svgNumber(n.x), svgNumber(n.y), svgNumber(n.width/2), svgNumber(n.height/2), xmlEscape(color),
+			))
+		} else {
+			sb.WriteString(fmt.Sprintf(
+				"      <rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" fill=\"#ffffff\" stroke=\"%s\"/>\n",
+				svgNumber(x0), svgNumber(y0), svgNumber(n.width), svgNumber(n.height), xmlEscape(color),
+			))
+		}
+		sb.WriteString(fmt.Sprintf(
+			"      <text x=\"%s\" y=\"%s\" text-anchor=\"middle\">%s</text>\n",
+			svgNumber(n.x), svgNumber(n.y+4), xmlEscape(n.node.Label),
+		))
+		sb.WriteString("    </g>\n")
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf(
+		"      <rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" fill=\"%s\" fill-opacity=\"0.13\" stroke=\"%s\"/>\n",
+		svgNumber(x0), svgNumber(y0), svgNumber(n.width), svgNumber(n.height), xmlEscape(orDefault(n.node.Color, "#88ff00")), xmlEscape(color),
+	))
+	sb.WriteString(fmt.Sprintf(
+		"      <text x=\"%s\" y=\"%s\" text-anchor=\"middle\" font-weight=\"bold\">%s</text>\n",
+		svgNumber(n.x), svgNumber(y0+16), xmlEscape(n.node.Label),
+	))
+
+	cursor := y0 + svgHeaderHeight
+	for s, rows := range n.node.Sections {
+		sb.WriteString(fmt.Sprintf(
+			"      <line x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\" stroke=\"%s\"/>\n",
+			svgNumber(x0), svgNumber(cursor), svgNumber(x0+n.width), svgNumber(cursor), xmlEscape(color),
+		))
+		for i, row := range rows {
+			fill := ""
+			if s < len(n.node.RowColors) && i < len(n.node.RowColors[s]) && n.node.RowColors[s][i] != "" {
+				fill = fmt.Sprintf(" fill=\"%s\"", xmlEscape(n.node.RowColors[s][i]))
+			}
+			sb.WriteString(fmt.Sprintf(
+				"      <text x=\"%s\" y=\"%s\"%s>%s</text>\n",
+				svgNumber(x0+8), svgNumber(cursor+4+12+float64(i)*svgLineHeight), fill, xmlEscape(row),
+			))
+		}
+		cursor += sectionHeight(rows)
+	}
+
+	sb.WriteString("    </g>\n")
+}
+
+func structNode(id, cluster string, s *Struct) *DiagramNode {
+	fields := []string{}
+	for _, f := range s.Fields {
+		fields = append(fields, strings.TrimSpace(fmt.Sprintf("%s %s", f.Name, f.Type)))
+	}
+	methods := []string{}
+	for _, m := range s.Methods {
+		methods = append(methods, strings.TrimSpace(m.Signature))
+	}
+	return &DiagramNode{ID: id, Label: s.Name, Sections: [][]string{fields, methods}, Cluster: cluster}
+}
+
+func FormatPackagesSVG(directories map[string]*Directory, style *DiagramStyle) string {
+	d := &Diagram{Style: style}
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			cluster := fmt.Sprintf("%s:%s", directory.Path, pkg.Name)
+			d.Clusters = append(d.Clusters, &DiagramCluster{ID: cluster, Label: directory.Path})
+			sort.Sort(ByFilePath(pkg.Files))
+			for _, f := range pkg.Files {
+				for _, s := range f.Structs {
+					d.Nodes = append(d.Nodes, structNode(fmt.Sprintf("%s.%s", pkg.ModulePath, s.Name), cluster, s))
+				}
+			}
+		}
+	}
+
+	return FormatSVG(d)
+}
+
+func FormatStructsSVG(structs []*Struct, style *DiagramStyle) string {
+	d := &Diagram{Style: style}
+	for _, s := range structs {
+		d.Nodes = append(d.Nodes, structNode(s.Name, "", s))
+	}
+	return FormatSVG(d)
+}
+
+func addDiagramCluster(d *Diagram, c *importCluster, parent, module string) {
+	id := parent
+	if c.Path != "" {
+		id = c.Path
+		d.Clusters = append(d.Clusters, &DiagramCluster{ID: id, Label: c.Label, Parent: parent})
+	}
+
+	for _, n := range c.Nodes {
+		label := n
+		if rel := relativeImport(n, module); rel != n && rel != "" {
+			label = path.Base(rel)
+		}
+		d.Nodes = append(d.Nodes, &DiagramNode{ID: n, Label: label, Cluster: id})
+	}
+
+	keys := make([]string, 0, len(c.Children))
+	for k := range c.Children {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		addDiagramCluster(d, c.Children[k], id, module)
+	}
+}
+
+func FormatImportsSVG(directories map[string]*Directory, module string, config *Config, opts *ImportsOptions) string {
+	d := &Diagram{Style: opts.Style}
+
+	edges, nodes := FindImportEdges(directories, module, config, opts)
+
+	if opts.Cluster {
+		for _, c := range buildImportClusters(directories, module, config, nodes) {
+			addDiagramCluster(d, c, "", module)
+		}
+	} else {
+		for _, n := range sortedNodes(nodes) {
+			d.Nodes = append(d.Nodes, &DiagramNode{ID: n, Label: n})
+		}
+	}
+
+	for _, e := range edges {
+		edge := &DiagramEdge{From: e.From, To: e.To}
+		if opts.Weights {
+			edge.Label = fmt.Sprintf("%d packages, %d files", len(e.Packages), e.Files)
+			edge.Width = penWidth(len(e.Packages))
+		}
+		d.Edges = append(d.Edges, edge)
+	}
+
+	return FormatSVG(d)
+}
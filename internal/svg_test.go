@@ -0,0 +1,170 @@
+package internal_test
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+type svgRect struct {
+	X      float64 `xml:"x,attr"`
+	Y      float64 `xml:"y,attr"`
+	Width  float64 `xml:"width,attr"`
+	Height float64 `xml:"height,attr"`
+}
+
+type svgPath struct {
+	D           string `xml:"d,attr"`
+	MarkerStart string `xml:"marker-start,attr"`
+	MarkerEnd   string `xml:"marker-end,attr"`
+}
+
+type svgGroup struct {
+	Class  string     `xml:"class,attr"`
+	Title  string     `xml:"title"`
+	Rect   *svgRect   `xml:"rect"`
+	Path   *svgPath   `xml:"path"`
+	Texts  []string   `xml:"text"`
+	Groups []svgGroup `xml:"g"`
+}
+
+type svgDocument struct {
+	Width  float64  `xml:"width,attr"`
+	Height float64  `xml:"height,attr"`
+	Root   svgGroup `xml:"g"`
+}
+
+func parseSVG(t *testing.T, content string) map[string][]svgGroup {
+	t.Helper()
+	var doc svgDocument
+	assertEqual(t, nil, xml.Unmarshal([]byte(content), &doc))
+	assertEqual(t, true, doc.Width > 0 && doc.Height > 0)
+
+	groups := map[string][]svgGroup{}
+	for _, g := range doc.Root.Groups {
+		groups[g.Class] = append(groups[g.Class], g)
+	}
+	return groups
+}
+
+func find(groups []svgGroup, title string) svgGroup {
+	for _, g := range groups {
+		if g.Title == title {
+			return g
+		}
+	}
+	return svgGroup{}
+}
+
+func contains(outer, inner *svgRect) bool {
+	return outer.X <= inner.X && outer.Y <= inner.Y &&
+		inner.X+inner.Width <= outer.X+outer.Width &&
+		inner.Y+inner.Height <= outer.Y+outer.Height
+}
+
+func overlaps(a, b *svgRect) bool {
+	return a.X < b.X+b.Width && b.X < a.X+a.Width && a.Y < b.Y+b.Height && b.Y < a.Y+a.Height
+}
+
+func TestFormatSVG(t *testing.T) {
+	for _, style := range []*internal.DiagramStyle{
+		nil,
+		{RankDir: "TB", Splines: "ortho"},
+		{RankDir: "RL"},
+		{RankDir: "BT"},
+	} {
+		groups := parseSVG(t, internal.FormatSVG(&internal.Diagram{
+			Style: style,
+			Clusters: []*internal.DiagramCluster{
+				{ID: "store", Label: "store"},
+				{ID: "store/sql", Label: "sql", Parent: "store"},
+			},
+			Nodes: []*internal.DiagramNode{
+				{ID: "app", Label: "app"},
+				{ID: "api", Label: "api"},
+				{ID: "memory", Label: "memory", Cluster: "store"},
+				{ID: "sql", Label: "sql", Cluster: "store/sql"},
+				{ID: "DB", Label: "DB", Cluster: "store/sql", Sections: [][]string{{"conn *sql.DB"}, {"Close() error"}}},
+			},
+			Edges: []*internal.DiagramEdge{
+				{From: "app", To: "api"},
+				{From: "api", To: "memory", Label: "2 files"},
+				{From: "memory", To: "sql"},
+				{From: "app", To: "sql"},
+				{From: "sql", To: "api"},
+			},
+		}))
+
+		assertEqual(t, 5, len(groups["node"]))
+		assertEqual(t, 2, len(groups["cluster"]))
+		assertEqual(t, 5, len(groups["edge"]))
+
+		nodes := groups["node"]
+		for i := range nodes {
+			for j := i + 1; j < len(nodes); j++ {
+				assertEqual(t, false, overlaps(nodes[i].Rect, nodes[j].Rect), nodes[i].Title, nodes[j].Title)
+			}
+		}
+
+		store := find(groups["cluster"], "store").Rect
+		sql := find(groups["cluster"], "store/sql").Rect
+		assertEqual(t, true, contains(store, sql))
+		assertEqual(t, true, contains(store, find(nodes, "memory").Rect))
+		assertEqual(t, true, contains(sql, find(nodes, "sql").Rect))
+		assertEqual(t, true, contains(sql, find(nodes, "DB").Rect))
+		assertEqual(t, false, overlaps(store, find(nodes, "app").Rect))
+		assertEqual(t, false, overlaps(store, find(nodes, "api").Rect))
+
+		assertEqual(t, []string{"DB", "conn *sql.DB", "Close() error"}, find(nodes, "DB").Texts)
+
+		back := find(groups["edge"], "sql -> api").Path
+		assertEqual(t, "url(#arrow)", back.MarkerStart)
+		assertEqual(t, "", back.MarkerEnd)
+		assertEqual(t, "url(#arrow)", find(groups["edge"], "app -> api").Path.MarkerEnd)
+		assertEqual(t, []string{"2 files"}, find(groups["edge"], "api -> memory").Texts)
+
+		ortho := style != nil && style.Splines == "ortho"
+		assertEqual(t, !ortho, strings.Contains(find(groups["edge"], "app -> sql").Path.D, "C"))
+	}
+}
+
+func TestFormatImportsSVG(t *testing.T) {
+	directories := loadFixture(t, "testdata/imports", "example.com/imports", &internal.Config{})
+
+	groups := parseSVG(t, internal.FormatImportsSVG(directories, "example.com/imports", &internal.Config{
+		Requires:      []string{"github.com/acme/widgets"},
+		ExcludeStdLib: true,
+	}, &internal.ImportsOptions{
+		Cluster: true,
+		Weights: true,
+	}))
+
+	var clusters []string
+	for _, c := range groups["cluster"] {
+		clusters = append(clusters, c.Title)
+	}
+	assertEqual(t, []string{"cmd", "internal", "internal/store", "external", "external_github.com/acme/widgets"}, clusters)
+	assertEqual(t, []string{"app"}, find(groups["node"], "example.com/imports/cmd/app").Texts)
+	assertEqual(t, 6, len(groups["edge"]))
+	assertEqual(t, []string{"1 packages, 1 files"}, find(groups["edge"], "example.com/imports/cmd/app -> example.com/imports/internal/api").Texts)
+}
+
+func TestFormatPackagesSVG(t *testing.T) {
+	directories := loadFixture(t, "testdata/query", "example.com/query", &internal.Config{})
+
+	groups := parseSVG(t, internal.FormatPackagesSVG(directories, nil))
+
+	db := find(groups["node"], "example.com/query/store.DB")
+	assertEqual(t, "DB", db.Texts[0])
+	assertEqual(t, true, strings.Contains(strings.Join(db.Texts, "\n"), "sync.Mutex"))
+
+	assertEqual(t, 3, len(groups["cluster"]))
+	for _, c := range groups["cluster"] {
+		for _, n := range groups["node"] {
+			inside := strings.HasPrefix(n.Title, "example.com/query/"+c.Texts[0][strings.LastIndex(c.Texts[0], "/")+1:]+".")
+			assertEqual(t, inside, contains(c.Rect, n.Rect), c.Title, n.Title)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"fmt"
+	"go/ast"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+type TemplateData struct {
+	Module   string
+	Requires []string
+	Packages []*Package
+	Stats    *Stats
+}
+
+var templateColors = map[string]string{
+	"red":    Red,
+	"green":  Green,
+	"yellow": Yellow,
+	"blue":   Blue,
+	"purple": Purple,
+	"cyan":   Cyan,
+}
+
+var TemplateFuncs = template.FuncMap{
+	"visibility": templateVisibility,
+	"colorize":   templateColorize,
+	"join":       templateJoin,
+	"sort":       templateSort,
+	"name":       templateName,
+}
+
+func templateName(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case *Struct:
+		return v.Name, nil
+	case *Field:
+		return fieldName(v), nil
+	case *Method:
+		return methodName(v), nil
+	case *Function:
+		return v.Name, nil
+	case *Interface:
+		return v.Name, nil
+	case *NamedType:
+		return v.Name, nil
+	case *Package:
+		return v.Name, nil
+	case *Import:
+		return v.Path, nil
+	case *File:
+		return filepath.Base(v.Path), nil
+	}
+	return "", fmt.Errorf("name: unsupported value of type %T", v)
+}
+
+func templateVisibility(v any) (string, error) {
+	name, err := templateName(v)
+	if err != nil {
+		return "", fmt.Errorf("visibility: unsupported value of type %T", v)
+	}
+	if ast.IsExported(name) {
+		return "public", nil
+	}
+	return "private", nil
+}
+
+func templateColorize(color string, v any) (string, error) {
+	c, ok := templateColors[color]
+	if !ok {
+		names := make([]string, 0, len(templateColors))
+		for name := range templateColors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("colorize: unknown color %q, expected one of: %s", color, strings.Join(names, ", "))
+	}
+	return fmt.Sprintf("%s%v%s", c, v, NoColor), nil
+}
+
+func templateJoin(sep string, items any) (string, error) {
+	if list, ok := items.([]string); ok {
+		return strings.Join(list, sep), nil
+	}
+
+	value := reflect.ValueOf(items)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: unsupported value of type %T", items)
+	}
+
+	parts := make([]string, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		item := value.Index(i).Interface()
+		if name, err := templateName(item); err == nil {
+			parts = append(parts, name)
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%v", item))
+	}
+	return strings.Join(parts, sep), nil
+}
+
+func templateSort(items any) (any, error) {
+	value := reflect.ValueOf(items)
+	if value.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("sort: unsupported value of type %T", items)
+	}
+
+	sorted := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+	reflect.Copy(sorted, value)
+
+	keys := make([]string, sorted.Len())
+	for i := range keys {
+		item := sorted.Index(i).Interface()
+		name, err := templateName(item)
+		if err != nil {
+			name = fmt.Sprintf("%v", item)
+		}
+		keys[i] = name
+	}
+
+	sort.Stable(templateSorter{keys, reflect.Swapper(sorted.Interface())})
+
+	return sorted.Interface(), nil
+}
+
+type templateSorter struct {
+	keys []string
+	swap func(i, j int)
+}
+
+func (s templateSorter) Len() int           { return len(s.keys) }
+func (s templateSorter) Less(i, j int) bool { return s.keys[i] < s.keys[j] }
+func (s templateSorter) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.swap(i, j)
+}
+
+func NewTemplateData(directories map[string]*Directory, module string, config *Config) *TemplateData {
+	data := &TemplateData{
+		Module:   module,
+		Requires: config.Requires,
+		Stats:    FindStats(directories),
+	}
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			sort.Sort(ByFilePath(pkg.Files))
+			data.Packages = append(data.Packages, pkg)
+		}
+	}
+
+	return data
+}
+
+func FormatTemplate(directories map[string]*Directory, module string, config *Config, path string) (string, error) {
+	tmpl, err := template.New(filepath.Base(path)).Funcs(TemplateFuncs).ParseFiles(path)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, NewTemplateData(directories, module, config)); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
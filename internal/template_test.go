@@ -0,0 +1,54 @@
+package internal_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func TestFormatTemplate(t *testing.T) {
+	directories := loadFixture(t, "testdata/query", "example.com/query", &internal.Config{})
+	res, err := internal.FormatTemplate(directories, "example.com/query", &internal.Config{}, "testdata/templates/wiki.tmpl")
+	assertEqual(t, nil, err)
+
+	expected := strings.Join([]string{
+		"# example.com/query",
+		"",
+		"3 packages, 4 structs",
+		"",
+		"## example.com/query/api",
+		"",
+		"| Route | public | Path, Handler |",
+		"",
+		"## example.com/query/store",
+		"",
+		"| DB | public | Mutex, conn, Name |",
+		"  - <green>Close<nocolor>",
+		"",
+		"| cache | private | mu, items |",
+		"",
+		"## example.com/query/web",
+		"",
+		"| Server | public | DB, Addr, handler, tx |",
+		"  - <green>ServeHTTP<nocolor>",
+		"",
+	}, "\n")
+	expected = strings.ReplaceAll(expected, "<green>", internal.Green)
+	expected = strings.ReplaceAll(expected, "<nocolor>", internal.NoColor)
+
+	assertEqual(t, expected, res)
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	sorted, err := internal.TemplateFuncs["sort"].(func(any) (any, error))([]string{"b", "c", "a"})
+	assertEqual(t, nil, err)
+	assertEqual(t, []string{"a", "b", "c"}, sorted)
+
+	visibility, err := internal.TemplateFuncs["visibility"].(func(any) (string, error))(&internal.Method{Signature: "close() error"})
+	assertEqual(t, nil, err)
+	assertEqual(t, "private", visibility)
+
+	_, err = internal.TemplateFuncs["colorize"].(func(string, any) (string, error))("pink", "x")
+	assertEqual(t, `colorize: unknown color "pink", expected one of: blue, cyan, green, purple, red, yellow`, err.Error())
+}
@@ -0,0 +1,12 @@
+package api
+
+import (
+	model "example.com/aliases/model"
+	"example.com/aliases/multi"
+	"example.com/aliases/store"
+)
+
+func Get() *model.User {
+	multi.Run()
+	return store.Find()
+}
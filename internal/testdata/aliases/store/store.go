@@ -0,0 +1,11 @@
+package store
+
+import (
+	_ "embed"
+
+	m "example.com/aliases/model"
+)
+
+func Find() *m.User {
+	return &m.User{}
+}
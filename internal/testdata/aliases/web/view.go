@@ -0,0 +1,7 @@
+package web
+
+import "example.com/aliases/model"
+
+func View(u *model.User) string {
+	return u.Name
+}
@@ -0,0 +1,13 @@
+package web
+
+import (
+	. "strings"
+
+	domain "example.com/aliases/model"
+	"example.com/aliases/store"
+)
+
+func Render(u *domain.User) string {
+	_ = store.Find()
+	return ToUpper(u.Name)
+}
@@ -0,0 +1,66 @@
+package complexity
+
+func Simple() int {
+	return 1
+}
+
+func Branches(a, b int, flag bool) int {
+	if a > 0 && b > 0 {
+		return 1
+	} else if a < 0 || b < 0 || flag {
+		return 2
+	} else {
+		return 3
+	}
+}
+
+func Nested(items [][]int) int {
+	total := 0
+	for _, row := range items {
+		for _, v := range row {
+			if v%2 == 0 {
+				continue
+			}
+			switch {
+			case v > 10:
+				total += v
+			case v > 5:
+				total -= v
+			default:
+			}
+		}
+	}
+	return total
+}
+
+type Worker struct{}
+
+func (w *Worker) Run(jobs []func() error) error {
+	handle := func(err error) error {
+		if err != nil {
+			return err
+		}
+		return nil
+	}
+	for _, job := range jobs {
+		if err := handle(job()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type Counter int
+
+func (c Counter) Next() Counter {
+outer:
+	for {
+		select {
+		case <-make(chan int):
+			break outer
+		default:
+			return c + 1
+		}
+	}
+	return c
+}
@@ -0,0 +1,5 @@
+package api
+
+type Client struct {
+	Endpoint string
+}
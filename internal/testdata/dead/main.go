@@ -0,0 +1,7 @@
+package main
+
+import "example.com/dead/used"
+
+func main() {
+	used.Do()
+}
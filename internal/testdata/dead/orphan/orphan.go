@@ -0,0 +1,7 @@
+package orphan
+
+import "example.com/dead/orphan/child"
+
+func Unused() {
+	child.Run()
+}
@@ -0,0 +1,5 @@
+package tested
+
+func Sum(a, b int) int {
+	return a + b
+}
@@ -0,0 +1,11 @@
+package tested
+
+import (
+	"testing"
+
+	"example.com/dead/testhelper"
+)
+
+func TestSum(t *testing.T) {
+	testhelper.Equal(t, 3, Sum(1, 2))
+}
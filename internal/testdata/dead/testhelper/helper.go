@@ -0,0 +1,9 @@
+package testhelper
+
+import "testing"
+
+func Equal(t *testing.T, expected, actual int) {
+	if expected != actual {
+		t.Fail()
+	}
+}
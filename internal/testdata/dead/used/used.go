@@ -0,0 +1,3 @@
+package used
+
+func Do() {}
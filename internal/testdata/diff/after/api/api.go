@@ -0,0 +1,7 @@
+package api
+
+import "example.com/diff/store"
+
+type Server struct {
+	db *store.DB
+}
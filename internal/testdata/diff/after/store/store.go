@@ -0,0 +1,25 @@
+package store
+
+import "strings"
+
+type DB struct {
+	name  string
+	size  int64
+	cache map[string]string
+}
+
+func (d *DB) Get(key string, fallback string) string {
+	return strings.Join([]string{d.name, key, fallback}, "")
+}
+
+type Cache struct {
+	items map[string]string
+}
+
+type Header struct {
+	Magic uint32
+	_     [4]byte
+	Size  uint64
+	_     [6]byte
+	_     [8]byte
+}
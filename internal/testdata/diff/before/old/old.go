@@ -0,0 +1,5 @@
+package old
+
+type Thing struct {
+	Name string
+}
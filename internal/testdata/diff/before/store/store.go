@@ -0,0 +1,27 @@
+package store
+
+import "fmt"
+
+type DB struct {
+	name string
+	size int
+}
+
+func (d *DB) Get(key string) string {
+	return fmt.Sprint(d.name, key)
+}
+
+func (d *DB) Close() error {
+	return nil
+}
+
+type Legacy struct {
+	ID int
+}
+
+type Header struct {
+	Magic uint32
+	_     [4]byte
+	Size  uint64
+	_     [2]byte
+}
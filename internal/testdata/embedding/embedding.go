@@ -0,0 +1,43 @@
+package embedding
+
+import (
+	"sync"
+
+	base "example.com/embedding/shared"
+)
+
+type A struct {
+	Name string
+	ID   int
+}
+
+func (a A) Hello() string {
+	return a.Name
+}
+
+type B struct {
+	Name string
+}
+
+func (b *B) Hello() string {
+	return b.Name
+}
+
+type C struct {
+	A
+	*B
+	base.Model
+
+	Own string
+}
+
+type D struct {
+	C
+	sync.Mutex
+
+	ID int
+}
+
+type Loop struct {
+	*Loop
+}
@@ -0,0 +1,12 @@
+package embedding
+
+import base "example.com/embedding/shared"
+
+type Base[T any] struct {
+	Value T
+}
+
+type E struct {
+	Base[string]
+	*base.List[int]
+}
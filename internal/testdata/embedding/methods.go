@@ -0,0 +1,5 @@
+package embedding
+
+func (b *Base[T]) Get() T {
+	return b.Value
+}
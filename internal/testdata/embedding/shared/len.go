@@ -0,0 +1,5 @@
+package shared
+
+func (l *List[T]) Len() int {
+	return len(l.Items)
+}
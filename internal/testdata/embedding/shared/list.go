@@ -0,0 +1,5 @@
+package shared
+
+type List[T any] struct {
+	Items []T
+}
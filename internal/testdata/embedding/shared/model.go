@@ -0,0 +1,7 @@
+package shared
+
+type Model struct {
+	CreatedAt int64
+}
+
+func (m *Model) Touch() {}
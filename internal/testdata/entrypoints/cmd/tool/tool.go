@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/entrypoints/shared"
+)
+
+func main() {
+	fmt.Println(shared.Name)
+}
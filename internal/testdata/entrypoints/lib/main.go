@@ -0,0 +1,18 @@
+package lib
+
+import (
+	"strings"
+
+	"example.com/entrypoints/shared"
+	"github.com/acme/widgets/render"
+)
+
+var ready bool
+
+func init() {
+	ready = true
+}
+
+func Helper() {
+	render.Text(strings.ToUpper(shared.Name))
+}
@@ -0,0 +1,7 @@
+package main
+
+import "example.com/entrypoints/lib"
+
+func main() {
+	lib.Helper()
+}
@@ -0,0 +1,9 @@
+package shared
+
+import "github.com/google/uuid"
+
+var Name = uuid.NewString()
+
+type Namer interface {
+	Name() string
+}
@@ -0,0 +1,24 @@
+package generics
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+type Box[T any] struct {
+	Item T
+}
+
+type Registry struct {
+	Entries  map[string]Pair[string, int]
+	Current  *Box[Pair[string, int]]
+	Handlers []func(*Box[string]) error
+}
+
+func (b *Box[T]) Get() T {
+	return b.Item
+}
+
+func (p Pair[K, V]) String() string {
+	return ""
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/imports/internal/api"
+	"example.com/imports/internal/store/memory"
+)
+
+func main() {
+	fmt.Println(api.New(memory.New()))
+}
@@ -0,0 +1,5 @@
+module example.com/imports
+
+go 1.19
+
+require github.com/acme/widgets v1.2.0
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"example.com/imports/internal/store/sql"
+	"github.com/acme/widgets/render"
+)
+
+type API struct {
+	store interface{}
+}
+
+func New(store interface{}) *API {
+	render.Init()
+	return &API{store: store}
+}
+
+func Default() *API {
+	return New(sql.New())
+}
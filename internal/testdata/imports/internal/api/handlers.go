@@ -0,0 +1,10 @@
+package api
+
+import (
+	"example.com/imports/internal/store/sql"
+	"github.com/acme/widgets/render/html"
+)
+
+func (a *API) Handle() {
+	html.Write(sql.New())
+}
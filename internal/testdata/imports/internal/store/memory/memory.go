@@ -0,0 +1,11 @@
+package memory
+
+import "example.com/imports/internal/store/sql"
+
+type Store struct {
+	fallback *sql.Store
+}
+
+func New() *Store {
+	return &Store{fallback: sql.New()}
+}
@@ -0,0 +1,10 @@
+package sql
+
+import "strings"
+
+type Store struct{}
+
+func New() *Store {
+	_ = strings.TrimSpace("")
+	return &Store{}
+}
@@ -0,0 +1,17 @@
+package broken
+
+type Valid struct {
+	Count int64
+	Flag  bool
+}
+
+type Broken struct {
+	Name    string
+	Missing Unknown
+}
+
+type Dangling struct {
+	Next Undefined
+}
+
+var fallback = Missing
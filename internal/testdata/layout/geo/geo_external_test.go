@@ -0,0 +1,8 @@
+package geo_test
+
+import "example.com/layout/geo"
+
+type fixture struct {
+	Name  string
+	Point geo.Point
+}
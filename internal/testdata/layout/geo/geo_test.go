@@ -0,0 +1,6 @@
+package geo
+
+type fixture struct {
+	Valid bool
+	Point Point
+}
@@ -0,0 +1,34 @@
+package layout
+
+import (
+	"sync"
+
+	"example.com/layout/geo"
+)
+
+type Padded struct {
+	Enabled bool
+	Count   int64
+	Visible bool
+}
+
+type Packed struct {
+	Count   int64
+	Enabled bool
+	Visible bool
+}
+
+type Shape struct {
+	Name   string
+	Closed bool
+	Points []geo.Point
+	Center geo.Point
+	mu     sync.Mutex
+}
+
+type Pair[T any] struct {
+	First  T
+	Second T
+}
+
+type Empty struct{}
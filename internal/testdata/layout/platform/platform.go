@@ -0,0 +1,7 @@
+package platform
+
+import "syscall"
+
+type Process struct {
+	Attr syscall.SysProcAttr
+}
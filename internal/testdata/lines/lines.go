@@ -0,0 +1,15 @@
+// Package lines is a fixture for line counting.
+package lines
+
+/*
+A block comment
+spanning lines.
+*/
+
+import "fmt"
+
+func Print() { // trailing comment counts as code
+	/* inline */ fmt.Println("x")
+
+	// standalone
+}
@@ -0,0 +1,8 @@
+package lines
+
+import "testing"
+
+// TestPrint checks nothing.
+func TestPrint(t *testing.T) {
+	Print()
+}
@@ -0,0 +1,7 @@
+package util
+
+func A() {}
+
+func B() {}
+
+func C() {}
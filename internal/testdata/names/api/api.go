@@ -0,0 +1,11 @@
+package api
+
+import (
+	"example.com/names/go-yaml"
+	"example.com/names/util"
+)
+
+func Clean(s string) string {
+	yaml.Parse()
+	return helpers.Trim(s)
+}
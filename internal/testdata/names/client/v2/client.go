@@ -0,0 +1,3 @@
+package client
+
+func Call() {}
@@ -0,0 +1,5 @@
+package mixed_test
+
+import "testing"
+
+func TestA(t *testing.T) {}
@@ -0,0 +1,13 @@
+package web
+
+import (
+	helpers "example.com/names/util"
+	yaml "example.com/names/go-yaml"
+	"example.com/names/client/v2"
+)
+
+func Render(s string) string {
+	client.Call()
+	yaml.Parse()
+	return helpers.Trim(s)
+}
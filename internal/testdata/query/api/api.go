@@ -0,0 +1,13 @@
+package api
+
+import "net/http"
+
+type Handler interface {
+	http.Handler
+	Name() string
+}
+
+type Route struct {
+	Path    string
+	Handler Handler
+}
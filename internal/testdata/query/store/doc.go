@@ -0,0 +1,4 @@
+// Package store keeps database connections. It is safe for concurrent use.
+//
+// Connections are closed with DB.Close.
+package store
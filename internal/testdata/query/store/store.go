@@ -0,0 +1,23 @@
+package store
+
+import (
+	"database/sql"
+	"sync"
+)
+
+type DB struct {
+	sync.Mutex
+	conn *sql.DB
+	Name string
+}
+
+func (d *DB) Close() error {
+	d.Lock()
+	defer d.Unlock()
+	return d.conn.Close()
+}
+
+type cache struct {
+	mu    sync.RWMutex
+	items map[string]string
+}
@@ -0,0 +1,26 @@
+package web
+
+import (
+	"database/sql"
+	"net/http"
+
+	"example.com/query/store"
+)
+
+type Server struct {
+	*store.DB
+	Addr    string
+	handler http.Handler
+	tx      *sql.Tx
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r == nil || w == nil {
+		return
+	}
+	s.handler.ServeHTTP(w, r)
+}
+
+func New(addr string) *Server {
+	return &Server{Addr: addr}
+}
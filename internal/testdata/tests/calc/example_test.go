@@ -0,0 +1,28 @@
+package calc_test
+
+import (
+	"fmt"
+	"testing"
+
+	"example.com/tests/calc"
+)
+
+func TestSub(t *testing.T) {
+	if calc.Sub(3, 2) != 1 {
+		t.Fail()
+	}
+}
+
+func FuzzAdd(f *testing.F) {
+	f.Fuzz(func(t *testing.T, a, b int) {
+		calc.Add(a, b)
+	})
+}
+
+func Example() {
+	fmt.Println(calc.Add(1, 2))
+}
+
+func ExampleSub() {
+	fmt.Println(calc.Sub(3, 2))
+}
@@ -0,0 +1,204 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type TestKind string
+
+const (
+	TestKindTest      TestKind = "test"
+	TestKindBenchmark TestKind = "benchmark"
+	TestKindFuzz      TestKind = "fuzz"
+	TestKindExample   TestKind = "example"
+)
+
+var testKindOrder = map[TestKind]int{
+	TestKindTest:      0,
+	TestKindBenchmark: 1,
+	TestKindFuzz:      2,
+	TestKindExample:   3,
+}
+
+type TestFunction struct {
+	Kind     TestKind
+	Name     string
+	External bool
+	Position Position
+}
+
+type PackageTests struct {
+	Path        string
+	Tests       []*TestFunction
+	SourceFiles int
+	TestFiles   int
+	SourceLines int
+	TestLines   int
+}
+
+func (p *PackageTests) Ratio() float64 {
+	if p.SourceLines == 0 {
+		return 0
+	}
+	return float64(p.TestLines) / float64(p.SourceLines)
+}
+
+func (p *PackageTests) Count(kind TestKind) int {
+	count := 0
+	for _, t := range p.Tests {
+		if t.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+func classifyTest(fn *Function) (TestKind, bool) {
+	prefixes := []struct {
+		prefix string
+		kind   TestKind
+		param  string
+	}{
+		{"Test", TestKindTest, ".T"},
+		{"Benchmark", TestKindBenchmark, ".B"},
+		{"Fuzz", TestKindFuzz, ".F"},
+		{"Example", TestKindExample, ""},
+	}
+
+	for _, p := range prefixes {
+		if !isTestName(fn.Name, p.prefix) {
+			continue
+		}
+
+		params := strings.TrimSpace(fn.Signature[len(fn.Name):])
+		params = params[1:strings.Index(params, ")")]
+
+		if p.param == "" {
+			return p.kind, params == ""
+		}
+		return p.kind, strings.HasPrefix(params, "*") && strings.HasSuffix(params, p.param) && !strings.Contains(params, ",")
+	}
+
+	return "", false
+}
+
+func isTestName(name, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	if len(name) == len(prefix) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+	return !unicode.IsLower(r)
+}
+
+func FindTests(directories map[string]*Directory) []*PackageTests {
+	byPath := map[string]*PackageTests{}
+	var res []*PackageTests
+
+	for _, directory := range DirectoryMap(directories).SortedDirectories() {
+		for _, pkg := range PackagesMap(directory.Packages).SortedPackages() {
+			p, ok := byPath[pkg.ModulePath]
+			if !ok {
+				p = &PackageTests{Path: pkg.ModulePath}
+				byPath[pkg.ModulePath] = p
+				res = append(res, p)
+			}
+
+			external := strings.HasSuffix(pkg.Name, "_test")
+
+			for _, f := range pkg.Files {
+				if !isTestFile(f.Path) {
+					p.SourceFiles++
+					p.SourceLines += f.Lines.Code
+					continue
+				}
+
+				p.TestFiles++
+				p.TestLines += f.Lines.Code
+
+				for _, fn := range f.Functions {
+					kind, ok := classifyTest(fn)
+					if !ok {
+						continue
+					}
+					p.Tests = append(p.Tests, &TestFunction{
+						Kind:     kind,
+						Name:     fn.Name,
+						External: external,
+						Position: fn.Position,
+					})
+				}
+			}
+		}
+	}
+
+	for _, p := range res {
+		sort.SliceStable(p.Tests, func(i, j int) bool {
+			if p.Tests[i].Kind != p.Tests[j].Kind {
+				return testKindOrder[p.Tests[i].Kind] < testKindOrder[p.Tests[j].Kind]
+			}
+			return p.Tests[i].Position.Before(p.Tests[j].Position)
+		})
+	}
+
+	return res
+}
+
+type TestsOptions struct {
+	Summary   bool
+	Positions bool
+}
+
+func FormatTests(directories map[string]*Directory, module string, config *Config, opts *TestsOptions) string {
+	var sb strings.Builder
+	var untested []string
+
+	for _, p := range FindTests(directories) {
+		if len(p.Tests) == 0 {
+			if p.SourceFiles > 0 {
+				untested = append(untested, p.Path)
+			}
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf(
+			"%s%s%s (%d tests, %d benchmarks, %d fuzz, %d examples, test/source lines %d/%d = %.2f)\n",
+			Yellow, p.Path, NoColor,
+			p.Count(TestKindTest), p.Count(TestKindBenchmark), p.Count(TestKindFuzz), p.Count(TestKindExample),
+			p.TestLines, p.SourceLines, p.Ratio(),
+		))
+
+		if opts.Summary {
+			continue
+		}
+
+		for _, t := range p.Tests {
+			location := "in-package"
+			if t.External {
+				location = "external"
+			}
+			sb.WriteString(fmt.Sprintf(
+				"%s    %-9s %s %s(%s)%s\n",
+				formatPosition(opts.Positions, t.Position), t.Kind, t.Name, Cyan, location, NoColor,
+			))
+		}
+	}
+
+	if len(untested) > 0 {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("packages without tests:\n")
+		for _, p := range untested {
+			sb.WriteString(fmt.Sprintf("    %s%s%s\n", Red, p, NoColor))
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,50 @@
+package internal_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/internal"
+)
+
+func TestFindTests(t *testing.T) {
+	directories := loadFixture(t, "testdata/tests", "example.com/tests", &internal.Config{IncludeTests: true})
+
+	var actual []string
+	for _, p := range internal.FindTests(directories) {
+		actual = append(actual, fmt.Sprintf(
+			"%s files=%d/%d lines=%d/%d", p.Path, p.SourceFiles, p.TestFiles, p.SourceLines, p.TestLines,
+		))
+		for _, test := range p.Tests {
+			actual = append(actual, fmt.Sprintf("    %s %s external=%v", test.Kind, test.Name, test.External))
+		}
+	}
+
+	assertEqual(t, []string{
+		"example.com/tests/calc files=1/2 lines=7/41",
+		"    test TestAdd external=false",
+		"    test TestSub external=true",
+		"    benchmark BenchmarkAdd external=false",
+		"    fuzz FuzzAdd external=true",
+		"    example Example external=true",
+		"    example ExampleSub external=true",
+		"example.com/tests/untested files=1/0 lines=2/0",
+	}, actual)
+}
+
+func TestFormatTestsSummary(t *testing.T) {
+	directories := loadFixture(t, "testdata/tests", "example.com/tests", &internal.Config{IncludeTests: true})
+
+	expected := "" +
+		"__YELLOW__example.com/tests/calc__NOCOLOR__ (2 tests, 1 benchmarks, 1 fuzz, 2 examples, test/source lines 41/7 = 5.86)\n" +
+		"\n" +
+		"packages without tests:\n" +
+		"    __RED__example.com/tests/untested__NOCOLOR__\n"
+
+	expected = strings.ReplaceAll(expected, "__YELLOW__", internal.Yellow)
+	expected = strings.ReplaceAll(expected, "__RED__", internal.Red)
+	expected = strings.ReplaceAll(expected, "__NOCOLOR__", internal.NoColor)
+
+	assertEqual(t, expected, internal.FormatTests(directories, "example.com/tests", &internal.Config{}, &internal.TestsOptions{Summary: true}))
+}
@@ -1,7 +1,13 @@
 package main
 
-import "github.com/slavsan/godiss/cmd"
+import (
+	"os"
+
+	"github.com/slavsan/godiss/cmd"
+)
 
 func main() {
-	cmd.Execute()
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
 }
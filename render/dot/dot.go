@@ -0,0 +1,71 @@
+package dot
+
+import (
+	"io"
+
+	"github.com/slavsan/godiss/analysis"
+	"github.com/slavsan/godiss/internal"
+	"github.com/slavsan/godiss/render/internal/model"
+)
+
+type Style struct {
+	RankDir   string
+	FontName  string
+	NodeShape string
+	NodeColor string
+	EdgeColor string
+	Splines   string
+}
+
+type Options struct {
+	Depth         int
+	Cluster       bool
+	Weights       bool
+	ExcludeStdLib bool
+	Binary        string
+	Style         *Style
+}
+
+func (o Options) config(m *analysis.Module) *internal.Config {
+	return &internal.Config{
+		Requires:      m.Requires,
+		ExcludeStdLib: o.ExcludeStdLib,
+	}
+}
+
+func (o Options) style() *internal.DiagramStyle {
+	if o.Style == nil {
+		return nil
+	}
+	return &internal.DiagramStyle{
+		RankDir:   o.Style.RankDir,
+		FontName:  o.Style.FontName,
+		NodeShape: o.Style.NodeShape,
+		NodeColor: o.Style.NodeColor,
+		EdgeColor: o.Style.EdgeColor,
+		Splines:   o.Style.Splines,
+	}
+}
+
+func Packages(w io.Writer, m *analysis.Module, opts Options) error {
+	_, err := io.WriteString(w, internal.FormatPackages(model.Directories(m), opts.style()))
+	return err
+}
+
+func Imports(w io.Writer, m *analysis.Module, opts Options) error {
+	_, err := io.WriteString(w, internal.FormatImports(model.Directories(m), m.Path, opts.config(m), &internal.ImportsOptions{
+		Depth:   opts.Depth,
+		Cluster: opts.Cluster,
+		Weights: opts.Weights,
+		Style:   opts.style(),
+	}))
+	return err
+}
+
+func Dependencies(w io.Writer, m *analysis.Module, opts Options) error {
+	_, err := io.WriteString(w, internal.FormatDependenciesDot(model.Directories(m), m.Path, opts.config(m), &internal.DependenciesOptions{
+		Binary: opts.Binary,
+		Style:  opts.style(),
+	}))
+	return err
+}
@@ -0,0 +1,34 @@
+package dot_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/analysis"
+	"github.com/slavsan/godiss/render/dot"
+)
+
+func TestImports(t *testing.T) {
+	m, err := analysis.Load(context.Background(), "../../analysis/testdata/lib")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := dot.Imports(&sb, m, dot.Options{Style: &dot.Style{RankDir: "TB"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Join([]string{
+		`digraph {`,
+		`    rankdir="TB"`,
+		``,
+		`    "example.com/lib" -> "example.com/lib/store"`,
+		`}`,
+		``,
+	}, "\n")
+	if sb.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, sb.String())
+	}
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"github.com/slavsan/godiss/analysis"
+	"github.com/slavsan/godiss/internal"
+)
+
+type converter struct {
+	methods map[*analysis.Method]*internal.Method
+}
+
+func Directories(m *analysis.Module) map[string]*internal.Directory {
+	c := &converter{methods: map[*analysis.Method]*internal.Method{}}
+
+	res := map[string]*internal.Directory{}
+	for _, pkg := range m.Packages() {
+		directory, ok := res[pkg.Dir]
+		if !ok {
+			directory = &internal.Directory{Path: pkg.Dir, Packages: map[string]*internal.Package{}}
+			res[pkg.Dir] = directory
+		}
+
+		p := &internal.Package{Name: pkg.Name, Path: pkg.Dir, ModulePath: pkg.Path, Doc: pkg.Doc}
+		for _, f := range pkg.Files {
+			p.Files = append(p.Files, c.file(f))
+		}
+		directory.Packages[pkg.Name] = p
+	}
+	return res
+}
+
+func position(p analysis.Position) internal.Position {
+	return internal.Position{Filename: p.Filename, Line: p.Line, Column: p.Column}
+}
+
+func complexity(c *analysis.Complexity) *internal.Complexity {
+	if c == nil {
+		return nil
+	}
+	return &internal.Complexity{Cyclomatic: c.Cyclomatic, Cognitive: c.Cognitive, Lines: c.Lines, Params: c.Params, Nesting: c.Nesting}
+}
+
+func (c *converter) method(m *analysis.Method) *internal.Method {
+	if res, ok := c.methods[m]; ok {
+		return res
+	}
+	res := &internal.Method{Signature: m.Signature, Receiver: m.Receiver, Position: position(m.Position), Complexity: complexity(m.Complexity)}
+	c.methods[m] = res
+	return res
+}
+
+func (c *converter) methodList(methods []*analysis.Method) []*internal.Method {
+	var res []*internal.Method
+	for _, m := range methods {
+		res = append(res, c.method(m))
+	}
+	return res
+}
+
+func (c *converter) file(f *analysis.File) *internal.File {
+	res := &internal.File{
+		Path:             f.Path,
+		BuildConstraints: f.BuildConstraints,
+		Methods:          c.methodList(f.Methods),
+		Lines:            internal.LineCounts{Code: f.Lines.Code, Comment: f.Lines.Comment, Blank: f.Lines.Blank},
+		Position:         position(f.Position),
+	}
+
+	for _, s := range f.Structs {
+		st := &internal.Struct{Name: s.Name, Methods: c.methodList(s.Methods), Position: position(s.Position)}
+		for _, field := range s.Fields {
+			st.Fields = append(st.Fields, &internal.Field{Name: field.Name, Type: field.Type, Position: position(field.Position)})
+		}
+		res.Structs = append(res.Structs, st)
+	}
+	for _, i := range f.Imports {
+		res.Imports = append(res.Imports, &internal.Import{Name: i.Name, Path: i.Path, StdLib: i.StdLib, Position: position(i.Position)})
+	}
+	for _, fn := range f.Functions {
+		res.Functions = append(res.Functions, &internal.Function{
+			Name:       fn.Name,
+			Signature:  fn.Signature,
+			Position:   position(fn.Position),
+			Complexity: complexity(fn.Complexity),
+		})
+	}
+	for _, i := range f.Interfaces {
+		res.Interfaces = append(res.Interfaces, &internal.Interface{
+			Name:     i.Name,
+			Methods:  c.methodList(i.Methods),
+			Embedded: i.Embedded,
+			Position: position(i.Position),
+		})
+	}
+	for _, t := range f.Types {
+		res.Types = append(res.Types, &internal.NamedType{Name: t.Name, Type: t.Type, Alias: t.Alias, Position: position(t.Position)})
+	}
+
+	return res
+}
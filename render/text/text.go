@@ -0,0 +1,79 @@
+package text
+
+import (
+	"io"
+
+	"github.com/slavsan/godiss/analysis"
+	"github.com/slavsan/godiss/internal"
+	"github.com/slavsan/godiss/render/internal/model"
+)
+
+type Options struct {
+	Positions     bool
+	Embedded      bool
+	ExcludeStdLib bool
+	External      bool
+	Summary       bool
+	Sort          string
+	Top           int
+}
+
+func (o Options) config(m *analysis.Module) *internal.Config {
+	return &internal.Config{
+		Requires:      m.Requires,
+		ExcludeStdLib: o.ExcludeStdLib,
+		IncludeTests:  true,
+	}
+}
+
+func Types(w io.Writer, m *analysis.Module, opts Options) error {
+	_, err := io.WriteString(w, internal.FormatTypes(model.Directories(m), m.Path, opts.config(m), &internal.TypesOptions{
+		Embedded:  opts.Embedded,
+		Positions: opts.Positions,
+	}))
+	return err
+}
+
+func Stats(w io.Writer, m *analysis.Module) error {
+	_, err := io.WriteString(w, internal.FormatStats(model.Directories(m), m.Path))
+	return err
+}
+
+func Imports(w io.Writer, m *analysis.Module, opts Options) error {
+	_, err := io.WriteString(w, internal.FormatImportsTable(model.Directories(m), m.Path, opts.config(m), &internal.ImportsTableOptions{Positions: opts.Positions}))
+	return err
+}
+
+func Entrypoints(w io.Writer, m *analysis.Module, opts Options) error {
+	_, err := io.WriteString(w, internal.FormatEntrypoints(model.Directories(m), m.Path, opts.config(m), &internal.EntrypointsOptions{Positions: opts.Positions}))
+	return err
+}
+
+func Dependencies(w io.Writer, m *analysis.Module, opts Options) error {
+	_, err := io.WriteString(w, internal.FormatDependencies(model.Directories(m), m.Path, opts.config(m), &internal.DependenciesOptions{Positions: opts.Positions}))
+	return err
+}
+
+func Coupling(w io.Writer, m *analysis.Module, opts Options) error {
+	_, err := io.WriteString(w, internal.FormatCoupling(model.Directories(m), m.Path, opts.config(m), &internal.CouplingOptions{Sort: opts.Sort, External: opts.External}))
+	return err
+}
+
+func Complexity(w io.Writer, m *analysis.Module, opts Options) error {
+	_, err := io.WriteString(w, internal.FormatComplexity(model.Directories(m), m.Path, opts.config(m), &internal.ComplexityOptions{
+		Sort:      opts.Sort,
+		Top:       opts.Top,
+		Positions: opts.Positions,
+	}))
+	return err
+}
+
+func Tests(w io.Writer, m *analysis.Module, opts Options) error {
+	_, err := io.WriteString(w, internal.FormatTests(model.Directories(m), m.Path, opts.config(m), &internal.TestsOptions{Summary: opts.Summary, Positions: opts.Positions}))
+	return err
+}
+
+func Dead(w io.Writer, m *analysis.Module, opts Options) error {
+	_, err := io.WriteString(w, internal.FormatDeadPackages(model.Directories(m), m.Path, opts.config(m), &internal.DeadOptions{}))
+	return err
+}
@@ -0,0 +1,34 @@
+package text_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/slavsan/godiss/analysis"
+	"github.com/slavsan/godiss/render/text"
+)
+
+func TestStats(t *testing.T) {
+	m, err := analysis.Load(context.Background(), "../../analysis/testdata/lib", analysis.WithTests(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := text.Stats(&sb, m); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		"  3 | packages count\n",
+		"  8 | files count\n",
+		"  1 | test files\n",
+		"  3 | files with build constraints\n",
+		"  8 | structs count\n",
+	} {
+		if !strings.Contains(sb.String(), line) {
+			t.Errorf("expected output to contain %q, got:\n%s", line, sb.String())
+		}
+	}
+}